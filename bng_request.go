@@ -0,0 +1,73 @@
+package bngsocket
+
+import (
+	"context"
+	"maps"
+	"net"
+)
+
+// Context gibt den Context der Anfrage zurück.
+// Dieser enthält den Trace Context sowie die Deadline des Aufrufers und wird abgebrochen,
+// sobald die Deadline abgelaufen ist oder die Verbindung beendet wurde.
+func (r *BngRequest) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+// RequestID gibt die ID des RPC Aufrufs zurück.
+func (r *BngRequest) RequestID() string {
+	return r.id
+}
+
+// Method gibt den Namen zurück, unter dem die Funktion aufgerufen wurde.
+func (r *BngRequest) Method() string {
+	return r.method
+}
+
+// RemoteAddr gibt die Netzwerkadresse des Aufrufers zurück, falls bekannt.
+func (r *BngRequest) RemoteAddr() net.Addr {
+	return r.Conn.RemoteAddr()
+}
+
+// PeerCredentials gibt die Zugangsdaten (PID, UID, GID) des aufrufenden Prozesses zurück.
+// Diese stehen nur bei Unix-Socket Verbindungen unter Linux zur Verfügung, ansonsten wird
+// ErrPeerCredentialsUnsupported zurückgegeben.
+func (r *BngRequest) PeerCredentials() (*PeerCredentials, error) {
+	return r.Conn.PeerCredentials()
+}
+
+// Metadata gibt eine Kopie der Metadaten zurück, welche der Aufrufer mitgesendet hat.
+func (r *BngRequest) Metadata() map[string]string {
+	metadata := make(map[string]string, len(r.metadata))
+	maps.Copy(metadata, r.metadata)
+	return metadata
+}
+
+// SetTrailer setzt einen Eintrag im Trailer, welcher mit der Antwort an den Aufrufer zurückgesendet wird.
+func (r *BngRequest) SetTrailer(key string, value string) {
+	r.trailerMu.Lock()
+	defer r.trailerMu.Unlock()
+	if r.trailer == nil {
+		r.trailer = make(map[string]string)
+	}
+	r.trailer[key] = value
+}
+
+// SetTrailers übernimmt alle Einträge in den Trailer, welcher mit der Antwort an den Aufrufer zurückgesendet wird.
+func (r *BngRequest) SetTrailers(trailer map[string]string) {
+	for key, value := range trailer {
+		r.SetTrailer(key, value)
+	}
+}
+
+// trailerMetadata gibt eine Kopie des Trailers zurück, ist dieser leer wird nil zurückgegeben.
+func (r *BngRequest) trailerMetadata() map[string]string {
+	r.trailerMu.Lock()
+	defer r.trailerMu.Unlock()
+	if len(r.trailer) == 0 {
+		return nil
+	}
+	return maps.Clone(r.trailer)
+}
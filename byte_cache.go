@@ -49,16 +49,16 @@ func (bc *_ByteCache) Read() ([]byte, uint64, error) {
 	bc.mu.Lock()
 	defer bc.mu.Unlock()
 
+	// Warten, bis Daten im Cache vorhanden sind oder der Cache geschlossen wurde.
+	for len(bc.dataItems) == 0 && !bc.closed {
+		bc.cond.Wait() // Blockiert, bis Daten vorhanden sind.
+	}
+
 	// Es wird geprüft ob der ByteChannel geschlossen wurde
 	if bc.closed {
 		return nil, 0, io.EOF
 	}
 
-	// Warten, bis Daten im Cache vorhanden sind.
-	for len(bc.dataItems) == 0 {
-		bc.cond.Wait() // Blockiert, bis Daten vorhanden sind.
-	}
-
 	// Den gesamten aktuellen Datensatz lesen.
 	currentItem := bc.dataItems[0]
 	data := currentItem.data.Bytes() // Liest alle verbleibenden Bytes.
@@ -83,5 +83,6 @@ func (bc *_ByteCache) IsClosed() bool {
 func (bc *_ByteCache) Close() {
 	bc.mu.Lock()
 	bc.closed = true
+	bc.cond.Broadcast() // Wartende Leser werden freigegeben
 	bc.mu.Unlock()
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net"
 	"time"
 )
@@ -136,6 +137,14 @@ func (m *BngConnChannel) Write(b []byte) (n int, err error) {
 		return 0, fmt.Errorf("BngConnChannel->Write: %w", runningErr)
 	}
 
+	// Bei einem neu erzeugten Channel wird gewartet, bis die Gegenseite beigetreten ist
+	if m.joined != nil {
+		<-m.joined
+		if m.isClosed.Get() {
+			return 0, io.EOF
+		}
+	}
+
 	// Es wird eine Lese Funktion hinzugefügt
 	m.openWriters.Add(1)
 	defer m.openWriters.Sub(1)
@@ -145,9 +154,13 @@ func (m *BngConnChannel) Write(b []byte) (n int, err error) {
 		return 0, ErrConcurrentWritingNotAllowed // Es wird ein Fehler ausgelöst
 	}
 
+	// Der Status des Channels wird auf "WaitOfACK" gesetzt, bevor die Daten gesendet werden,
+	// da die Bestätigung der Gegenseite bereits vor dem Ende des Sendevorgangs eintreffen kann
+	m.waitOfPackageACK.Set(true)
+
 	// Es wird versucht, die Daten in den Channel zu schreiben
 	// LOG
-	_DebugPrint(fmt.Sprintf("BngConn(%s): Strat transfering data", m.socket._innerhid))
+	m.socket.logger.Debug("Start transfering data", slog.String(logKeyChannelSession, m.sesisonId))
 	packageId, writtenSize, err := channelDataTransport(m.socket, b, m.sesisonId) // sessionId korrigiert
 	if err != nil {
 		if errors.Is(err, io.EOF) {
@@ -164,9 +177,6 @@ func (m *BngConnChannel) Write(b []byte) (n int, err error) {
 		return 0, fmt.Errorf("BngConnChannel->Write: %w", runningErr)
 	}
 
-	// Der Status des Channels wird auf "WaitOfACK" gesetzt
-	m.waitOfPackageACK.Set(true)
-
 	// Es wird auf die Bestätigung durch die Gegenseite gewartet
 	ackPackageId, ok := m.ackChan.Read()
 	if !ok {
@@ -208,7 +218,7 @@ func (m *BngConnChannel) Write(b []byte) (n int, err error) {
 		return 0, ferr
 	}
 
-	_DebugPrint(fmt.Sprintf("BngConn(%s): Data transfered", m.socket._innerhid))
+	m.socket.logger.Debug("Data transfered", slog.String(logKeyChannelSession, m.sesisonId))
 
 	// Die Daten wurden erfolgreich übertragen
 	return writtenSize, nil
@@ -286,7 +296,7 @@ func (m *BngConnChannel) processClose(sendSignal bool) error {
 	}
 
 	// Debug
-	_DebugPrint("Channel is closed:", m.sesisonId)
+	m.socket.logger.Debug("Channel is closed", slog.String(logKeyChannelSession, m.sesisonId))
 
 	// Es ist Kein Fehler aufgetreten
 	return nil
@@ -29,7 +29,6 @@ func (o *BngConnChannelListener) Accept() (*BngConnChannel, error) {
 		if connectionIsClosed(o.socket) {
 			return nil, io.EOF
 		}
-		fmt.Println(connectionIsClosed(o.socket))
 		return nil, fmt.Errorf("BngConnChannelListener->Accept[1]: cant read from chan")
 	}
 
@@ -97,10 +96,13 @@ func (o *BngConnChannelListener) processIncommingSessionRequest(requestChannelId
 	return nil
 }
 
-// Close schließt den Channel Listener (derzeit ohne Implementierung).
+// Close schließt den Channel Listener, es werden keine neuen Channel Anfragen mehr angenommen.
 func (o *BngConnChannelListener) Close() error {
-	// Placeholder für die Schließlogik, derzeit keine Operation.
-	o.waitOfAccepting.Destroy()
-	_DebugPrint("BngConnChannelListener: Close")
+	// Der Listener wird nur beim ersten Aufruf geschlossen
+	if !o.waitOfAccepting.Destroy() {
+		return nil
+	}
+	o.socket.metrics.ListenersChanged(-1)
+	o.socket.logger.Debug("Channel listener closed")
 	return nil
 }
@@ -0,0 +1,242 @@
+package bngsocket
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec kodiert die Nachrichten einer Verbindung sowie die Werte der RPC Aufrufe.
+// Eigene Codecs können über RegisterCodec bereitgestellt werden.
+type Codec interface {
+	// Name des Codecs, unter dem er mit der Gegenseite ausgehandelt wird
+	Name() string
+	// Marshal kodiert einen Wert
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal dekodiert die Daten in den Wert, auf den v zeigt
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Mitgelieferte Codecs
+const (
+	CodecMsgpack  = "msgpack"  // MessagePack (Standard, kompatibel mit Gegenseiten ohne Codec Unterstützung)
+	CodecCBOR     = "cbor"     // CBOR (RFC 8949)
+	CodecJSON     = "json"     // JSON, z.B. zur Fehlersuche mit Netzwerkanalysewerkzeugen
+	CodecProtobuf = "protobuf" // Protocol Buffers, ausschließlich für proto.Message Werte
+)
+
+// Nachrichten, welche nicht mit msgpack kodiert wurden, beginnen mit diesem Byte gefolgt vom Namen des Codecs.
+// Das Byte wird von msgpack nie verwendet, msgpack Nachrichten bleiben dadurch unverändert.
+const codecEnvelopeMarker = 0xc1
+
+// Speichert alle verfügbaren Codecs
+var codecRegistry = struct {
+	mu     sync.RWMutex
+	codecs map[string]Codec
+}{
+	codecs: map[string]Codec{
+		CodecMsgpack:  _MsgpackCodec{},
+		CodecCBOR:     newCBORCodec(),
+		CodecJSON:     _JSONCodec{},
+		CodecProtobuf: _ProtobufCodec{},
+	},
+}
+
+// Der Typ des proto.Message Interfaces
+var protoMessageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+
+// RegisterCodec stellt einen eigenen Codec für alle Verbindungen bereit.
+// Der Codec kann anschließend über BngConnConfig.Codec ausgewählt werden.
+//
+// Parameter:
+//   - codec Codec: Der Codec, welcher unter seinem Namen registriert wird.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls der Name ungültig oder bereits vergeben ist, ansonsten nil.
+func RegisterCodec(codec Codec) error {
+	// Der Name gzip ist für den Umschlag komprimierter Nachrichten reserviert
+	if codec == nil || codec.Name() == "" || len(codec.Name()) > 255 || codec.Name() == CompressionGzip {
+		return fmt.Errorf("bngsocket->RegisterCodec[0]: invalid codec name")
+	}
+
+	codecRegistry.mu.Lock()
+	defer codecRegistry.mu.Unlock()
+
+	if _, found := codecRegistry.codecs[codec.Name()]; found {
+		return fmt.Errorf("bngsocket->RegisterCodec[1]: codec %s always registrated", codec.Name())
+	}
+	codecRegistry.codecs[codec.Name()] = codec
+	return nil
+}
+
+// lookupCodec gibt den Codec mit dem Namen zurück.
+func lookupCodec(name string) (Codec, bool) {
+	codecRegistry.mu.RLock()
+	defer codecRegistry.mu.RUnlock()
+	codec, found := codecRegistry.codecs[name]
+	return codec, found
+}
+
+// Codec gibt den Namen des Codecs zurück, mit dem die Verbindung derzeit ihre Nachrichten kodiert.
+func (s *BngConn) Codec() string {
+	return messageCodec(s).Name()
+}
+
+// messageCodec gibt den Codec zurück, mit dem ausgehende Nachrichten kodiert werden.
+// Bis der Codec mit der Gegenseite ausgehandelt wurde, wird msgpack verwendet.
+func messageCodec(o *BngConn) Codec {
+	if codec := o.negotiatedCodec.Get(); codec != nil {
+		return codec
+	}
+	return _MsgpackCodec{}
+}
+
+// marshalMessage kodiert eine ausgehende Nachricht mit dem ausgehandelten Codec.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - v interface{}: Die zu kodierende Nachricht.
+//
+// Rückgabe:
+//   - []byte: Die kodierte Nachricht, bei einem anderen Codec als msgpack mit vorangestelltem Codec Namen.
+//   - error: Ein Fehler, falls die Nachricht nicht kodiert werden konnte, ansonsten nil.
+func marshalMessage(o *BngConn, v interface{}) ([]byte, error) {
+	codec := messageCodec(o)
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshalMessage[0]: %w", err)
+	}
+
+	// msgpack Nachrichten werden ohne Umschlag übertragen
+	if codec.Name() == CodecMsgpack {
+		return data, nil
+	}
+
+	// Der Name des Codecs wird vorangestellt
+	envelope := make([]byte, 0, len(data)+len(codec.Name())+2)
+	envelope = append(envelope, codecEnvelopeMarker, byte(len(codec.Name())))
+	envelope = append(envelope, codec.Name()...)
+	return append(envelope, data...), nil
+}
+
+// unwrapMessage ermittelt den Codec einer eingegangenen Nachricht.
+//
+// Parameter:
+//   - data []byte: Die empfangenen Daten.
+//
+// Rückgabe:
+//   - Codec: Der Codec, mit dem die Nachricht kodiert wurde.
+//   - []byte: Die Nachricht ohne Umschlag.
+//   - error: ErrUnsupportedCodec, falls der Codec nicht bekannt ist, ansonsten nil.
+func unwrapMessage(data []byte) (Codec, []byte, error) {
+	// Nachrichten ohne Umschlag wurden mit msgpack kodiert
+	if len(data) == 0 || data[0] != codecEnvelopeMarker {
+		return _MsgpackCodec{}, data, nil
+	}
+
+	// Der Name des Codecs wird ausgelesen
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return nil, nil, fmt.Errorf("%w: invalid envelope", ErrUnsupportedCodec)
+	}
+	name := string(data[2 : 2+int(data[1])])
+	codec, found := lookupCodec(name)
+	if !found {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedCodec, name)
+	}
+	return codec, data[2+int(data[1]):], nil
+}
+
+// unmarshalMessage dekodiert einen mit marshalMessage kodierten Wert.
+func unmarshalMessage(data []byte, v interface{}) error {
+	codec, payload, err := unwrapMessage(data)
+	if err != nil {
+		return err
+	}
+	return codec.Unmarshal(payload, v)
+}
+
+// isProtoMessageType gibt an ob es sich um eine Protocol Buffers Nachricht handelt.
+func isProtoMessageType(t reflect.Type) bool {
+	return t != nil && t.Kind() == reflect.Ptr && t.Implements(protoMessageType)
+}
+
+// protoMessageName gibt den vollständigen Namen einer Protocol Buffers Nachricht zurück.
+func protoMessageName(t reflect.Type) string {
+	return string(reflect.Zero(t).Interface().(proto.Message).ProtoReflect().Descriptor().FullName())
+}
+
+// _MsgpackCodec kodiert mittels MessagePack.
+type _MsgpackCodec struct{}
+
+func (_MsgpackCodec) Name() string                          { return CodecMsgpack }
+func (_MsgpackCodec) Marshal(v interface{}) ([]byte, error) { return msgpack.Marshal(v) }
+func (_MsgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+// _CBORCodec kodiert mittels CBOR, Maps werden als map[string]interface{} dekodiert.
+type _CBORCodec struct {
+	decMode cbor.DecMode
+}
+
+func newCBORCodec() _CBORCodec {
+	decMode, err := cbor.DecOptions{DefaultMapType: reflect.TypeOf(map[string]interface{}(nil))}.DecMode()
+	if err != nil {
+		panic("bngsocket->newCBORCodec: " + err.Error())
+	}
+	return _CBORCodec{decMode: decMode}
+}
+
+func (_CBORCodec) Name() string                          { return CodecCBOR }
+func (_CBORCodec) Marshal(v interface{}) ([]byte, error) { return cbor.Marshal(v) }
+func (c _CBORCodec) Unmarshal(data []byte, v interface{}) error {
+	return c.decMode.Unmarshal(data, v)
+}
+
+// _JSONCodec kodiert mittels JSON.
+type _JSONCodec struct{}
+
+func (_JSONCodec) Name() string                          { return CodecJSON }
+func (_JSONCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
+func (_JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	return decoder.Decode(v)
+}
+
+// _ProtobufCodec kodiert Protocol Buffers Nachrichten, andere Werte werden nicht unterstützt.
+type _ProtobufCodec struct{}
+
+func (_ProtobufCodec) Name() string { return CodecProtobuf }
+
+func (_ProtobufCodec) Marshal(v interface{}) ([]byte, error) {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf codec: %T is not a proto.Message", v)
+	}
+	return proto.Marshal(message)
+}
+
+func (_ProtobufCodec) Unmarshal(data []byte, v interface{}) error {
+	// Es kann direkt in die Nachricht dekodiert werden
+	if message, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, message)
+	}
+
+	// Bei einem Zeiger auf einen Nachrichtenzeiger wird die Nachricht erzeugt
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Ptr || !isProtoMessageType(target.Elem().Type()) {
+		return fmt.Errorf("protobuf codec: %T is not a proto.Message", v)
+	}
+	message := reflect.New(target.Elem().Type().Elem())
+	if err := proto.Unmarshal(data, message.Interface().(proto.Message)); err != nil {
+		return err
+	}
+	target.Elem().Set(message)
+	return nil
+}
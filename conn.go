@@ -1,8 +1,10 @@
 package bngsocket
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"net"
 	"reflect"
 	"strings"
@@ -11,7 +13,6 @@ import (
 
 	"github.com/custodia-cenv/bngsocket-go/transport"
 	"github.com/google/uuid"
-	"github.com/vmihailenco/msgpack/v5"
 )
 
 // OpenChannelListener wird verwendet, um einen neuen Channel bereitzustellen.
@@ -44,9 +45,10 @@ func (s *BngConn) OpenChannelListener(cahnnelId string) (*BngConnChannelListener
 
 	// Der Eintrag wird hinzugefügt
 	s.openChannelListener.Store(cahnnelId, listener)
+	s.metrics.ListenersChanged(1)
 
 	// LOG
-	_DebugPrint(fmt.Sprintf("BngConn(%s): New Channel Listener: %s", s._innerhid, cahnnelId))
+	s.logger.Debug("New Channel Listener", slog.String("channel", cahnnelId))
 
 	// Der Listener wird zurückgegeben
 	return listener, nil
@@ -62,6 +64,11 @@ func (s *BngConn) OpenChannelListener(cahnnelId string) (*BngConnChannelListener
 //   - *BngConnChannel: Ein Zeiger auf das verbundene Channel-Objekt.
 //   - error: Ein Fehler, falls beim Beitritt zum Channel ein Problem auftritt, ansonsten nil.
 func (s *BngConn) JoinChannel(channelId string) (*BngConnChannel, error) {
+	// Es wird geprüft ob neue Channel Beitritte gestartet werden dürfen
+	if err := acceptsNewOperations(s); err != nil {
+		return nil, err
+	}
+
 	// Es wird ein RpcRequest Paket erstellt
 	chreq := &transport.ChannelRequest{
 		Type:               "chreq",
@@ -70,7 +77,7 @@ func (s *BngConn) JoinChannel(channelId string) (*BngConnChannel, error) {
 	}
 
 	// Das Paket wird in Bytes umgewandelt
-	bytedData, err := msgpack.Marshal(chreq)
+	bytedData, err := marshalMessage(s, chreq)
 	if err != nil {
 		return nil, fmt.Errorf("bngsocket->_CallFunction[1]: " + err.Error())
 	}
@@ -86,8 +93,11 @@ func (s *BngConn) JoinChannel(channelId string) (*BngConnChannel, error) {
 		return nil, fmt.Errorf("bngsocket->JoinChannel: " + err.Error())
 	}
 
-	// Es wird auf die Antwort gewartet
-	response := <-responseChan
+	// Es wird auf die Antwort gewartet, wurde der Chan geschlossen, ist die Verbindung beendet worden
+	response, ok := <-responseChan
+	if !ok {
+		return nil, connectionTerminationError(s)
+	}
 
 	// Die Requestssitzung wird entfernt
 	s.openChannelJoinProcesses.Delete(chreq.RequestId)
@@ -95,6 +105,11 @@ func (s *BngConn) JoinChannel(channelId string) (*BngConnChannel, error) {
 	// Der Chan wird geschlossen
 	close(responseChan)
 
+	// Es wird geprüft ob die Gegenseite heruntergefahren wird
+	if response.NotAcceptedByReason == channelRejectGoingAway {
+		return nil, ErrPeerGoingAway
+	}
+
 	// Es wird geprüft ob die Anfrage von der Gegenseite angenommen wurde
 	if response.NotAcceptedByReason != "" {
 		return nil, fmt.Errorf("bngsocket->JoinChannel[1]: " + response.NotAcceptedByReason)
@@ -107,7 +122,7 @@ func (s *BngConn) JoinChannel(channelId string) (*BngConnChannel, error) {
 	}
 
 	// LOG
-	_DebugPrint(fmt.Sprintf("BngConn(%s): Channel Joined: %s", s._innerhid, channel.sesisonId))
+	s.logger.Debug("Channel Joined", slog.String(logKeyChannelSession, channel.sesisonId))
 
 	// Dem Server wird mitgeteilt dass die Verbindung erfolgreich zustande gekommen ist
 	if err := channelWriteACKForJoin(s, response.ChannelId); err != nil {
@@ -122,6 +137,8 @@ func (s *BngConn) JoinChannel(channelId string) (*BngConnChannel, error) {
 
 // RegisterFunction ermöglicht es, neue Funktionen dynamisch hinzuzufügen.
 // Diese Methode registriert eine Funktion unter einem bestimmten Namen, sodass sie später über RPC aufgerufen werden kann.
+// Die Rückgabetypen des Aufrufers werden vor der Ausführung mit der Signatur verglichen. Ausgenommen sind Rückgabewerte
+// vom Typ interface{}, diese werden erst nach der Ausführung geprüft, da ihr Typ erst dann feststeht.
 //
 // Parameter:
 //   - name string: Der eindeutige Name, unter dem die Funktion registriert werden soll.
@@ -132,13 +149,79 @@ func (s *BngConn) JoinChannel(channelId string) (*BngConnChannel, error) {
 func (s *BngConn) RegisterFunction(name string, fn interface{}) error {
 	// Fügt eine neue Funktion hinzu
 	if err := _RegisterFunction(s, name, fn); err != nil {
-		return fmt.Errorf("bngsocket->RegisterFunction[0]: " + err.Error())
+		return fmt.Errorf("bngsocket->RegisterFunction[0]: %w", err)
 	}
 
 	// Kein Fehler aufgetreten
 	return nil
 }
 
+// UnregisterFunction entfernt eine registrierte Funktion.
+// Bereits laufende Aufrufe der Funktion werden normal beendet, neue Aufrufe erhalten ErrUnkownRpcFunction.
+//
+// Parameter:
+//   - name string: Der Name, unter dem die Funktion registriert wurde.
+//
+// Rückgabe:
+//   - error: ErrFunctionNotRegistered, falls keine Funktion unter dem Namen registriert ist, ansonsten nil.
+func (s *BngConn) UnregisterFunction(name string) error {
+	if err := _UnregisterFunction(s, name); err != nil {
+		return fmt.Errorf("bngsocket->UnregisterFunction[0]: %w", err)
+	}
+	return nil
+}
+
+// ReplaceFunction ersetzt die unter dem Namen registrierte Funktion oder registriert sie, falls sie noch nicht vorhanden ist.
+// Bereits laufende Aufrufe der bisherigen Funktion werden normal beendet, neue Aufrufe verwenden die neue Funktion.
+//
+// Parameter:
+//   - name string: Der Name, unter dem die Funktion registriert werden soll.
+//   - fn interface{}: Die neue Funktion.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls die Funktion ungültig ist, ansonsten nil.
+func (s *BngConn) ReplaceFunction(name string, fn interface{}) error {
+	if err := _ReplaceFunction(s, name, fn); err != nil {
+		return fmt.Errorf("bngsocket->ReplaceFunction[0]: %w", err)
+	}
+	return nil
+}
+
+// RegisterService registriert alle exportierten Methoden eines Objektes unter dem Namen "service.Methode".
+// Jede exportierte Methode muss eine gültige RPC Funktion sein, ansonsten wird keine der Methoden registriert.
+// Sollen Hilfsmethoden ohne passende Signatur übersprungen werden, ist RegisterReceiver zu verwenden.
+//
+// Parameter:
+//   - service string: Der Namensraum, unter dem die Methoden registriert werden.
+//   - obj interface{}: Das Objekt, dessen Methoden registriert werden.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls eine Methode ungültig oder bereits registriert ist, ansonsten nil.
+func (s *BngConn) RegisterService(service string, obj interface{}) error {
+	if err := _RegisterService(s, service, obj); err != nil {
+		return fmt.Errorf("bngsocket->RegisterService[0]: %w", err)
+	}
+	return nil
+}
+
+// RegisterReceiver registriert alle exportierten Methoden eines Objektes, deren erster Parameter ein *BngRequest
+// und deren letzter Rückgabewert ein error ist, unter dem Namen "prefix.Methode" (ohne Präfix nur "Methode").
+// Im Gegensatz zu RegisterService werden andere Methoden übersprungen. Alle ungültigen Methoden mit passender
+// Signatur werden in einem gemeinsamen Fehler gemeldet, in diesem Fall wird keine der Methoden registriert.
+//
+// Parameter:
+//   - prefix string: Der Namensraum, unter dem die Methoden registriert werden.
+//   - rcvr any: Das Objekt, dessen Methoden registriert werden.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls Methoden ungültig oder bereits registriert sind, ansonsten nil.
+func (s *BngConn) RegisterReceiver(prefix string, rcvr any) error {
+	if err := _RegisterReceiver(s, prefix, rcvr); err != nil {
+		return fmt.Errorf("bngsocket->RegisterReceiver[0]: %w", err)
+	}
+	return nil
+}
+
 // CallFunction ruft eine Funktion auf der Gegenseite (Remote) auf.
 // Diese Methode sendet eine Funktionsanfrage über die Socket-Verbindung und wartet auf die Antwort.
 //
@@ -151,8 +234,29 @@ func (s *BngConn) RegisterFunction(name string, fn interface{}) error {
 //   - []interface{}: Ein Slice von Rückgabewerten der aufgerufenen Funktion.
 //   - error: Ein Fehler, falls beim Aufrufen der Funktion ein Problem aufgetreten ist, ansonsten nil.
 func (s *BngConn) CallFunction(name string, params []interface{}, returnDataType []reflect.Type) ([]interface{}, error) {
-	// Die Funktion auf der Gegenseite wird aufgerufen
-	data, err := _CallFunction(s, name, params, returnDataType)
+	return s.CallFunctionContext(context.Background(), name, params, returnDataType)
+}
+
+// CallFunctionContext ruft eine Funktion auf der Gegenseite (Remote) auf.
+// Der Trace Context aus ctx wird über die Metadaten des Aufrufs an die Gegenseite übertragen.
+// Wird ctx abgebrochen, bevor die Antwort eingetroffen ist, wird der Fehler des Contexts zurückgegeben.
+// Fehlgeschlagene Aufrufe werden gemäß der Wiederholungsrichtlinie (ContextWithRetryPolicy oder RetryPolicies) wiederholt.
+//
+// Parameter:
+//   - ctx context.Context: Der Context des Aufrufs.
+//   - name string: Der Name der Funktion, die auf der Gegenseite aufgerufen werden soll.
+//   - params []interface{}: Ein Slice von Parametern, die an die Funktion übergeben werden.
+//   - returnDataType []reflect.Type: Ein Slice von Rückgabetypen, die die erwarteten Rückgabewerte der Funktion definieren.
+//
+// Rückgabe:
+//   - []interface{}: Ein Slice von Rückgabewerten der aufgerufenen Funktion.
+//   - error: Ein Fehler, falls beim Aufrufen der Funktion ein Problem aufgetreten ist, ansonsten nil.
+func (s *BngConn) CallFunctionContext(ctx context.Context, name string, params []interface{}, returnDataType []reflect.Type) ([]interface{}, error) {
+	// Die Funktion auf der Gegenseite wird über alle Interceptoren aufgerufen
+	invoker := chainUnaryClientInterceptors(s.config.ClientInterceptors, func(ctx context.Context, conn *BngConn, method string, params []interface{}, returnDataType []reflect.Type) ([]interface{}, error) {
+		return _CallFunctionWithRetry(ctx, conn, method, params, returnDataType)
+	})
+	data, err := invoker(ctx, s, name, params, returnDataType)
 	if err != nil {
 		return nil, err
 	}
@@ -161,10 +265,132 @@ func (s *BngConn) CallFunction(name string, params []interface{}, returnDataType
 	return data, nil
 }
 
+// ListRemoteFunctions ruft die Beschreibung der Gegenseite ab.
+// Diese enthält alle registrierten Funktionen mit ihren Parameter- und Rückgabetypen sowie die IDs der
+// geöffneten Channel Listener, z.B. um die Kompatibilität beim Verbindungsaufbau zu prüfen.
+//
+// Rückgabe:
+//   - *BngServiceDescription: Die Beschreibung der Gegenseite.
+//   - error: Ein Fehler, falls die Beschreibung nicht abgerufen werden konnte, ansonsten nil.
+func (s *BngConn) ListRemoteFunctions() (*BngServiceDescription, error) {
+	return _ListRemoteFunctions(context.Background(), s)
+}
+
+// NewChannel erzeugt einen neuen Channel, welcher als Parameter oder Rückgabewert eines RPC Aufrufs
+// an die Gegenseite übergeben werden kann. Die Gegenseite erhält den verbundenen *BngConnChannel,
+// Schreibvorgänge warten bis zu diesem Beitritt.
+//
+// Rückgabe:
+//   - *BngConnChannel: Der neue Channel.
+//   - error: Ein Fehler, falls der Channel nicht erzeugt werden konnte, ansonsten nil.
+func (s *BngConn) NewChannel() (*BngConnChannel, error) {
+	return _NewChannel(context.Background(), s)
+}
+
+// NewChannelContext erzeugt wie NewChannel einen neuen Channel, welcher geschlossen wird sobald der Context endet.
+// Wird der Context des Aufrufs verwendet, ist die Lebensdauer des Channels an den Aufruf gebunden.
+//
+// Parameter:
+//   - ctx context.Context: Der Context, an den die Lebensdauer des Channels gebunden ist.
+//
+// Rückgabe:
+//   - *BngConnChannel: Der neue Channel.
+//   - error: Ein Fehler, falls der Channel nicht erzeugt werden konnte, ansonsten nil.
+func (s *BngConn) NewChannelContext(ctx context.Context) (*BngConnChannel, error) {
+	return _NewChannel(ctx, s)
+}
+
+// Notify ruft eine Funktion der Gegenseite auf, ohne auf eine Antwort zu warten.
+// Die Gegenseite führt die Funktion aus und verwirft deren Rückgabewerte, Fehler der Funktion werden nur
+// auf der Gegenseite protokolliert. Der Aufruf ist abgeschlossen, sobald die Nachricht übertragen wurde.
+//
+// Parameter:
+//   - name string: Der Name der Funktion.
+//   - params ...interface{}: Die Parameter, welche beim Aufruf übergeben werden.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls die Nachricht nicht übertragen werden konnte, ansonsten nil.
+func (s *BngConn) Notify(name string, params ...interface{}) error {
+	return s.NotifyContext(context.Background(), name, params...)
+}
+
+// NotifyContext entspricht Notify, die Metadaten und der Trace Context werden aus ctx übernommen.
+//
+// Parameter:
+//   - ctx context.Context: Der Context, aus dem die Metadaten übernommen werden.
+//   - name string: Der Name der Funktion.
+//   - params ...interface{}: Die Parameter, welche beim Aufruf übergeben werden.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls die Nachricht nicht übertragen werden konnte, ansonsten nil.
+func (s *BngConn) NotifyContext(ctx context.Context, name string, params ...interface{}) error {
+	return _Notify(ctx, s, name, params)
+}
+
+// Batch erzeugt einen neuen Batch, mit dem mehrere Funktionen der Gegenseite in einer Nachricht aufgerufen werden.
+// Die Gegenseite führt die Aufrufe nebenläufig aus und sendet alle Ergebnisse gemeinsam zurück.
+//
+//	results, err := conn.Batch().
+//		Call("a", nil, []reflect.Type{reflect.TypeFor[string]()}).
+//		Call("b", []interface{}{int64(1)}, nil).
+//		Do()
+func (s *BngConn) Batch() *BngBatch {
+	return &BngBatch{conn: s}
+}
+
+// Subscribe abonniert ein Topic bei der Gegenseite. Die Teile eines Topics werden durch einen Punkt getrennt,
+// "*" passt auf genau einen Teil und ">" auf alle verbleibenden Teile (z.B. "jobs.*.status" oder "config.>").
+// Die Funktion kehrt zurück, sobald die Gegenseite das Abonnement bestätigt hat. Der Handler wird für jede
+// passende Nachricht in der Reihenfolge der Veröffentlichung aufgerufen und nie nebenläufig zu sich selbst,
+// die Handler verschiedener Abonnements laufen unabhängig voneinander.
+//
+// Parameter:
+//   - topic string: Das zu abonnierende Topic.
+//   - handler func(*BngMessage): Wird für jede passende Nachricht aufgerufen.
+//
+// Rückgabe:
+//   - *BngSubscription: Das Abonnement, kann mit Unsubscribe beendet werden.
+//   - error: Ein Fehler, falls das Topic ungültig ist oder von der Gegenseite abgelehnt wurde, ansonsten nil.
+func (s *BngConn) Subscribe(topic string, handler func(*BngMessage)) (*BngSubscription, error) {
+	return _Subscribe(s, topic, handler)
+}
+
+// Publish veröffentlicht eine Nachricht unter dem Topic. Die Nachricht wird nur übertragen,
+// wenn die Gegenseite ein passendes Topic abonniert hat.
+//
+// Parameter:
+//   - topic string: Das Topic der Nachricht, Wildcards sind nicht zulässig.
+//   - value interface{}: Der zu veröffentlichende Wert, dieser wird mit dem ausgehandelten Codec übertragen.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls das Topic ungültig ist oder die Nachricht nicht übertragen werden konnte, ansonsten nil.
+func (s *BngConn) Publish(topic string, value interface{}) error {
+	return _Publish(s, topic, value)
+}
+
+// CallStream ruft eine Stream Funktion der Gegenseite auf. Die Funktion muss mit der Signatur
+// func(req *BngRequest, stream *BngStream, params...) error registriert worden sein.
+// Über den zurückgegebenen Stream können Werte gesendet und empfangen werden, der Aufruf ist beendet
+// sobald Recv bzw. Next das Ende des Streams meldet. Client Interceptoren werden nicht angewendet.
+//
+// Parameter:
+//   - ctx context.Context: Der Context des Aufrufs, läuft dieser ab wird der Stream abgebrochen.
+//   - name string: Der Name der Stream Funktion.
+//   - params []interface{}: Die Parameter, welche beim Aufruf übergeben werden.
+//
+// Rückgabe:
+//   - *BngStream: Der Stream des Aufrufs.
+//   - error: Ein Fehler, falls der Aufruf nicht gestartet oder von der Gegenseite abgelehnt wurde, ansonsten nil.
+func (s *BngConn) CallStream(ctx context.Context, name string, params []interface{}) (*BngStream, error) {
+	return _CallStream(ctx, s, name, params)
+}
+
 // Close wird verwendet, um die Verbindung zu schließen.
 // Diese Methode prüft zunächst, ob die Verbindung bereits geschlossen wurde.
 // Falls nicht, wird die Verbindung vollständig geschlossen.
 // Bei erfolgreichem Schließen wird nil zurückgegeben, andernfalls der aufgetretene Fehler.
+// Close wartet nicht auf noch laufende Funktionsaufrufe und kann daher auch innerhalb einer aufgerufenen
+// Funktion verwendet werden, deren Abschluss wird über Done signalisiert.
 //
 // Rückgabe:
 //   - error: Ein Fehler, falls beim Schließen der Verbindung ein Problem aufgetreten ist, ansonsten nil.
@@ -202,6 +428,16 @@ func (s *BngConn) RemoteAddr() net.Addr {
 	return s.conn.RemoteAddr()
 }
 
+// PeerCredentials gibt die Zugangsdaten (PID, UID, GID) des Prozesses der Gegenseite zurück.
+// Diese werden vom Betriebssystem bereitgestellt und können von der Gegenseite nicht gefälscht werden.
+//
+// Rückgabe:
+//   - *PeerCredentials: Die Zugangsdaten der Gegenseite.
+//   - error: ErrPeerCredentialsUnsupported, falls es sich nicht um einen Unix-Socket unter Linux handelt, ansonsten nil.
+func (s *BngConn) PeerCredentials() (*PeerCredentials, error) {
+	return peerCredentials(s.conn)
+}
+
 // SetDeadline setzt die Lese- und Schreib-Deadlines, die mit der Verbindung verknüpft sind.
 // Es ist äquivalent zum gleichzeitigen Aufruf von SetReadDeadline und SetWriteDeadline.
 //
@@ -2,6 +2,14 @@ package bngsocket
 
 import "sync"
 
+// Zustände des ACK-Handlers
+const (
+	ackStateWaiting  uint8 = 0 // Es wurde noch keine Rückmeldung empfangen
+	ackStateReceived uint8 = 1 // Es wurde ein ACK empfangen
+	ackStateRejected uint8 = 2 // Es wurde ein NACK empfangen
+	ackStateClosed   uint8 = 3 // Die Verbindung wurde geschlossen, es werden keine Rückmeldungen mehr erwartet
+)
+
 // newConnACK erstellt ein neues _ConnACK-Objekt.
 // Diese Funktion initialisiert die Synchronisationsmechanismen (Mutex und Bedingungsvariable)
 // sowie den Anfangszustand des ACK-Handlers.
@@ -9,25 +17,36 @@ func newConnACK() *_ConnACK {
 	n := new(_ConnACK)
 	n.mutex = new(sync.Mutex)
 	n.cond = sync.NewCond(n.mutex)
-	n.state = 0
+	n.state = ackStateWaiting
 	return n
 }
 
 // WaitOfACK wartet auf ein ACK (Acknowledgment).
-// Diese Methode blockiert, bis der Zustand des ACK-Handlers auf 1 gesetzt wird,
-// was bedeutet, dass ein ACK empfangen wurde. Nach dem Empfangen wird der Zustand
-// wieder auf 0 zurückgesetzt.
+// Diese Methode blockiert, bis eine Rückmeldung der Gegenseite empfangen wurde.
+// Nach dem Empfangen wird der Zustand wieder zurückgesetzt. Wurde ein NACK
+// empfangen, wird ErrFrameNACK zurückgegeben.
 func (n *_ConnACK) WaitOfACK() error {
 	n.mutex.Lock()
 	defer n.mutex.Unlock()
 
-	// Warten, bis der Zustand ungleich null ist
-	for n.state == 0 {
+	// Warten, bis eine Rückmeldung vorhanden ist
+	for n.state == ackStateWaiting {
 		n.cond.Wait()
 	}
 
-	// Der Zustand wird nach dem Empfang des ACK wieder auf 0 gesetzt
-	n.state = 0
+	// Wurde die Verbindung geschlossen, bleibt der Zustand bestehen
+	if n.state == ackStateClosed {
+		return ErrConnectionClosed
+	}
+
+	// Der Zustand wird nach dem Empfang wieder zurückgesetzt
+	state := n.state
+	n.state = ackStateWaiting
+
+	// Es wird geprüft ob der Frame abgelehnt wurde
+	if state == ackStateRejected {
+		return ErrFrameNACK
+	}
 
 	return nil
 }
@@ -39,10 +58,48 @@ func (n *_ConnACK) EnterACK() error {
 	n.mutex.Lock()
 	defer n.mutex.Unlock()
 
+	// Nach dem Schließen werden keine Rückmeldungen mehr angenommen
+	if n.state == ackStateClosed {
+		return ErrConnectionClosed
+	}
+
 	// Zustand setzen, um den Empfang des ACK anzuzeigen
-	n.state = 1
+	n.state = ackStateReceived
 
 	// Alle wartenden Goroutinen signalisieren, dass ein ACK empfangen wurde
 	n.cond.Broadcast()
 	return nil
 }
+
+// EnterNACK signalisiert den Empfang eines NACK (Negative Acknowledgment).
+// Die Gegenseite hat einen Frame mit einer fehlerhaften Checksumme empfangen
+// und fordert diesen erneut an.
+func (n *_ConnACK) EnterNACK() error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	// Nach dem Schließen werden keine Rückmeldungen mehr angenommen
+	if n.state == ackStateClosed {
+		return ErrConnectionClosed
+	}
+
+	// Zustand setzen, um den Empfang des NACK anzuzeigen
+	n.state = ackStateRejected
+
+	// Alle wartenden Goroutinen signalisieren, dass ein NACK empfangen wurde
+	n.cond.Broadcast()
+	return nil
+}
+
+// Close gibt alle wartenden Goroutinen frei, da keine Rückmeldungen mehr eintreffen werden.
+// Nach dem Aufruf gibt WaitOfACK immer ErrConnectionClosed zurück.
+func (n *_ConnACK) Close() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	// Zustand setzen, um das Schließen anzuzeigen
+	n.state = ackStateClosed
+
+	// Alle wartenden Goroutinen werden freigegeben
+	n.cond.Broadcast()
+}
@@ -0,0 +1,275 @@
+package bngsocket
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go/transport"
+	"github.com/google/uuid"
+)
+
+// Call fügt dem Batch einen Aufruf hinzu, die Parameter entsprechen CallFunction.
+//
+// Parameter:
+//   - name string: Der Name der Funktion.
+//   - params []interface{}: Die Parameter, welche beim Aufruf übergeben werden.
+//   - returnDataType []reflect.Type: Die erwarteten Rückgabetypen.
+//
+// Rückgabe:
+//   - *BngBatch: Der Batch, damit weitere Aufrufe angehängt werden können.
+func (b *BngBatch) Call(name string, params []interface{}, returnDataType []reflect.Type) *BngBatch {
+	b.calls = append(b.calls, &_BatchCall{name: name, params: params, returnDataType: returnDataType})
+	return b
+}
+
+// Do führt alle Aufrufe des Batches aus.
+//
+// Rückgabe:
+//   - []BngBatchResult: Die Ergebnisse in der Reihenfolge der Aufrufe.
+//   - error: Ein Fehler, falls der Batch nicht übertragen werden konnte, ansonsten nil.
+func (b *BngBatch) Do() ([]BngBatchResult, error) {
+	return b.DoContext(context.Background())
+}
+
+// DoContext führt alle Aufrufe des Batches aus, die Metadaten und die Deadline werden aus ctx übernommen.
+// Client Interceptoren werden nicht angewendet.
+//
+// Parameter:
+//   - ctx context.Context: Der Context des Batches.
+//
+// Rückgabe:
+//   - []BngBatchResult: Die Ergebnisse in der Reihenfolge der Aufrufe.
+//   - error: Ein Fehler, falls der Batch nicht übertragen werden konnte, ansonsten nil.
+func (b *BngBatch) DoContext(ctx context.Context) ([]BngBatchResult, error) {
+	return _CallBatch(ctx, b.conn, b.calls)
+}
+
+// Wird verwendet um RPC Batch Anfragen zu verarbeiten, die Aufrufe werden mit höchstens
+// BatchConcurrency Routinen nebenläufig ausgeführt. Batches mit mehr als MaxBatchSize Aufrufen
+// werden nicht ausgeführt, jeder Aufruf erhält ErrBatchTooLarge als Antwort.
+func processRpcBatchRequest(o *BngConn, batch *transport.RpcBatchRequest) error {
+	responses := make([]*transport.RpcResponse, len(batch.Requests))
+	if len(batch.Requests) > o.config.MaxBatchSize {
+		// Der Batch wird abgelehnt, ohne die Aufrufe auszuführen
+		tooLarge := fmt.Errorf("%w: %d calls, limit %d", ErrBatchTooLarge, len(batch.Requests), o.config.MaxBatchSize)
+		for i, rpcReq := range batch.Requests {
+			responses[i] = newRpcErrorResponse(rpcReq.Id, tooLarge, nil)
+		}
+	} else {
+		// Die Aufrufe werden nebenläufig ausgeführt, ein Verarbeitungsfehler betrifft nur den jeweiligen Aufruf
+		workers := make(chan struct{}, o.config.BatchConcurrency)
+		var wg sync.WaitGroup
+		for i, rpcReq := range batch.Requests {
+			workers <- struct{}{}
+			wg.Add(1)
+			go func(i int, rpcReq *transport.RpcRequest) {
+				defer func() {
+					<-workers
+					wg.Done()
+				}()
+				response, err := executeRpcRequest(o, rpcReq)
+				if err != nil {
+					response = newRpcErrorResponse(rpcReq.Id, err, nil)
+				}
+				responses[i] = response
+			}(i, rpcReq)
+		}
+		wg.Wait()
+	}
+
+	// Die Antworten werden gemeinsam zurückgesendet
+	if err := convertAndWriteBytesIntoChan(o, &transport.RpcBatchResponse{Type: "rpcbatchres", Id: batch.Id, Responses: responses}); err != nil {
+		return fmt.Errorf("bngsocket->processRpcBatchRequest: " + err.Error())
+	}
+	return nil
+}
+
+// Wird verwendet um eine RPC Batch Response entgegenzunehmen
+func processRpcBatchResponse(o *BngConn, batchResp *transport.RpcBatchResponse) (err error) {
+	// Es wird geprüft ob es eine Offene Sitzung gibt
+	session, found := o.openRpcBatches.Load(batchResp.Id)
+	if !found {
+		return fmt.Errorf("bngsocket->processRpcBatchResponse[0]: unkown rpc batch session")
+	}
+
+	// Die Antwort wird in den Chan geschrieben
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("bngsocket->processRpcBatchResponse[1]: session panicked: %v", r)
+		}
+	}()
+	session <- batchResp
+
+	return nil
+}
+
+// Ruft mehrere Funktionen der Gegenseite in einer Nachricht auf
+func _CallBatch(ctx context.Context, s *BngConn, calls []*_BatchCall) (results []BngBatchResult, err error) {
+	// Ein leerer Batch wird nicht übertragen
+	if len(calls) == 0 {
+		return nil, nil
+	}
+
+	// Es wird geprüft ob die Verbindung getrennt wurde
+	if connectionIsClosed(s) {
+		return nil, io.EOF
+	}
+
+	// Es wird geprüft ob neue Aufrufe gestartet werden dürfen
+	if err := acceptsNewOperations(s); err != nil {
+		return nil, err
+	}
+
+	// Es wird ein Span für den Batch erzeugt, die Dauer wird für jeden Aufruf erfasst
+	ctx, span := s.tracer.Start(ctx, "batch", SpanKindClient)
+	start := time.Now()
+	results = make([]BngBatchResult, len(calls))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+		for i, call := range calls {
+			callErr := err
+			if callErr == nil {
+				callErr = results[i].Err
+			}
+			s.metrics.RpcCallFinished(call.name, time.Since(start), callErr)
+		}
+	}()
+
+	// Die Aufrufe werden umgewandelt, ungültige Aufrufe werden nicht übertragen
+	batch := &transport.RpcBatchRequest{Type: "rpcbatchreq", Id: strings.ReplaceAll(uuid.NewString(), "-", "")}
+	indexById := make(map[string]int, len(calls))
+	metadata, timeout := injectRpcMetadata(s, ctx), rpcTimeout(ctx)
+	var hiddenIds []string
+	defer func() { releaseHiddenFunctions(s, hiddenIds) }()
+	for i, call := range calls {
+		rpcReq, ids, err := newBatchRpcRequest(s, call, metadata, timeout)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		hiddenIds = append(hiddenIds, ids...)
+		indexById[rpcReq.Id] = i
+		batch.Requests = append(batch.Requests, rpcReq)
+	}
+	if len(batch.Requests) == 0 {
+		return results, nil
+	}
+	span.SetAttribute(logKeyRpcId, batch.Id)
+
+	// Das Paket wird in Bytes umgewandelt
+	bytedData, err := marshalMessage(s, batch)
+	if err != nil {
+		return nil, fmt.Errorf("bngsocket->_CallBatch[0]: " + err.Error())
+	}
+
+	// Der Antwort Chan wird erzeugt und zwischengespeichert
+	responseChan := make(chan *transport.RpcBatchResponse)
+	s.openRpcBatches.Store(batch.Id, responseChan)
+
+	// Das Paket wird gesendet
+	if err := writeBytesIntoSocketConn(s, bytedData); err != nil {
+		if _, loaded := s.openRpcBatches.LoadAndDelete(batch.Id); loaded {
+			close(responseChan)
+		}
+		if connectionIsClosed(s) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("bngsocket->_CallBatch[1]: " + err.Error())
+	}
+
+	// Es wird auf die Antwort gewartet, wurde der Chan geschlossen, ist die Verbindung beendet worden
+	var response *transport.RpcBatchResponse
+	var ok bool
+	select {
+	case response, ok = <-responseChan:
+		if !ok {
+			return nil, connectionTerminationError(s)
+		}
+	case <-ctx.Done():
+		// Die Sitzung bleibt bestehen, bis die Antwort der Gegenseite eingetroffen ist, diese wird verworfen
+		go discardRpcBatchResponse(s, batch.Id, responseChan)
+		return nil, ctx.Err()
+	}
+
+	// Die Sitzung wird entfernt
+	if _, loaded := s.openRpcBatches.LoadAndDelete(batch.Id); loaded {
+		close(responseChan)
+	}
+
+	// Die Antworten werden den Aufrufen zugeordnet
+	for _, rpcResp := range response.Responses {
+		i, found := indexById[rpcResp.Id]
+		if !found {
+			continue
+		}
+		delete(indexById, rpcResp.Id)
+		results[i].Values, results[i].Err = decodeRpcResponse(s, rpcResp, calls[i].returnDataType)
+	}
+
+	// Aufrufe ohne Antwort werden als Fehler gewertet
+	for _, i := range indexById {
+		results[i].Err = fmt.Errorf("bngsocket->_CallBatch[2]: no response for call %q", calls[i].name)
+	}
+
+	return results, nil
+}
+
+// newBatchRpcRequest erzeugt den RpcRequest eines einzelnen Aufrufs eines Batches. Die Parameter werden
+// wie bei einem Einzelaufruf vorbereitet, übergebene Funktionen werden als Callbacks registriert.
+//
+// Rückgabe:
+//   - *transport.RpcRequest: Der erzeugte Aufruf.
+//   - []string: Die IDs der registrierten Callbacks, diese müssen nach dem Batch freigegeben werden.
+//   - error: Ein Fehler, falls ein Parameter nicht übertragen werden kann, ansonsten nil.
+func newBatchRpcRequest(s *BngConn, call *_BatchCall, metadata map[string]string, timeout int64) (*transport.RpcRequest, []string, error) {
+	// Die Parameter werden geprüft, übergebene Funktionen werden als Callbacks registriert
+	params, hiddenIds, err := prepareRpcParams(s, call.params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Die Parameter werden umgewandelt
+	convertedParams, err := processRpcGoDataTypeTransportable(messageCodec(s), params...)
+	if err != nil {
+		releaseHiddenFunctions(s, hiddenIds)
+		return nil, nil, fmt.Errorf("bngsocket->newBatchRpcRequest[0]: " + err.Error())
+	}
+
+	// Die Rückgabetypen werden umgewandelt
+	returnDataTypes, err := processRpcGoDataTypeTransportableDatatype(call.returnDataType)
+	if err != nil {
+		releaseHiddenFunctions(s, hiddenIds)
+		return nil, nil, fmt.Errorf("bngsocket->newBatchRpcRequest[1]: " + err.Error())
+	}
+
+	return &transport.RpcRequest{
+		Type:         "rpcreq",
+		Params:       convertedParams,
+		ReturnDTypes: returnDataTypes,
+		Name:         call.name,
+		Id:           strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Metadata:     metadata,
+		Timeout:      timeout,
+	}, hiddenIds, nil
+}
+
+// discardRpcBatchResponse wartet auf die Antwort eines abgebrochenen Batches und verwirft diese.
+func discardRpcBatchResponse(o *BngConn, id string, responseChan chan *transport.RpcBatchResponse) {
+	// Wurde der Chan geschlossen, ist die Verbindung beendet worden
+	if _, ok := <-responseChan; !ok {
+		return
+	}
+
+	// Die Sitzung wird entfernt
+	if _, loaded := o.openRpcBatches.LoadAndDelete(id); loaded {
+		close(responseChan)
+	}
+}
@@ -0,0 +1,293 @@
+package bngsocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/custodia-cenv/bngsocket-go/transport"
+	"github.com/google/uuid"
+)
+
+const (
+	// Präfix der versteckten Funktionen, unter denen die Callbacks eines Aufrufs registriert werden
+	callbackFunctionPrefix = reservedFunctionPrefix + "cb."
+
+	// Eingebaute Funktion, über welche die Gegenseite einen Callback freigibt
+	releaseFunctionName = reservedFunctionPrefix + "release"
+)
+
+// isCallbackParameterType gibt an ob der Parameter einer registrierten Funktion einen Callback entgegennimmt.
+func isCallbackParameterType(t reflect.Type) bool {
+	return t.Kind() == reflect.Func || t == reflect.TypeOf((*BngCallback)(nil))
+}
+
+// rpcMethodLabel gibt den Namen zurück, unter dem ein Aufruf in Spans und Metriken erfasst wird.
+// Callbacks werden unabhängig von ihrer ID zusammengefasst, damit die Anzahl der Namen begrenzt bleibt.
+func rpcMethodLabel(name string) string {
+	if strings.HasPrefix(name, callbackFunctionPrefix) {
+		return strings.TrimSuffix(callbackFunctionPrefix, ".")
+	}
+	return name
+}
+
+// builtinReleaseCallback gibt einen versteckten Callback frei, sobald die Gegenseite ihn nicht mehr benötigt.
+func builtinReleaseCallback(req *BngRequest, id string) error {
+	if !strings.HasPrefix(id, callbackFunctionPrefix) {
+		return fmt.Errorf("bngsocket->builtinReleaseCallback[0]: invalid callback id")
+	}
+	req.Conn.hiddenFunctions.Delete(id)
+	return nil
+}
+
+// registerHiddenFunctions registriert alle Funktionen der Parameter eines Aufrufs als versteckte Funktionen
+// und ersetzt sie durch ihre ID. Funktionen ohne *BngRequest als ersten Parameter werden entsprechend erweitert.
+//
+// Parameter:
+//   - s *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - params []interface{}: Die Parameter des Aufrufs.
+//
+// Rückgabe:
+//   - []interface{}: Die Parameter, in denen die Funktionen durch *transport.RpcHiddenFunction ersetzt wurden.
+//   - []string: Die IDs der registrierten Funktionen, diese müssen nach dem Aufruf freigegeben werden.
+//   - error: Ein Fehler, falls eine Funktion ungültig ist, ansonsten nil.
+func registerHiddenFunctions(s *BngConn, params []interface{}) ([]interface{}, []string, error) {
+	var ids []string
+	var replaced []interface{}
+	for i, item := range params {
+		fnValue := reflect.ValueOf(item)
+		if fnValue.Kind() != reflect.Func {
+			continue
+		}
+
+		// Die Parameter werden nur kopiert, wenn eine Funktion vorhanden ist
+		if replaced == nil {
+			replaced = append(make([]interface{}, 0, len(params)), params...)
+		}
+
+		// Funktionen ohne *BngRequest erhalten diesen als ersten Parameter
+		fnValue = withRequestParameter(fnValue)
+		if err := validateRPCFunction(fnValue, fnValue.Type(), true); err != nil {
+			releaseHiddenFunctions(s, ids)
+			return nil, nil, fmt.Errorf("registerHiddenFunctions[0]: parameter %d: %s", i, err.Error())
+		}
+
+		// Die Funktion wird unter einer neuen ID registriert
+		id := callbackFunctionPrefix + strings.ReplaceAll(uuid.NewString(), "-", "")
+		s.hiddenFunctions.Store(id, fnValue)
+		ids = append(ids, id)
+		replaced[i] = &transport.RpcHiddenFunction{FunctionId: id}
+	}
+
+	// Es wurden keine Funktionen übergeben
+	if replaced == nil {
+		return params, nil, nil
+	}
+	return replaced, ids, nil
+}
+
+// releaseHiddenFunctions entfernt die versteckten Funktionen eines abgeschlossenen Aufrufs.
+func releaseHiddenFunctions(s *BngConn, ids []string) {
+	for _, id := range ids {
+		s.hiddenFunctions.Delete(id)
+	}
+}
+
+// withRequestParameter erweitert eine Funktion ohne *BngRequest als ersten Parameter um diesen.
+func withRequestParameter(fn reflect.Value) reflect.Value {
+	fnType := fn.Type()
+	requestType := reflect.TypeOf((*BngRequest)(nil))
+	if fnType.NumIn() > 0 && fnType.In(0) == requestType {
+		return fn
+	}
+
+	// Die Parameter und Rückgabewerte werden übernommen
+	in := []reflect.Type{requestType}
+	for i := 0; i < fnType.NumIn(); i++ {
+		in = append(in, fnType.In(i))
+	}
+	out := make([]reflect.Type, 0, fnType.NumOut())
+	for i := 0; i < fnType.NumOut(); i++ {
+		out = append(out, fnType.Out(i))
+	}
+
+	// Der *BngRequest wird beim Aufruf verworfen
+	wrappedType := reflect.FuncOf(in, out, fnType.IsVariadic())
+	return reflect.MakeFunc(wrappedType, func(args []reflect.Value) []reflect.Value {
+		if fnType.IsVariadic() {
+			return fn.CallSlice(args[1:])
+		}
+		return fn.Call(args[1:])
+	})
+}
+
+// newCallbackParameter erzeugt aus einem übertragenen Callback den Parameter der aufgerufenen Funktion.
+// Dies ist entweder ein *BngCallback oder eine Funktion, welche den Callback auf der Gegenseite aufruft.
+//
+// Parameter:
+//   - req *BngRequest: Die Anfrage, über deren Verbindung der Callback aufgerufen wird.
+//   - capsle *transport.RpcDataCapsle: Der übertragene Parameter.
+//   - expectedType reflect.Type: Der Typ des Parameters der aufgerufenen Funktion.
+//
+// Rückgabe:
+//   - reflect.Value: Der Parameter für die aufgerufene Funktion.
+//   - error: Ein Fehler, falls der Callback ungültig ist, ansonsten nil.
+func newCallbackParameter(req *BngRequest, capsle *transport.RpcDataCapsle, expectedType reflect.Type) (reflect.Value, error) {
+	// Der Parameter muss einen Callback entgegennehmen
+	if !isCallbackParameterType(expectedType) {
+		return reflect.Value{}, fmt.Errorf("newCallbackParameter[0]: callback transmitted, function expects %s", expectedType)
+	}
+
+	// Die ID der versteckten Funktion wird ausgelesen
+	var id string
+	switch value := capsle.Value.(type) {
+	case map[string]interface{}:
+		id, _ = value["id"].(string)
+	case *transport.RpcHiddenFunction:
+		id = value.FunctionId
+	}
+	if !strings.HasPrefix(id, callbackFunctionPrefix) {
+		return reflect.Value{}, fmt.Errorf("newCallbackParameter[1]: invalid callback id")
+	}
+
+	// Der Context des Callbacks übernimmt die Werte (z.B. den Trace Context) des Aufrufs, wird jedoch erst mit der
+	// Freigabe des Callbacks oder dem Beenden der Verbindung abgebrochen, da der Callback den Aufruf überdauern kann
+	ctx, cancel := newRpcRequestContext(req.Conn, context.WithoutCancel(req.Context()), 0)
+
+	// Der Callback wird beim Garbage Collector registriert, damit er freigegeben wird sobald er nicht mehr erreichbar ist
+	callback := &BngCallback{
+		conn:     req.Conn,
+		ctx:      ctx,
+		cancel:   cancel,
+		id:       id,
+		released: newSafeBool(false),
+	}
+	runtime.SetFinalizer(callback, func(cb *BngCallback) {
+		go cb.Release()
+	})
+
+	// Die Funktion verwendet den *BngCallback direkt
+	if expectedType.Kind() != reflect.Func {
+		return reflect.ValueOf(callback), nil
+	}
+
+	// Es wird eine Funktion erzeugt, welche den Callback auf der Gegenseite aufruft
+	returnDataType := make([]reflect.Type, 0, expectedType.NumOut())
+	for i := 0; i < expectedType.NumOut()-1; i++ {
+		returnDataType = append(returnDataType, expectedType.Out(i))
+	}
+	proxy := reflect.MakeFunc(expectedType, func(args []reflect.Value) []reflect.Value {
+		params := make([]interface{}, 0, len(args))
+		for _, arg := range args {
+			params = append(params, arg.Interface())
+		}
+		values, err := callback.Call(params, returnDataType)
+		return callbackProxyResults(expectedType, values, err)
+	})
+	return proxy, nil
+}
+
+// callbackProxyResults wandelt die Rückgabewerte eines Callbacks in die Rückgabewerte der Proxy Funktion um.
+// Bei einem Fehler oder einem nicht passenden Rückgabewert werden die Nullwerte und der Fehler zurückgegeben.
+func callbackProxyResults(fnType reflect.Type, values []interface{}, err error) []reflect.Value {
+	results := make([]reflect.Value, fnType.NumOut())
+	for i := 0; i < fnType.NumOut()-1; i++ {
+		results[i] = reflect.Zero(fnType.Out(i))
+		if err != nil || i >= len(values) || values[i] == nil {
+			continue
+		}
+
+		// Structs werden bereits als Zeiger zurückgegeben, alle anderen Werte werden umgewandelt
+		var value reflect.Value
+		if fnType.Out(i).Kind() == reflect.Ptr {
+			value = reflect.ValueOf(values[i])
+		} else {
+			value, err = processGoValueToRelectType(values[i], fnType.Out(i))
+			if err != nil {
+				err = fmt.Errorf("bngsocket->callbackProxyResults[0]: " + err.Error())
+				continue
+			}
+		}
+
+		// Der Wert muss dem Rückgabetyp der Proxy Funktion entsprechen, ansonsten würde reflect einen Panic auslösen
+		if !value.Type().AssignableTo(fnType.Out(i)) {
+			err = fmt.Errorf("bngsocket->callbackProxyResults[1]: %w: return value %d is %s, expected %s", ErrSignatureMismatch, i, value.Type(), fnType.Out(i))
+			continue
+		}
+		results[i] = value
+	}
+
+	// Der Fehler wird als letzter Rückgabewert gesetzt
+	errValue := reflect.Zero(fnType.Out(fnType.NumOut() - 1))
+	if err != nil {
+		for i := 0; i < fnType.NumOut()-1; i++ {
+			results[i] = reflect.Zero(fnType.Out(i))
+		}
+		errValue = reflect.ValueOf(&err).Elem()
+	}
+	results[fnType.NumOut()-1] = errValue
+	return results
+}
+
+// Call ruft den Callback auf der Gegenseite mit dem Context des Callbacks auf. Dieser enthält die Werte des
+// ursprünglichen Aufrufs und wird mit der Freigabe des Callbacks oder dem Beenden der Verbindung abgebrochen.
+//
+// Parameter:
+//   - params []interface{}: Ein Slice von Parametern, die an den Callback übergeben werden.
+//   - returnDataType []reflect.Type: Die erwarteten Rückgabetypen des Callbacks (ohne den abschließenden error).
+//
+// Rückgabe:
+//   - []interface{}: Die Rückgabewerte des Callbacks.
+//   - error: ErrCallbackReleased, falls der Callback bereits freigegeben wurde, oder der Fehler des Callbacks.
+func (cb *BngCallback) Call(params []interface{}, returnDataType []reflect.Type) ([]interface{}, error) {
+	return cb.CallContext(cb.ctx, params, returnDataType)
+}
+
+// CallContext ruft den Callback auf der Gegenseite auf, der Context begrenzt die Wartezeit auf die Antwort.
+//
+// Parameter:
+//   - ctx context.Context: Der Context des Aufrufs.
+//   - params []interface{}: Ein Slice von Parametern, die an den Callback übergeben werden.
+//   - returnDataType []reflect.Type: Die erwarteten Rückgabetypen des Callbacks (ohne den abschließenden error).
+//
+// Rückgabe:
+//   - []interface{}: Die Rückgabewerte des Callbacks.
+//   - error: ErrCallbackReleased, falls der Callback bereits freigegeben wurde, oder der Fehler des Callbacks.
+func (cb *BngCallback) CallContext(ctx context.Context, params []interface{}, returnDataType []reflect.Type) ([]interface{}, error) {
+	// Es wird geprüft ob der Callback bereits freigegeben wurde
+	if cb.released.Get() {
+		return nil, ErrCallbackReleased
+	}
+
+	// Der Callback wird auf der Gegenseite aufgerufen, ist er dort nicht mehr vorhanden, wurde der Aufruf abgeschlossen
+	values, err := cb.conn.CallFunctionContext(ctx, cb.id, params, returnDataType)
+	if errors.Is(err, ErrUnkownRpcFunction) {
+		cb.released.Set(true)
+		return nil, ErrCallbackReleased
+	}
+	return values, err
+}
+
+// Release gibt den Callback frei, die Gegenseite entfernt daraufhin die versteckte Funktion.
+// Weitere Aufrufe des Callbacks geben ErrCallbackReleased zurück.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls die Freigabe nicht übermittelt werden konnte, ansonsten nil.
+func (cb *BngCallback) Release() error {
+	// Der Callback wird nur einmal freigegeben
+	var err error
+	cb.releaseOnce.Do(func() {
+		cb.released.Set(true)
+		defer cb.cancel()
+
+		// Ist die Verbindung bereits beendet, hat die Gegenseite den Callback bereits entfernt
+		if cb.ctx.Err() != nil || connectionIsClosed(cb.conn) {
+			return
+		}
+		err = _Notify(context.Background(), cb.conn, releaseFunctionName, []interface{}{cb.id})
+	})
+	return err
+}
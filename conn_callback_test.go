@@ -0,0 +1,28 @@
+package bngsocket
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCallbackProxyResultsTypeMismatch(t *testing.T) {
+	fnType := reflect.TypeFor[func() (*BngRequest, error)]()
+
+	// Ein nicht passender Rückgabewert wird als Fehler zurückgegeben, anstatt einen Panic auszulösen
+	results := callbackProxyResults(fnType, []interface{}{"value"}, nil)
+	if len(results) != 2 || !results[0].IsNil() {
+		t.Fatalf("unexpected results %v", results)
+	}
+	err, _ := results[1].Interface().(error)
+	if !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("expected ErrSignatureMismatch, got %v", err)
+	}
+
+	// Passende Rückgabewerte werden übernommen
+	req := &BngRequest{}
+	results = callbackProxyResults(fnType, []interface{}{req}, nil)
+	if results[0].Interface() != req || !results[1].IsNil() {
+		t.Fatalf("unexpected results %v", results)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"sync"
 
 	"github.com/custodia-cenv/bngsocket-go/transport"
@@ -11,6 +12,17 @@ import (
 
 // Wird verwendet um eintreffende Channel Request Pakete zu verarbeiten
 func (s *BngConn) _ProcessIncommingChannelRequestPackage(channlrequest *transport.ChannelRequest) error {
+	// Wird die Verbindung heruntergefahren, werden keine neuen Channel mehr angenommen
+	if s.draining.Get() {
+		if err := responseChannelGoingAway(s, channlrequest.RequestId); err != nil {
+			if errors.Is(err, io.EOF) {
+				return io.EOF
+			}
+			return fmt.Errorf("bngsocket->_ProcessIncommingChannelRequestPackage: transmittion error")
+		}
+		return nil
+	}
+
 	// Es wird geprüft ob es einen Offenen Listener für die Angefordnerte ID gibt
 	channelListener, foundListener := s.openChannelListener.Load(channlrequest.RequestedChannelId)
 	if !foundListener {
@@ -65,7 +77,7 @@ func (s *BngConn) _ProcessIncommingChannelSessionPackage(channlrequest *transpor
 	ChannelSessionDataTransport, foundSession := s.openChannelInstances.Load(channlrequest.ChannelSessionId)
 	if !foundSession {
 		// Der Gegenseite wird mitgeteilt dass kein Offener Channl gefunden wurde
-		if err := responseChannelNotOpen(s, ChannelSessionDataTransport.sesisonId); err != nil {
+		if err := responseChannelNotOpen(s, channlrequest.ChannelSessionId); err != nil {
 			return fmt.Errorf("bngsocket->_ProcessIncommingChannelSessionPackage: " + err.Error())
 		}
 
@@ -75,6 +87,10 @@ func (s *BngConn) _ProcessIncommingChannelSessionPackage(channlrequest *transpor
 
 	// Die eingetroffenen Daten werden an den Channel übergeben
 	if err := ChannelSessionDataTransport.enterIncommingData(channlrequest.Body, channlrequest.PackageId); err != nil {
+		// Wurde der Channel in der Zwischenzeit geschlossen, wird das Paket verworfen
+		if channelWasClosedMeanwhile(ChannelSessionDataTransport, err) {
+			return nil
+		}
 		return fmt.Errorf("bngsocket->_ProcessIncommingChannelSessionPackage: " + err.Error())
 	}
 
@@ -94,7 +110,7 @@ func (s *BngConn) _ProcessIncommingChannelTransportStateResponsePackage(channlre
 	ChannelSessionDataTransport, foundSession := s.openChannelInstances.Load(channlrequest.ChannelSessionId)
 	if !foundSession {
 		// Der Gegenseite wird mitgeteilt dass kein Offener Channl gefunden wurde
-		if err := responseChannelNotOpen(s, ChannelSessionDataTransport.sesisonId); err != nil {
+		if err := responseChannelNotOpen(s, channlrequest.ChannelSessionId); err != nil {
 			return fmt.Errorf("bngsocket->_ProcessIncommingChannelTransportStateResponsePackage: " + err.Error())
 		}
 
@@ -104,6 +120,10 @@ func (s *BngConn) _ProcessIncommingChannelTransportStateResponsePackage(channlre
 
 	// Der Status wird an den Channel übergeben
 	if err := ChannelSessionDataTransport.enterChannelTransportStateResponseSate(channlrequest.PackageId, channlrequest.State); err != nil {
+		// Wurde der Channel in der Zwischenzeit geschlossen, wird die Bestätigung verworfen
+		if channelWasClosedMeanwhile(ChannelSessionDataTransport, err) {
+			return nil
+		}
 		return fmt.Errorf("bngsocket->_ProcessIncommingChannelTransportStateResponsePackage: " + err.Error())
 	}
 
@@ -117,22 +137,21 @@ func (s *BngConn) _ProcessIncommingChannelSessionSignal(channlrequest *transport
 	s.connMutex.Lock()
 	defer s.connMutex.Unlock()
 
-	// Es wird geprüft ob es einen offnen Channel gibt,
-	// wenn ja wird das Paket an diesen Weitergereicht,
-	// wenn es keinen passenden Channel gibt, wird dies der Gegenseite mitgeteilt.
+	// Es wird geprüft ob es einen offnen Channel gibt, wenn ja wird das Signal an diesen Weitergereicht.
+	// Signale für unbekannte Channel werden verworfen, da beide Seiten den Channel gleichzeitig geschlossen
+	// haben können und eine Antwort sonst erneut mit einem Signal beantwortet werden würde.
 	channelSessionDataTransport, foundSession := s.openChannelInstances.Load(channlrequest.ChannelSessionId)
 	if !foundSession {
-		// Der Gegenseite wird mitgeteilt dass kein Offener Channl gefunden wurde
-		if err := responseChannelNotOpen(s, channlrequest.ChannelSessionId); err != nil {
-			return fmt.Errorf("bngsocket->_ProcessIncommingChannelSessionSignal: " + err.Error())
-		}
-
-		// Der Vorgang wird ohne Fehler beendet
+		s.logger.Debug("Discard signal for unkown channel", slog.String(logKeyChannelSession, channlrequest.ChannelSessionId))
 		return nil
 	}
 
 	// Der Status wird an den Channel übergeben
 	if err := channelSessionDataTransport.enterSignal(channlrequest.Signal); err != nil {
+		// Wurde der Channel in der Zwischenzeit geschlossen, wird das Signal verworfen
+		if channelWasClosedMeanwhile(channelSessionDataTransport, err) {
+			return nil
+		}
 		return fmt.Errorf("bngsocket->_ProcessIncommingChannelSessionSignal: " + err.Error())
 	}
 
@@ -164,9 +183,10 @@ func (s *BngConn) _RegisterNewChannelSession(channelSessionId string) (*BngConnC
 
 	// Der Channel wird zwischengespeichert
 	s.openChannelInstances.Store(channelSessionId, bngsoc)
+	s.metrics.ChannelsChanged(1)
 
 	// Debug
-	_DebugPrint(fmt.Sprintf("BngConn(%s): Register new Channel '%s'", s._innerhid, channelSessionId))
+	s.logger.Debug("Register new Channel", slog.String(logKeyChannelSession, channelSessionId))
 
 	// Das Objekt wird zurückgegeben
 	return bngsoc, nil
@@ -175,8 +195,16 @@ func (s *BngConn) _RegisterNewChannelSession(channelSessionId string) (*BngConnC
 // Schließet eine Channel Sitzung
 func (s *BngConn) _UnregisterChannelSession(channelSessionId string) error {
 	// Die SessionId wird gelöscht
-	s.openChannelInstances.Delete(channelSessionId)
+	if _, loaded := s.openChannelInstances.LoadAndDelete(channelSessionId); loaded {
+		s.metrics.ChannelsChanged(-1)
+	}
 
 	// Es ist kein Fehler aufgetreten
 	return nil
 }
+
+// channelWasClosedMeanwhile gibt an, ob ein Paket nicht zugestellt werden konnte, weil der Channel
+// zwischen dem Nachschlagen und dem Zustellen geschlossen wurde. In diesem Fall wird das Paket verworfen.
+func channelWasClosedMeanwhile(channel *BngConnChannel, err error) bool {
+	return errors.Is(err, io.EOF) && channel.isClosed.Get()
+}
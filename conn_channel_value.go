@@ -0,0 +1,123 @@
+package bngsocket
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+
+	"github.com/custodia-cenv/bngsocket-go/transport"
+	"github.com/google/uuid"
+)
+
+// isChannelType gibt an ob es sich um einen Channel handelt, welcher als Parameter oder Rückgabewert übertragen wird.
+func isChannelType(t reflect.Type) bool {
+	return t == reflect.TypeOf((*BngConnChannel)(nil))
+}
+
+// Erzeugt einen neuen Channel, welcher als Parameter oder Rückgabewert an die Gegenseite übergeben werden kann.
+// Schreibvorgänge warten, bis die Gegenseite dem Channel beigetreten ist.
+func _NewChannel(ctx context.Context, s *BngConn) (*BngConnChannel, error) {
+	// Es wird geprüft ob die Verbindung getrennt wurde
+	if connectionIsClosed(s) {
+		return nil, connectionTerminationError(s)
+	}
+
+	// Es wird geprüft ob neue Channel geöffnet werden dürfen
+	if err := acceptsNewOperations(s); err != nil {
+		return nil, err
+	}
+
+	// Die Channel Sitzung wird registriert
+	channel, err := s._RegisterNewChannelSession(strings.ReplaceAll(uuid.NewString(), "-", ""))
+	if err != nil {
+		return nil, fmt.Errorf("bngsocket->_NewChannel[0]: " + err.Error())
+	}
+
+	// Es wird auf den Beitritt der Gegenseite gewartet, wird der Channel vorher geschlossen, endet das Warten ebenfalls
+	channel.joined = make(chan struct{})
+	channel.waitOfPackageACK.Set(true)
+	go func() {
+		defer close(channel.joined)
+		if _, ok := channel.ackChan.Read(); ok {
+			s.logger.Debug("Channel Joined", slog.String(logKeyChannelSession, channel.sesisonId))
+		}
+	}()
+
+	// Der Channel wird geschlossen, sobald der Context abgelaufen ist
+	if ctx.Done() != nil {
+		context.AfterFunc(ctx, func() { channel.processClose(true) })
+	}
+
+	return channel, nil
+}
+
+// checkChannelValues prüft ob die zu übertragenen Channel zur Verbindung gehören und noch geöffnet sind.
+//
+// Parameter:
+//   - s *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - values []interface{}: Die Parameter oder Rückgabewerte.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls ein Channel nicht übertragen werden kann, ansonsten nil.
+func checkChannelValues(s *BngConn, values []interface{}) error {
+	for i, item := range values {
+		channel, ok := item.(*BngConnChannel)
+		if !ok {
+			continue
+		}
+		if channel == nil || channel.socket != s {
+			return fmt.Errorf("checkChannelValues[0]: channel %d does not belong to connection", i)
+		}
+		if channel.isClosed.Get() {
+			return fmt.Errorf("checkChannelValues[1]: channel %d: %w", i, ErrChannelClosed)
+		}
+	}
+	return nil
+}
+
+// resolveChannelValue gibt den Channel eines übertragenen Parameters oder Rückgabewertes zurück.
+// Ist der Channel noch nicht bekannt, wird die Sitzung registriert und der Beitritt der Gegenseite bestätigt.
+//
+// Parameter:
+//   - s *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - capsle *transport.RpcDataCapsle: Der übertragene Wert.
+//   - expectedType reflect.Type: Der erwartete Typ.
+//
+// Rückgabe:
+//   - *BngConnChannel: Der lokale Teil des Channels.
+//   - error: Ein Fehler, falls der Channel nicht übernommen werden konnte, ansonsten nil.
+func resolveChannelValue(s *BngConn, capsle *transport.RpcDataCapsle, expectedType reflect.Type) (*BngConnChannel, error) {
+	// Es muss ein Channel erwartet werden
+	if !isChannelType(expectedType) {
+		return nil, fmt.Errorf("resolveChannelValue[0]: channel transmitted, expected %s", expectedType)
+	}
+
+	// Die ID der Channel Sitzung wird ausgelesen
+	sessionId, ok := capsle.Value.(string)
+	if !ok || sessionId == "" {
+		return nil, fmt.Errorf("resolveChannelValue[1]: invalid channel session id")
+	}
+
+	// Ist der Channel bereits bekannt, wird der vorhandene Channel verwendet,
+	// ansonsten wird die Channel Sitzung registriert, der Connmutex verhindert eine doppelte Registrierung
+	s.connMutex.Lock()
+	if channel, found := s.openChannelInstances.Load(sessionId); found {
+		s.connMutex.Unlock()
+		return channel, nil
+	}
+	channel, err := s._RegisterNewChannelSession(sessionId)
+	s.connMutex.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("resolveChannelValue[2]: " + err.Error())
+	}
+
+	// Der Beitritt wird der Gegenseite bestätigt
+	if err := channelWriteACKForJoin(s, sessionId); err != nil {
+		s._UnregisterChannelSession(sessionId)
+		return nil, fmt.Errorf("resolveChannelValue[3]: " + err.Error())
+	}
+
+	return channel, nil
+}
@@ -0,0 +1,242 @@
+package bngsocket
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"slices"
+	"sync/atomic"
+
+	"github.com/custodia-cenv/bngsocket-go/transport"
+)
+
+// Maximale Größe, welche eine dekomprimierte Nachricht annehmen darf
+const maxDecompressedPayloadSize = 256 << 20
+
+// Komprimierte Nachrichten werden wie Nachrichten eines anderen Codecs in einen Umschlag mit dem Namen
+// des Kompressionsverfahrens gelegt, dadurch werden sie unabhängig von ihrem Inhalt eindeutig erkannt
+var gzipEnvelopeHeader = append([]byte{codecEnvelopeMarker, byte(len(CompressionGzip))}, CompressionGzip...)
+
+// _CompressionCounter zählt die Bytes vor und nach der Kompression.
+type _CompressionCounter struct {
+	messages     atomic.Uint64 // Anzahl der komprimierten Nachrichten
+	rawBytes     atomic.Uint64 // Größe der Nachrichten vor der Kompression
+	encodedBytes atomic.Uint64 // Größe der Nachrichten nach der Kompression
+}
+
+// CompressionStats gibt Auskunft über die erreichte Kompression einer Richtung.
+type CompressionStats struct {
+	Messages          uint64 // Anzahl der komprimierten Nachrichten
+	UncompressedBytes uint64 // Größe der Nachrichten vor der Kompression
+	CompressedBytes   uint64 // Größe der Nachrichten nach der Kompression
+}
+
+// Ratio gibt das Verhältnis zwischen unkomprimierter und komprimierter Größe zurück.
+// Ein Wert von 5 bedeutet, dass die Daten auf ein Fünftel verkleinert wurden.
+func (c CompressionStats) Ratio() float64 {
+	if c.CompressedBytes == 0 {
+		return 0
+	}
+	return float64(c.UncompressedBytes) / float64(c.CompressedBytes)
+}
+
+// add wird verwendet um eine komprimierte Nachricht zu zählen
+func (c *_CompressionCounter) add(rawSize int, encodedSize int) {
+	c.messages.Add(1)
+	c.rawBytes.Add(uint64(rawSize))
+	c.encodedBytes.Add(uint64(encodedSize))
+}
+
+// stats gibt den aktuellen Stand der Zähler zurück
+func (c *_CompressionCounter) stats() CompressionStats {
+	return CompressionStats{
+		Messages:          c.messages.Load(),
+		UncompressedBytes: c.rawBytes.Load(),
+		CompressedBytes:   c.encodedBytes.Load(),
+	}
+}
+
+// CompressionStats gibt die Kompressionszähler der ausgehenden und eingehenden Nachrichten zurück.
+//
+// Rückgabe:
+//   - CompressionStats: Zähler der gesendeten Nachrichten.
+//   - CompressionStats: Zähler der empfangenen Nachrichten.
+func (s *BngConn) CompressionStats() (sent CompressionStats, received CompressionStats) {
+	return s.compressionOut.stats(), s.compressionIn.stats()
+}
+
+// CompressionActive gibt an ob die Kompression mit der Gegenseite ausgehandelt wurde.
+func (s *BngConn) CompressionActive() bool {
+	return s.compressionNegotiated.Get()
+}
+
+// Eingebaute Funktion, über welche die unterstützten Verfahren mit der Gegenseite ausgehandelt werden
+const helloFunctionName = reservedFunctionPrefix + "hello"
+
+// newConnHello erzeugt die Beschreibung der Verfahren, welche die Verbindung anbietet.
+func newConnHello(o *BngConn) *transport.ConnHello {
+	hello := &transport.ConnHello{}
+
+	// Es wird geprüft ob eine Kompression angeboten werden soll
+	if o.config.Compression != CompressionNone {
+		hello.Compression = []string{o.config.Compression}
+	}
+
+	// Es wird geprüft ob ein anderer Codec angeboten werden soll
+	if o.config.Codec != CodecMsgpack {
+		hello.Codecs = []string{o.config.Codec}
+	}
+
+	return hello
+}
+
+// negotiateConnHello handelt die Kompression und den Codec mit der Gegenseite aus.
+// Die Verfahren werden über die eingebaute Funktion _bng.hello ausgetauscht, eine Gegenseite ohne diese Funktion
+// beantwortet den Aufruf mit ErrUnkownRpcFunction, die Verbindung wird dann unverändert (unkomprimiert, msgpack) verwendet.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls der Austausch fehlgeschlagen ist, ansonsten nil.
+func negotiateConnHello(o *BngConn) error {
+	values, err := _CallFunction(context.Background(), o, helloFunctionName, []interface{}{newConnHello(o)}, []reflect.Type{reflect.TypeFor[*transport.ConnHello]()})
+	if err != nil {
+		// Die Gegenseite unterstützt keine Aushandlung
+		if errors.Is(err, ErrUnkownRpcFunction) {
+			o.logger.Debug("Peer does not support negotiation, using defaults")
+			return nil
+		}
+		return fmt.Errorf("negotiateConnHello[0]: " + err.Error())
+	}
+
+	hello, _ := values[0].(*transport.ConnHello)
+	if hello == nil {
+		return nil
+	}
+	return processConnHello(o, hello)
+}
+
+// builtinConnHello übernimmt die von der Gegenseite angebotenen Verfahren und gibt die eigenen Verfahren zurück.
+func builtinConnHello(req *BngRequest, hello *transport.ConnHello) (*transport.ConnHello, error) {
+	if hello != nil {
+		if err := processConnHello(req.Conn, hello); err != nil {
+			return nil, err
+		}
+	}
+	return newConnHello(req.Conn), nil
+}
+
+// processConnHello wertet die von der Gegenseite angebotenen Verfahren aus.
+func processConnHello(o *BngConn, hello *transport.ConnHello) error {
+	// Die Kompression wird nur aktiviert, wenn beide Seiten das selbe Verfahren verwenden
+	if o.config.Compression != CompressionNone && slices.Contains(hello.Compression, o.config.Compression) {
+		o.compressionNegotiated.Set(true)
+
+		// LOG
+		o.logger.Debug("Compression negotiated", slog.String("compression", o.config.Compression))
+	}
+
+	// Der Codec wird nur gewechselt, wenn beide Seiten den selben Codec verwenden
+	if o.config.Codec != CodecMsgpack && slices.Contains(hello.Codecs, o.config.Codec) {
+		codec, found := lookupCodec(o.config.Codec)
+		if !found {
+			return fmt.Errorf("processConnHello[0]: %w", ErrUnsupportedCodec)
+		}
+		o.negotiatedCodec.Set(codec)
+
+		// LOG
+		o.logger.Debug("Codec negotiated", slog.String("codec", o.config.Codec))
+	}
+
+	return nil
+}
+
+// compressOutgoingPayload komprimiert eine ausgehende Nachricht, sofern die Kompression ausgehandelt wurde
+// und die Nachricht den Schwellwert erreicht. Ist die komprimierte Nachricht nicht kleiner, werden die
+// Originaldaten verwendet.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - data []byte: Die zu sendenden Daten.
+//
+// Rückgabe:
+//   - []byte: Die (ggf. komprimierten) Daten.
+//   - error: Ein Fehler, falls beim Komprimieren ein Problem aufgetreten ist, ansonsten nil.
+func compressOutgoingPayload(o *BngConn, data []byte) ([]byte, error) {
+	// Es wird geprüft ob die Nachricht komprimiert werden soll
+	if !o.compressionNegotiated.Get() || len(data) < o.config.CompressionThreshold {
+		return data, nil
+	}
+
+	// Die Daten werden komprimiert, der Umschlag wird vorangestellt
+	var buffer bytes.Buffer
+	buffer.Write(gzipEnvelopeHeader)
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("compressOutgoingPayload[0]: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("compressOutgoingPayload[1]: %w", err)
+	}
+
+	// Sollte die Kompression keinen Vorteil bringen, werden die Originaldaten gesendet
+	if buffer.Len() >= len(data) {
+		return data, nil
+	}
+
+	// Die Zähler werden aktualisiert
+	o.compressionOut.add(len(data), buffer.Len()-len(gzipEnvelopeHeader))
+
+	return buffer.Bytes(), nil
+}
+
+// decompressIncomingPayload dekomprimiert eine eingehende Nachricht, sofern diese komprimiert wurde.
+// Komprimierte Nachrichten werden nur akzeptiert, wenn die Verbindung selbst eine Kompression anbietet,
+// da die Gegenseite nur in diesem Fall eine Kompression aushandeln kann.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - data []byte: Die empfangenen Daten.
+//
+// Rückgabe:
+//   - []byte: Die dekomprimierten Daten.
+//   - error: Ein Fehler, falls beim Dekomprimieren ein Problem aufgetreten ist, ansonsten nil.
+func decompressIncomingPayload(o *BngConn, data []byte) ([]byte, error) {
+	// Es wird geprüft ob es sich um komprimierte Daten handelt
+	if !bytes.HasPrefix(data, gzipEnvelopeHeader) {
+		return data, nil
+	}
+
+	// Ohne angebotene Kompression darf die Gegenseite keine komprimierten Nachrichten senden
+	if o.config.Compression != CompressionGzip {
+		return nil, fmt.Errorf("%w: compression was not negotiated", ErrDecompressPayload)
+	}
+	encodedSize := len(data) - len(gzipEnvelopeHeader)
+
+	// Die Daten werden dekomprimiert
+	reader, err := gzip.NewReader(bytes.NewReader(data[len(gzipEnvelopeHeader):]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecompressPayload, err)
+	}
+	defer reader.Close()
+
+	// Die Größe der dekomprimierten Daten wird begrenzt
+	decompressed, err := io.ReadAll(io.LimitReader(reader, maxDecompressedPayloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecompressPayload, err)
+	}
+	if len(decompressed) > maxDecompressedPayloadSize {
+		return nil, fmt.Errorf("%w: payload too large", ErrDecompressPayload)
+	}
+
+	// Die Zähler werden aktualisiert
+	o.compressionIn.add(len(decompressed), encodedSize)
+
+	return decompressed, nil
+}
@@ -0,0 +1,104 @@
+package bngsocket
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Erzeugt zwei über einen Unix-Socket verbundene BngConn Objekte
+func newTestConnPair(t *testing.T, serverConfig *BngConnConfig, clientConfig *BngConnConfig) (*BngConn, *BngConn) {
+	listener, err := net.Listen("unix", filepath.Join(t.TempDir(), "bng.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, _ := listener.Accept()
+		accepted <- conn
+	}()
+	clientSocket, err := net.Dial("unix", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverSocket := <-accepted
+
+	server, err := UpgradeSocketToBngConnWithConfig(serverSocket, serverConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := UpgradeSocketToBngConnWithConfig(clientSocket, clientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return server, client
+}
+
+func TestNegotiationWithPeerWithoutSupport(t *testing.T) {
+	// Die Gegenseite kennt die eingebaute Funktion nicht, wie eine Gegenseite ohne Aushandlung
+	server, client := newTestConnPair(t, &BngConnConfig{withoutBuiltins: []string{helloFunctionName}}, &BngConnConfig{Compression: CompressionGzip, Codec: CodecJSON})
+	if err := server.RegisterFunction("echo", func(req *BngRequest, value string) (string, error) {
+		return value, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Die Verbindung bleibt bestehen und wird ohne Kompression mit msgpack verwendet
+	time.Sleep(100 * time.Millisecond)
+	values, err := client.CallFunction("echo", []interface{}{"hello"}, []reflect.Type{reflect.TypeFor[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != "hello" {
+		t.Fatalf("unexpected result %v", values[0])
+	}
+	if client.CompressionActive() || client.Codec() != CodecMsgpack || client.State() != StateReady || server.State() != StateReady {
+		t.Fatalf("unexpected connection state: compression=%v codec=%s client=%s server=%s",
+			client.CompressionActive(), client.Codec(), client.State(), server.State())
+	}
+}
+
+func TestDecompressOnlyMarkedPayloads(t *testing.T) {
+	sender := newTestReadingConn(t, nil, &BngConnConfig{Compression: CompressionGzip, CompressionThreshold: 1})
+	sender.compressionNegotiated.Set(true)
+	raw := bytes.Repeat([]byte("compressible payload "), 64)
+	compressed, err := compressOutgoingPayload(sender, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(compressed, gzipEnvelopeHeader) {
+		t.Fatal("compressed payload is not marked")
+	}
+
+	// Eine Verbindung mit Kompression dekomprimiert markierte Nachrichten
+	receiver := newTestReadingConn(t, nil, &BngConnConfig{Compression: CompressionGzip})
+	data, err := decompressIncomingPayload(receiver, compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, raw) {
+		t.Fatal("payload was not restored")
+	}
+
+	// Nachrichten, welche zufällig wie gzip Daten beginnen, bleiben unverändert
+	gzipLike := append([]byte{0x1f, 0x8b}, raw...)
+	if data, err := decompressIncomingPayload(receiver, gzipLike); err != nil || !bytes.Equal(data, gzipLike) {
+		t.Fatalf("unmarked payload was modified: %v", err)
+	}
+
+	// Ohne angebotene Kompression werden komprimierte Nachrichten abgelehnt
+	plain := newTestReadingConn(t, nil, nil)
+	if _, err := decompressIncomingPayload(plain, compressed); !errors.Is(err, ErrDecompressPayload) {
+		t.Fatalf("expected ErrDecompressPayload, got %v", err)
+	}
+}
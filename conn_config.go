@@ -0,0 +1,237 @@
+package bngsocket
+
+import (
+	"log/slog"
+	"maps"
+	"time"
+)
+
+// Standardwerte für die Konfiguration einer BngConn
+const (
+	DefaultCompressionThreshold = 1024     // Ab dieser Größe (in Bytes) werden Nachrichten komprimiert
+	DefaultMaxFrameRetransmits  = 3        // Maximale Anzahl an erneuten Sendeversuchen nach einem NACK
+	DefaultKeepaliveTimeouts    = 3        // Anzahl der Ping Intervalle ohne Pong, nach denen die Gegenseite als tot gilt
+	DefaultMaxStreamFrameSize   = 16 << 20 // Maximale Größe (in Bytes) eines empfangenen Stream Wertes
+	DefaultMaxBatchSize         = 256      // Maximale Anzahl an Aufrufen eines eingehenden Batches
+	DefaultBatchConcurrency     = 16       // Anzahl der Aufrufe eines eingehenden Batches, welche gleichzeitig ausgeführt werden
+)
+
+// Maximale Größe eines Chunks in Bytes
+const frameChunkSize = 1024
+
+// Zeitspanne ohne eintreffende Bytes, nach der die Reste eines beschädigten Frames als verworfen gelten
+const corruptedFrameDrainTimeout = 50 * time.Millisecond
+
+// FrameIntegrityMode legt fest, wie die Integrität der übertragenen Frames geprüft wird.
+type FrameIntegrityMode uint8
+
+const (
+	// Es werden keine Checksummen übertragen ('M'/'E' Frames)
+	FrameIntegrityNone FrameIntegrityMode = iota
+	// Es werden Checksummen übertragen ('m'/'e' Frames), bei einer fehlerhaften Checksumme wird die Verbindung beendet
+	FrameIntegrityVerify
+	// Es werden Checksummen übertragen, bei einer fehlerhaften Checksumme wird der Chunk erneut angefordert (NACK)
+	FrameIntegrityRetransmit
+)
+
+// Unterstützte Kompressionsverfahren. Derzeit steht ausschließlich gzip zur Verfügung,
+// die Kompression wird auf jede ausreichend große Nachricht der Verbindung angewendet.
+const (
+	CompressionNone = ""     // Es wird keine Kompression verwendet
+	CompressionGzip = "gzip" // Die Nachrichten werden mittels gzip (compress/gzip) komprimiert
+)
+
+// BngConnConfig enthält die Einstellungen, mit denen eine BngConn erzeugt wird.
+// Nicht gesetzte Felder werden mit den Standardwerten belegt.
+type BngConnConfig struct {
+	// Kompressionsverfahren, welches für ausgehende Nachrichten verwendet werden soll (CompressionNone oder CompressionGzip).
+	// Die Kompression wird erst aktiv, wenn die Gegenseite das Verfahren ebenfalls angeboten hat, und gilt dann
+	// für alle Nachrichten der Verbindung, einschließlich der Channel Daten.
+	Compression string
+
+	// Nachrichten, welche kleiner als dieser Wert sind, werden unkomprimiert übertragen.
+	CompressionThreshold int
+
+	// Legt fest ob übertragene Parameter und Rückgabewerte exakt dem erwarteten Typ entsprechen müssen.
+	// Ohne Strict Modus werden Zahlen umgewandelt, sofern der Wert verlustfrei in den erwarteten Typ passt.
+	StrictTypes bool
+
+	// Codec, mit dem die Nachrichten und RPC Werte kodiert werden sollen (z.B. CodecCBOR oder CodecJSON).
+	// Der Codec wird erst verwendet, wenn die Gegenseite ihn ebenfalls angeboten hat, bis dahin wird msgpack verwendet.
+	Codec string
+
+	// Legt fest ob die Frames mit einer CRC32 Checksumme übertragen und geprüft werden.
+	// Eingehende Frames mit Checksumme werden unabhängig von dieser Einstellung immer geprüft.
+	FrameIntegrity FrameIntegrityMode
+
+	// Maximale Anzahl an erneuten Sendeversuchen eines Chunks im Modus FrameIntegrityRetransmit.
+	MaxFrameRetransmits int
+
+	// Maximale Größe (in Bytes) eines über einen Stream empfangenen Wertes, größere Werte beenden den Stream
+	// mit ErrFrameTooLarge. Ist der Wert 0, wird DefaultMaxStreamFrameSize verwendet.
+	MaxStreamFrameSize int
+
+	// Abstand, in dem Pings an die Gegenseite gesendet werden. Ist der Wert 0, werden keine Pings gesendet.
+	KeepaliveInterval time.Duration
+
+	// Zeit ohne Pong, nach der die Verbindung mit ErrPeerTimeout beendet wird.
+	// Ist der Wert 0, wird das Dreifache des KeepaliveInterval verwendet.
+	KeepaliveTimeout time.Duration
+
+	// Logger, über welchen die Verbindung ihre Log-Ausgaben schreibt.
+	// Ist der Wert nil, werden keine Log-Ausgaben erzeugt.
+	Logger *slog.Logger
+
+	// Metrics, über welche die Verbindung Kennzahlen über den Datenverkehr erfasst.
+	// Ist der Wert nil, werden keine Kennzahlen erfasst.
+	Metrics Metrics
+
+	// Tracer, über welchen Spans für ausgehende und eingehende RPC Aufrufe erzeugt werden.
+	// Ist der Wert nil, werden keine Spans erzeugt.
+	Tracer Tracer
+
+	// Propagator, über welchen der Trace Context in den Metadaten der RPC Aufrufe übertragen wird.
+	// Ist der Wert nil, wird der W3C Trace Context (TraceContextPropagator) verwendet.
+	Propagator Propagator
+
+	// Interceptoren, welche in dieser Reihenfolge um jeden eingehenden RPC Aufruf gelegt werden.
+	ServerInterceptors []UnaryServerInterceptor
+
+	// Interceptoren, welche in dieser Reihenfolge um jeden ausgehenden RPC Aufruf gelegt werden.
+	ClientInterceptors []UnaryClientInterceptor
+
+	// Wiederholungsrichtlinien für ausgehende RPC Aufrufe, nach dem Namen der Funktion.
+	// Eine mittels ContextWithRetryPolicy gesetzte Richtlinie hat Vorrang.
+	RetryPolicies map[string]*RetryPolicy
+
+	// Wiederholungsrichtlinie für Funktionen ohne Eintrag in RetryPolicies. Ist der Wert nil, wird nicht wiederholt.
+	DefaultRetryPolicy *RetryPolicy
+
+	// Maximale Anzahl an Aufrufen eines eingehenden Batches, größere Batches werden mit ErrBatchTooLarge abgelehnt.
+	// Ist der Wert 0, wird DefaultMaxBatchSize verwendet.
+	MaxBatchSize int
+
+	// Anzahl der Aufrufe eines eingehenden Batches, welche gleichzeitig ausgeführt werden.
+	// Ist der Wert 0, wird DefaultBatchConcurrency verwendet.
+	BatchConcurrency int
+
+	// Dauer, für welche die Antworten eingehender Aufrufe mit Idempotency Key vorgehalten werden.
+	// Ist der Wert 0, wird DefaultIdempotencyTTL verwendet.
+	IdempotencyTTL time.Duration
+
+	// Funktion, welche bei jeder Zustandsänderung der Verbindung aufgerufen wird. Im Gegensatz zu
+	// BngConn.OnStateChange ist sie bereits vor dem Upgrade registriert und erhält daher auch den
+	// Übergang von StateConnecting nach StateReady.
+	OnStateChange func(old, new State, err error)
+
+	// Eingebaute Funktionen, welche auf dieser Verbindung nicht angeboten werden.
+	// Wird in Tests verwendet, um eine Gegenseite ohne diese Funktionen nachzubilden.
+	withoutBuiltins []string
+}
+
+// DefaultBngConnConfig gibt die Standardkonfiguration einer BngConn zurück.
+func DefaultBngConnConfig() *BngConnConfig {
+	return &BngConnConfig{
+		Compression:          CompressionNone,
+		CompressionThreshold: DefaultCompressionThreshold,
+		Codec:                CodecMsgpack,
+		FrameIntegrity:       FrameIntegrityNone,
+		MaxFrameRetransmits:  DefaultMaxFrameRetransmits,
+		MaxStreamFrameSize:   DefaultMaxStreamFrameSize,
+		MaxBatchSize:         DefaultMaxBatchSize,
+		BatchConcurrency:     DefaultBatchConcurrency,
+		IdempotencyTTL:       DefaultIdempotencyTTL,
+	}
+}
+
+// normalizeBngConnConfig erzeugt eine Kopie der Konfiguration und setzt fehlende Werte auf die Standardwerte.
+//
+// Parameter:
+//   - config *BngConnConfig: Die vom Benutzer übergebene Konfiguration, darf nil sein.
+//
+// Rückgabe:
+//   - *BngConnConfig: Eine vollständige Kopie der Konfiguration.
+func normalizeBngConnConfig(config *BngConnConfig) *BngConnConfig {
+	// Sollte keine Konfiguration vorhanden sein, wird die Standardkonfiguration verwendet
+	if config == nil {
+		return DefaultBngConnConfig()
+	}
+
+	// Die Konfiguration wird kopiert, damit spätere Änderungen keine Auswirkungen haben
+	normalized := *config
+
+	// Es wird geprüft ob ein Schwellwert für die Kompression gesetzt wurde
+	if normalized.CompressionThreshold <= 0 {
+		normalized.CompressionThreshold = DefaultCompressionThreshold
+	}
+
+	// Es wird geprüft ob ein Codec gesetzt wurde
+	if normalized.Codec == "" {
+		normalized.Codec = CodecMsgpack
+	}
+
+	// Es wird geprüft ob die Anzahl der Sendeversuche gesetzt wurde
+	if normalized.MaxFrameRetransmits <= 0 {
+		normalized.MaxFrameRetransmits = DefaultMaxFrameRetransmits
+	}
+
+	// Es wird geprüft ob eine maximale Größe für Stream Werte gesetzt wurde
+	if normalized.MaxStreamFrameSize <= 0 {
+		normalized.MaxStreamFrameSize = DefaultMaxStreamFrameSize
+	}
+
+	// Es wird geprüft ob die Grenzen für eingehende Batches gesetzt wurden
+	if normalized.MaxBatchSize <= 0 {
+		normalized.MaxBatchSize = DefaultMaxBatchSize
+	}
+	if normalized.BatchConcurrency <= 0 {
+		normalized.BatchConcurrency = DefaultBatchConcurrency
+	}
+
+	// Die Interceptoren werden kopiert, damit spätere Änderungen an den Slices keine Auswirkungen haben
+	normalized.ServerInterceptors = append([]UnaryServerInterceptor(nil), config.ServerInterceptors...)
+	normalized.ClientInterceptors = append([]UnaryClientInterceptor(nil), config.ClientInterceptors...)
+
+	// Die Wiederholungsrichtlinien werden kopiert, damit spätere Änderungen an der Map keine Auswirkungen haben
+	normalized.RetryPolicies = maps.Clone(config.RetryPolicies)
+
+	// Es wird geprüft ob die Dauer für die Antworten mit Idempotency Key gesetzt wurde
+	if normalized.IdempotencyTTL <= 0 {
+		normalized.IdempotencyTTL = DefaultIdempotencyTTL
+	}
+
+	// Es wird geprüft ob ein Timeout für die Keepalive Pings gesetzt wurde
+	if normalized.KeepaliveInterval > 0 && normalized.KeepaliveTimeout <= 0 {
+		normalized.KeepaliveTimeout = DefaultKeepaliveTimeouts * normalized.KeepaliveInterval
+	}
+
+	return &normalized
+}
+
+// validateBngConnConfig prüft ob die Konfiguration verwendet werden kann.
+func validateBngConnConfig(config *BngConnConfig) error {
+	switch config.Compression {
+	case CompressionNone, CompressionGzip:
+	default:
+		return ErrUnsupportedCompression
+	}
+	// Der Protobuf Codec kann nur proto.Message Werte kodieren und eignet sich nicht für die Nachrichten
+	if _, found := lookupCodec(config.Codec); !found || config.Codec == CodecProtobuf {
+		return ErrUnsupportedCodec
+	}
+	if config.FrameIntegrity > FrameIntegrityRetransmit {
+		return ErrUnsupportedFrameIntegrity
+	}
+	if config.KeepaliveInterval < 0 || config.KeepaliveTimeout < 0 {
+		return ErrInvalidKeepalive
+	}
+	if err := validateRetryPolicy(config.DefaultRetryPolicy); err != nil {
+		return err
+	}
+	for _, policy := range config.RetryPolicies {
+		if err := validateRetryPolicy(policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
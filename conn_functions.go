@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"syscall"
 )
 
@@ -34,7 +35,8 @@ func connIoEOFError(socket *BngConn) {
 	}
 
 	// DEBUG: Verbindung wurde geschlossen
-	_DebugPrint("Connection closed")
+	socket.logger.Info("Connection closed by peer")
+	socket.metrics.ErrorOccurred(MetricsErrorEOF)
 
 	// Alle Channel Listener werden geschlossen
 	for socket.openChannelListener.Count() != 0 {
@@ -67,20 +69,17 @@ func connIoEOFError(socket *BngConn) {
 		if !found {
 			break
 		}
+		socket.metrics.ChannelsChanged(-1)
 
 		// Der Channel wird geschlossen
 		value.Close()
 	}
 
-	// Alle ausgehenden RPC Anfragen werden geschlossen
-	for socket.openRpcRequests.Count() != 0 {
-		// Implementierung fehlt: Hier sollten die ausgehenden RPC Anfragen geschlossen werden
-		// Beispiel:
-		// rpcRequest, found := socket.openRpcRequests.PopFirst()
-		// if found {
-		//     rpcRequest.Close()
-		// }
-	}
+	// Alle ausgehenden RPC Anfragen und wartenden Writer werden freigegeben
+	releaseWaitingOperations(socket)
+
+	// Der Zustand der Verbindung wird geändert
+	setConnState(socket, StateClosed, nil)
 }
 
 // readProcessErrorHandling wird verwendet, um beim Lesvorgang auf Fehler zu reagieren.
@@ -178,9 +177,10 @@ func connectionIsClosed(ipcc *BngConn) bool {
 // fullCloseConn wird verwendet, um den Socket vollständig zu schließen.
 // Diese Funktion sorgt dafür, dass die Verbindung ordnungsgemäß geschlossen wird, indem sie
 // verschiedene Schritte durchführt, um sicherzustellen, dass alle Ressourcen freigegeben werden.
-// Sie markiert die Verbindung als geschlossen, schließt den Socket, wartet auf den Abschluss aller
-// Hintergrundprozesse und setzt das geschlossene Flag. Bei Fehlern während des Schließvorgangs
-// wird der Fehler zurückgegeben.
+// Sie markiert die Verbindung als geschlossen, schließt den Socket und setzt das geschlossene Flag.
+// Auf den Abschluss der Hintergrundprozesse wird nicht gewartet, da die Funktion auch innerhalb einer
+// aufgerufenen Funktion verwendet werden kann, dieser wird über Done signalisiert. Bei Fehlern während
+// des Schließvorgangs wird der Fehler zurückgegeben.
 //
 // Parameter:
 //   - s *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung und zugehörige
@@ -194,7 +194,7 @@ func fullCloseConn(s *BngConn) error {
 	}
 
 	// DEBUG
-	defer _DebugPrint("Connection closed")
+	defer s.logger.Info("Connection closed")
 
 	// Es wird Markiert dass der Socket geschlossen ist
 	s.closing.Set(true)
@@ -205,17 +205,18 @@ func fullCloseConn(s *BngConn) error {
 	s.connMutex.Unlock()
 
 	// LOG
-	_DebugPrint(fmt.Sprintf("BngConn(%s): CLOSE FULL", s._innerhid))
+	s.logger.Debug("CLOSE FULL")
 
-	// Es wird gewartet dass alle Hintergrundaufgaben abgeschlossen werden
-	s.backgroundProcesses.Wait()
+	// Alle Vorgänge welche auf die Gegenseite warten werden freigegeben
+	releaseWaitingOperations(s)
 
-	// Es wird Signalisiert, dass die Verbindung final geschlossen wurde
+	// Es wird Signalisiert, dass die Verbindung final geschlossen wurde, Hintergrundaufgaben (z.B. die Keepalive
+	// Pings) werden dadurch beendet, Done wird nach dem Abschluss aller Hintergrundaufgaben geschlossen
 	s.closed.Set(true)
+	setConnState(s, StateClosed, nil)
 
 	// Sollte ein Fehler vorhanden sein, wird dieser Zurückgegeben
 	if closeerr != nil {
-		fmt.Println("AA: " + closeerr.Error())
 		return closeerr
 	}
 
@@ -231,7 +232,8 @@ func consensusProtocolTermination(o *BngConn, reason error) {
 	}
 
 	// LOG
-	_DebugPrint(fmt.Sprintf("BngConn(%s): ERROR %s", o._innerhid, reason.Error()))
+	o.logger.Error("Connection terminated", slog.Any(logKeyError, reason))
+	o.metrics.ErrorOccurred(metricsErrorKind(reason))
 
 	// Der connMutextex wird angewenet
 	o.connMutex.Lock()
@@ -249,4 +251,10 @@ func consensusProtocolTermination(o *BngConn, reason error) {
 
 	// Die Socket Verbindung wird geschlossen
 	o.conn.Close()
+
+	// Alle Vorgänge welche auf die Gegenseite warten werden freigegeben
+	releaseWaitingOperations(o)
+
+	// Der Zustand der Verbindung wird geändert
+	setConnState(o, StateFailed, o.runningError.Get())
 }
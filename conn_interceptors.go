@@ -0,0 +1,120 @@
+package bngsocket
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// UnaryHandler führt die registrierte RPC Funktion mit den übergebenen Argumenten aus.
+// Die Argumente enthalten nicht den *BngRequest, die Rückgabewerte enthalten nicht den abschließenden Fehler.
+type UnaryHandler func(req *BngRequest, args []interface{}) ([]interface{}, error)
+
+// UnaryServerInterceptor wird um jeden eingehenden RPC Aufruf gelegt.
+// Der Interceptor erhält den Namen der aufgerufenen Funktion sowie die bereits dekodierten Argumente und
+// entscheidet, ob und mit welchen Argumenten handler aufgerufen wird. Ein zurückgegebener Fehler wird
+// an den Aufrufer übertragen.
+type UnaryServerInterceptor func(req *BngRequest, method string, args []interface{}, handler UnaryHandler) ([]interface{}, error)
+
+// UnaryInvoker führt einen ausgehenden RPC Aufruf aus.
+type UnaryInvoker func(ctx context.Context, conn *BngConn, method string, params []interface{}, returnDataType []reflect.Type) ([]interface{}, error)
+
+// UnaryClientInterceptor wird um jeden ausgehenden RPC Aufruf gelegt.
+// Der Interceptor entscheidet, ob und wie oft invoker aufgerufen wird.
+type UnaryClientInterceptor func(ctx context.Context, conn *BngConn, method string, params []interface{}, returnDataType []reflect.Type, invoker UnaryInvoker) ([]interface{}, error)
+
+// chainUnaryServerInterceptors verbindet die Interceptoren zu einem Handler.
+// Der erste Interceptor wird als äußerster aufgerufen.
+//
+// Parameter:
+//   - interceptors []UnaryServerInterceptor: Die Interceptoren in der Reihenfolge ihres Aufrufs.
+//   - method string: Der Name der aufgerufenen Funktion.
+//   - handler UnaryHandler: Der Handler, welcher die eigentliche Funktion ausführt.
+//
+// Rückgabe:
+//   - UnaryHandler: Der Handler, welcher alle Interceptoren durchläuft.
+func chainUnaryServerInterceptors(interceptors []UnaryServerInterceptor, method string, handler UnaryHandler) UnaryHandler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(req *BngRequest, args []interface{}) ([]interface{}, error) {
+			return interceptor(req, method, args, next)
+		}
+	}
+	return handler
+}
+
+// chainUnaryClientInterceptors verbindet die Interceptoren zu einem Invoker.
+// Der erste Interceptor wird als äußerster aufgerufen.
+//
+// Parameter:
+//   - interceptors []UnaryClientInterceptor: Die Interceptoren in der Reihenfolge ihres Aufrufs.
+//   - invoker UnaryInvoker: Der Invoker, welcher den eigentlichen Aufruf durchführt.
+//
+// Rückgabe:
+//   - UnaryInvoker: Der Invoker, welcher alle Interceptoren durchläuft.
+func chainUnaryClientInterceptors(interceptors []UnaryClientInterceptor, invoker UnaryInvoker) UnaryInvoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, conn *BngConn, method string, params []interface{}, returnDataType []reflect.Type) ([]interface{}, error) {
+			return interceptor(ctx, conn, method, params, returnDataType, next)
+		}
+	}
+	return invoker
+}
+
+// newReflectUnaryHandler erzeugt einen Handler, welcher die registrierte Funktion mittels Reflection aufruft.
+//
+// Parameter:
+//   - fn reflect.Value: Die registrierte Funktion.
+//
+// Rückgabe:
+//   - UnaryHandler: Der Handler, welcher die Funktion aufruft.
+func newReflectUnaryHandler(fn reflect.Value) UnaryHandler {
+	return func(req *BngRequest, args []interface{}) ([]interface{}, error) {
+		fnType := fn.Type()
+
+		// Es wird geprüft ob die Anzahl der Argumente zur Funktion passt, ein Interceptor kann die Argumente verändert haben
+		if len(args)+1 != fnType.NumIn() {
+			return nil, fmt.Errorf("%w: function wants %d arguments, have %d", ErrInvalidParameter, fnType.NumIn()-1, len(args))
+		}
+
+		// Die Argumente werden in reflect.Values umgewandelt, der Typ muss zum jeweiligen Parameter passen
+		in := make([]reflect.Value, len(args)+1)
+		in[0] = reflect.ValueOf(req)
+		for i, arg := range args {
+			paramType := fnType.In(i + 1)
+			if arg == nil {
+				in[i+1] = reflect.Zero(paramType)
+				continue
+			}
+			value := reflect.ValueOf(arg)
+			if !value.Type().AssignableTo(paramType) {
+				return nil, fmt.Errorf("%w: parameter %d: %s given, expected %s", ErrInvalidParameter, i, value.Type(), paramType)
+			}
+			in[i+1] = value
+		}
+
+		// Die Funktion wird mittels Reflection aufgerufen
+		results := callRpcFunctionValue(fn, in)
+
+		// Es muss mindestens 1 Eintrag vorhanden sein
+		if len(results) < 1 {
+			return nil, fmt.Errorf("bngsocket->UnaryHandler: return need more the zero values")
+		}
+
+		// Der Letzte Eintrag muss ein Error sein
+		var callErr error
+		lasteElementOnResultsArray := results[len(results)-1]
+		if lasteElementOnResultsArray.Type().Implements(reflect.TypeOf((*error)(nil)).Elem()) && !lasteElementOnResultsArray.IsNil() {
+			callErr = lasteElementOnResultsArray.Interface().(error)
+		}
+
+		// Die Rückgabewerte werden nacheinander abgearbeitet, der Letzte Eintrag wird ausgelassen
+		values := make([]interface{}, 0, len(results)-1)
+		for i := range len(results) - 1 {
+			values = append(values, results[i].Interface())
+		}
+
+		return values, callErr
+	}
+}
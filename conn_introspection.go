@@ -0,0 +1,143 @@
+package bngsocket
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	// Funktionsnamen mit diesem Präfix sind für eingebaute Funktionen reserviert
+	reservedFunctionPrefix = "_bng."
+
+	// Eingebaute Funktion, welche die bereitgestellten Funktionen und Channel Listener beschreibt
+	listFunctionName = reservedFunctionPrefix + "list"
+)
+
+// Speichert alle eingebauten Funktionen, diese stehen auf jeder Verbindung zur Verfügung
+var builtinRpcFunctions = map[string]reflect.Value{
+	listFunctionName:    reflect.ValueOf(builtinListFunctions),
+	releaseFunctionName: reflect.ValueOf(builtinReleaseCallback),
+	helloFunctionName:   reflect.ValueOf(builtinConnHello),
+}
+
+// isReservedFunctionName gibt an ob der Name für eingebaute Funktionen reserviert ist.
+func isReservedFunctionName(name string) bool {
+	return strings.HasPrefix(name, reservedFunctionPrefix)
+}
+
+// loadRpcFunction gibt die Funktion zurück, welche unter dem Namen aufgerufen werden kann.
+// Reservierte Namen werden ausschließlich über die eingebauten Funktionen und Callbacks aufgelöst.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - name string: Der aufgerufene Funktionsname.
+//
+// Rückgabe:
+//   - reflect.Value: Die gefundene Funktion.
+//   - bool: Gibt an ob eine Funktion gefunden wurde.
+func loadRpcFunction(o *BngConn, name string) (reflect.Value, bool) {
+	if strings.HasPrefix(name, callbackFunctionPrefix) {
+		return o.hiddenFunctions.Load(name)
+	}
+	if isReservedFunctionName(name) {
+		if slices.Contains(o.config.withoutBuiltins, name) {
+			return reflect.Value{}, false
+		}
+		fn, found := builtinRpcFunctions[name]
+		return fn, found
+	}
+	return o.functions.Load(name)
+}
+
+// builtinListFunctions beschreibt alle registrierten Funktionen und geöffneten Channel Listener der Verbindung.
+func builtinListFunctions(req *BngRequest) (*BngServiceDescription, error) {
+	return describeBngConn(req.Conn), nil
+}
+
+// describeBngConn erzeugt die Beschreibung der Funktionen und Channel Listener einer Verbindung.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//
+// Rückgabe:
+//   - *BngServiceDescription: Die nach Namen sortierte Beschreibung.
+func describeBngConn(o *BngConn) *BngServiceDescription {
+	description := &BngServiceDescription{
+		Functions:        make([]*BngFunctionDescription, 0),
+		ChannelListeners: make([]string, 0),
+	}
+
+	// Es werden alle registrierten Funktionen beschrieben
+	o.functions.Range(func(key, value any) bool {
+		description.Functions = append(description.Functions, describeRpcFunction(key.(string), value.(reflect.Value).Type()))
+		return true
+	})
+
+	// Es werden alle geöffneten Channel Listener aufgelistet
+	o.openChannelListener.Range(func(key, value any) bool {
+		description.ChannelListeners = append(description.ChannelListeners, key.(string))
+		return true
+	})
+
+	// Die Einträge werden sortiert, damit die Beschreibung unabhängig von der Map Reihenfolge ist
+	sort.Slice(description.Functions, func(i, j int) bool {
+		return description.Functions[i].Name < description.Functions[j].Name
+	})
+	sort.Strings(description.ChannelListeners)
+
+	return description
+}
+
+// describeRpcFunction beschreibt die Signatur einer registrierten Funktion.
+// Der *BngRequest, der *BngStream sowie der abschließende error sind nicht enthalten.
+func describeRpcFunction(name string, fnType reflect.Type) *BngFunctionDescription {
+	description := &BngFunctionDescription{
+		Name:    name,
+		Params:  make([]string, 0),
+		Returns: make([]string, 0),
+		Stream:  isStreamFunction(fnType),
+	}
+
+	// Die Parameter werden ohne den *BngRequest (und *BngStream) übernommen
+	beginAt := 1
+	if description.Stream {
+		beginAt = 2
+	}
+	for i := beginAt; i < fnType.NumIn(); i++ {
+		description.Params = append(description.Params, fnType.In(i).String())
+	}
+
+	// Die Rückgabewerte werden ohne den abschließenden Fehler übernommen
+	for i := 0; i < fnType.NumOut(); i++ {
+		if i == fnType.NumOut()-1 && isErrorType(fnType.Out(i)) {
+			break
+		}
+		description.Returns = append(description.Returns, fnType.Out(i).String())
+	}
+
+	return description
+}
+
+// Ruft die Beschreibung der Funktionen und Channel Listener der Gegenseite ab
+func _ListRemoteFunctions(ctx context.Context, s *BngConn) (*BngServiceDescription, error) {
+	// Die eingebaute Funktion wird auf der Gegenseite aufgerufen
+	values, err := s.CallFunctionContext(ctx, listFunctionName, nil, []reflect.Type{reflect.TypeFor[*BngServiceDescription]()})
+	if err != nil {
+		return nil, fmt.Errorf("bngsocket->_ListRemoteFunctions[0]: %w", err)
+	}
+
+	// Es muss genau eine Beschreibung zurückgegeben werden
+	if len(values) != 1 {
+		return nil, fmt.Errorf("bngsocket->_ListRemoteFunctions[1]: invalid response")
+	}
+	description, ok := values[0].(*BngServiceDescription)
+	if !ok || description == nil {
+		return nil, fmt.Errorf("bngsocket->_ListRemoteFunctions[2]: invalid response")
+	}
+
+	return description, nil
+}
@@ -0,0 +1,232 @@
+package bngsocket
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// RTT gibt die zuletzt gemessene Round-Trip-Time zur Gegenseite zurück.
+// Solange noch keine Messung erfolgt ist (oder keine Keepalive Pings aktiviert sind), wird 0 zurückgegeben.
+func (s *BngConn) RTT() time.Duration {
+	return time.Duration(s.keepalive.rtt.Load())
+}
+
+// LastPong gibt den Zeitpunkt zurück, zu dem zuletzt ein Pong der Gegenseite empfangen wurde.
+func (s *BngConn) LastPong() time.Time {
+	unixNano := s.keepalive.lastPong.Load()
+	if unixNano == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, unixNano)
+}
+
+// constantKeepalive sendet in regelmäßigen Abständen einen Ping ('P') an die Gegenseite
+// und prüft ob die Pongs ('O') weiterhin eintreffen. Bleiben die Pongs länger als das
+// konfigurierte Timeout aus, wird die Verbindung mit ErrPeerTimeout beendet.
+//
+// Die Routine wird beendet, sobald die Verbindung geschlossen wurde.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+func constantKeepalive(o *BngConn) {
+	defer func() {
+		o.logger.Debug("Keepalive was stopped")
+		o.backgroundProcesses.Done()
+	}()
+
+	o.logger.Debug("Keepalive was started")
+
+	// Der Startzeitpunkt gilt als erster Lebensnachweis der Gegenseite
+	o.keepalive.lastPong.Store(time.Now().UnixNano())
+
+	ticker := time.NewTicker(o.config.KeepaliveInterval)
+	defer ticker.Stop()
+
+	for runningBackgroundServingLoop(o) {
+		select {
+		case <-connEnded(o):
+			return
+		case <-ticker.C:
+		}
+
+		// Es wird geprüft ob die Verbindung inzwischen geschlossen wurde
+		if !runningBackgroundServingLoop(o) {
+			return
+		}
+
+		// Es wird geprüft ob die Gegenseite noch antwortet
+		if time.Since(o.LastPong()) > o.config.KeepaliveTimeout {
+			consensusProtocolTermination(o, fmt.Errorf("bngsocket->constantKeepalive: %w", ErrPeerTimeout))
+			return
+		}
+
+		// Es wird ein neuer Ping gesendet
+		if err := writePing(o); err != nil {
+			writeProcessErrorHandling(o, err)
+			return
+		}
+	}
+}
+
+// writePing sendet einen Ping ('P') mit einer fortlaufenden Nummer an die Gegenseite.
+// Der Sendezeitpunkt wird gespeichert, um beim Eintreffen des Pongs die RTT zu berechnen.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls beim Senden des Pings ein Problem aufgetreten ist, ansonsten nil.
+func writePing(o *BngConn) error {
+	o.writerMutex.Lock()
+	defer o.writerMutex.Unlock()
+
+	// Die Nummer und der Zeitpunkt des Pings werden gespeichert
+	nonce := o.keepalive.nonce.Add(1)
+	o.keepalive.pingSentAt.Store(time.Now().UnixNano())
+	o.keepalive.pingNonce.Store(nonce)
+
+	// Schreibe den Ping
+	if err := o.writer.WriteByte('P'); err != nil {
+		return fmt.Errorf("%w: %v", ErrWritePing, err)
+	}
+	if err := binary.Write(o.writer, binary.BigEndian, nonce); err != nil {
+		return fmt.Errorf("%w: %v", ErrWritePing, err)
+	}
+
+	// Flushe den Writer
+	if err := o.writer.Flush(); err != nil {
+		return fmt.Errorf("%w: %v", ErrFlushWriter, err)
+	}
+
+	o.metrics.FrameSent('P', 9)
+	o.logger.Debug("PING sent", logFrame('P'), slog.Uint64("nonce", nonce))
+	return nil
+}
+
+// writePong beantwortet einen Ping der Gegenseite mit einem Pong ('O'), welcher die Nummer des Pings enthält.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - nonce uint64: Die Nummer des empfangenen Pings.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls beim Senden des Pongs ein Problem aufgetreten ist, ansonsten nil.
+func writePong(o *BngConn, nonce uint64) error {
+	o.writerMutex.Lock()
+	defer o.writerMutex.Unlock()
+
+	// Schreibe den Pong
+	if err := o.writer.WriteByte('O'); err != nil {
+		return fmt.Errorf("%w: %v", ErrWritePong, err)
+	}
+	if err := binary.Write(o.writer, binary.BigEndian, nonce); err != nil {
+		return fmt.Errorf("%w: %v", ErrWritePong, err)
+	}
+
+	// Flushe den Writer
+	if err := o.writer.Flush(); err != nil {
+		return fmt.Errorf("%w: %v", ErrFlushWriter, err)
+	}
+
+	o.metrics.FrameSent('O', 9)
+	o.logger.Debug("PONG sent", logFrame('O'), slog.Uint64("nonce", nonce))
+	return nil
+}
+
+// handlePing liest einen eingehenden Ping und beantwortet diesen mit einem Pong.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls beim Lesen oder Beantworten ein Problem aufgetreten ist, ansonsten nil.
+func handlePing(o *BngConn) error {
+	var nonce uint64
+	if err := binary.Read(o.reader, binary.BigEndian, &nonce); err != nil {
+		return fmt.Errorf("%s: %w: %v", o._innerhid, ErrReadPing, err)
+	}
+	o.metrics.FrameReceived('P', 9)
+
+	return writePong(o, nonce)
+}
+
+// handlePong liest einen eingehenden Pong, aktualisiert den Lebensnachweis der Gegenseite
+// und berechnet die RTT, sofern der Pong zum zuletzt gesendeten Ping gehört.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls beim Lesen ein Problem aufgetreten ist, ansonsten nil.
+func handlePong(o *BngConn) error {
+	var nonce uint64
+	if err := binary.Read(o.reader, binary.BigEndian, &nonce); err != nil {
+		return fmt.Errorf("%s: %w: %v", o._innerhid, ErrReadPing, err)
+	}
+	o.metrics.FrameReceived('O', 9)
+
+	// Der Lebensnachweis wird aktualisiert
+	now := time.Now()
+	o.keepalive.lastPong.Store(now.UnixNano())
+
+	// Die RTT wird nur für den zuletzt gesendeten Ping berechnet
+	if nonce == o.keepalive.pingNonce.Load() {
+		rtt := now.Sub(time.Unix(0, o.keepalive.pingSentAt.Load()))
+		o.keepalive.rtt.Store(int64(rtt))
+		o.logger.Debug("PONG received", logFrame('O'), slog.Uint64("nonce", nonce), slog.Duration("rtt", rtt))
+	}
+
+	return nil
+}
+
+// releaseWaitingOperations gibt alle Vorgänge frei, welche auf eine Antwort der Gegenseite warten.
+// Wartende Writer erhalten einen Fehler beim Warten auf das ACK, offene RPC Anfragen werden verworfen.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+func releaseWaitingOperations(o *BngConn) {
+	// Alle Writer welche auf ein ACK warten werden freigegeben
+	o.ackHandle.Close()
+
+	// Alle ausgehenden RPC Anfragen werden verworfen
+	for o.openRpcRequests.Count() != 0 {
+		// Das erste Item wird extrahiert
+		responseChan, found := o.openRpcRequests.PopFirst()
+		if !found {
+			break
+		}
+
+		// Der Aufrufer wird freigegeben
+		close(responseChan)
+	}
+
+	// Alle ausgehenden RPC Batches werden verworfen
+	for o.openRpcBatches.Count() != 0 {
+		responseChan, found := o.openRpcBatches.PopFirst()
+		if !found {
+			break
+		}
+		close(responseChan)
+	}
+
+	// Alle offenen Abonnement-Anfragen werden verworfen
+	for o.openSubscribeRequests.Count() != 0 {
+		ackChan, found := o.openSubscribeRequests.PopFirst()
+		if !found {
+			break
+		}
+		close(ackChan)
+	}
+}
+
+// connectionTerminationError gibt den Fehler zurück, mit dem die Verbindung beendet wurde.
+// Wurde die Verbindung ordnungsgemäß geschlossen, wird io.EOF zurückgegeben.
+func connectionTerminationError(o *BngConn) error {
+	if err := o.runningError.Get(); err != nil && err != io.EOF {
+		return err
+	}
+	return io.EOF
+}
@@ -0,0 +1,48 @@
+package bngsocket
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Schlüssel der Attribute, welche in den Log-Ausgaben einer BngConn verwendet werden
+const (
+	logKeyConn           = "conn"            // Interne ID der Verbindung
+	logKeyChannelSession = "channel_session" // Session-ID eines Channels
+	logKeyRpcId          = "rpc_id"          // ID eines RPC Aufrufs
+	logKeyFrame          = "frame"           // Typ des Frames ('M', 'E', 'A', 'P', ...)
+	logKeyError          = "error"           // Aufgetretener Fehler
+)
+
+// _DiscardLogHandler verwirft alle Log-Ausgaben, er wird verwendet wenn kein Logger konfiguriert wurde.
+type _DiscardLogHandler struct{}
+
+func (_DiscardLogHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (_DiscardLogHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h _DiscardLogHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h _DiscardLogHandler) WithGroup(string) slog.Handler           { return h }
+
+// Logger gibt den Logger der Verbindung zurück, jeder Eintrag enthält die ID der Verbindung.
+func (s *BngConn) Logger() *slog.Logger {
+	return s.logger
+}
+
+// newConnLogger erzeugt den Logger einer Verbindung.
+//
+// Parameter:
+//   - logger *slog.Logger: Der konfigurierte Logger, ist dieser nil, werden alle Ausgaben verworfen.
+//   - connId string: Die interne ID der Verbindung, welche jedem Eintrag angehängt wird.
+//
+// Rückgabe:
+//   - *slog.Logger: Der Logger der Verbindung.
+func newConnLogger(logger *slog.Logger, connId string) *slog.Logger {
+	if logger == nil {
+		return slog.New(_DiscardLogHandler{})
+	}
+	return logger.With(slog.String(logKeyConn, connId))
+}
+
+// logFrame gibt ein Log-Attribut mit dem Typ eines Frames zurück.
+func logFrame(frameType byte) slog.Attr {
+	return slog.String(logKeyFrame, string(frameType))
+}
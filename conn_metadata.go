@@ -0,0 +1,83 @@
+package bngsocket
+
+import (
+	"context"
+	"maps"
+	"time"
+)
+
+// Schlüssel, unter welchen die Metadaten eines ausgehenden Aufrufs im Context gespeichert werden
+type outgoingMetadataKey struct{}
+type trailerCaptureKey struct{}
+
+// ContextWithMetadata gibt einen Context zurück, dessen Metadaten bei einem Aufruf von CallFunctionContext
+// an die Gegenseite übertragen werden. Bereits vorhandene Metadaten werden ergänzt bzw. überschrieben.
+func ContextWithMetadata(ctx context.Context, metadata map[string]string) context.Context {
+	merged := OutgoingMetadataFromContext(ctx)
+	maps.Copy(merged, metadata)
+	return context.WithValue(ctx, outgoingMetadataKey{}, merged)
+}
+
+// OutgoingMetadataFromContext gibt eine Kopie der mittels ContextWithMetadata gesetzten Metadaten zurück.
+func OutgoingMetadataFromContext(ctx context.Context) map[string]string {
+	metadata := make(map[string]string)
+	if existing, ok := ctx.Value(outgoingMetadataKey{}).(map[string]string); ok {
+		maps.Copy(metadata, existing)
+	}
+	return metadata
+}
+
+// ContextWithTrailer gibt einen Context zurück, bei dem der Trailer der Antwort eines Aufrufs von
+// CallFunctionContext in die übergebene Map geschrieben wird.
+func ContextWithTrailer(ctx context.Context, trailer map[string]string) context.Context {
+	return context.WithValue(ctx, trailerCaptureKey{}, trailer)
+}
+
+// captureRpcTrailer schreibt den Trailer einer Antwort in die mittels ContextWithTrailer übergebene Map.
+func captureRpcTrailer(ctx context.Context, trailer map[string]string) {
+	if target, ok := ctx.Value(trailerCaptureKey{}).(map[string]string); ok && target != nil {
+		maps.Copy(target, trailer)
+	}
+}
+
+// rpcTimeout gibt die bis zur Deadline des Contexts verbleibende Zeit in Nanosekunden zurück, ist keine vorhanden wird 0 zurückgegeben.
+// Es wird die verbleibende Zeit anstelle des Zeitpunkts übertragen, damit abweichende Uhren der Gegenseite keine Auswirkungen haben.
+func rpcTimeout(ctx context.Context) int64 {
+	if deadline, ok := ctx.Deadline(); ok {
+		return max(int64(time.Until(deadline)), 1)
+	}
+	return 0
+}
+
+// newRpcRequestContext erzeugt den Context eines eingehenden RPC Aufrufs.
+// Der Context wird abgebrochen, sobald die verbleibende Zeit des Aufrufers abgelaufen ist oder die Verbindung beendet wurde.
+// Die Deadline wird anhand der eigenen Uhr ab dem Empfang berechnet.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - parent context.Context: Der Context, welcher den Trace Context des Aufrufers enthält.
+//   - timeout int64: Die verbleibende Zeit des Aufrufers in Nanosekunden, 0 wenn keine Deadline vorhanden ist.
+//
+// Rückgabe:
+//   - context.Context: Der Context des Aufrufs.
+//   - context.CancelFunc: Gibt die Ressourcen des Contexts frei, muss nach dem Aufruf ausgeführt werden.
+func newRpcRequestContext(o *BngConn, parent context.Context, timeout int64) (context.Context, context.CancelFunc) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, time.Duration(timeout))
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
+
+	// Wird die Verbindung beendet, wird der Context abgebrochen
+	go func() {
+		select {
+		case <-connEnded(o):
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
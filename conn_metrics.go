@@ -0,0 +1,93 @@
+package bngsocket
+
+import (
+	"errors"
+	"io"
+	"syscall"
+	"time"
+)
+
+// Metrics wird von einer BngConn aufgerufen, um Kennzahlen über den Datenverkehr zu erfassen.
+// Die Methoden werden aus verschiedenen Routinen gleichzeitig aufgerufen und dürfen nicht blockieren.
+// Eine Implementierung, welche die Kennzahlen im Prometheus Textformat bereitstellt, befindet sich im
+// Paket github.com/custodia-cenv/bngsocket-go/promexport.
+type Metrics interface {
+	// FrameSent wird nach dem Senden eines Frames aufgerufen, size enthält die Anzahl der geschriebenen Bytes.
+	FrameSent(frameType byte, size int)
+	// FrameReceived wird nach dem Empfangen eines Frames aufgerufen, size enthält die Anzahl der gelesenen Bytes.
+	FrameReceived(frameType byte, size int)
+	// AckWaited wird aufgerufen, nachdem auf das ACK der Gegenseite gewartet wurde.
+	AckWaited(d time.Duration)
+	// RpcCallFinished wird aufgerufen, wenn ein ausgehender RPC Aufruf abgeschlossen wurde.
+	RpcCallFinished(function string, d time.Duration, err error)
+	// RpcRequestServed wird aufgerufen, wenn ein eingehender RPC Aufruf ausgeführt wurde.
+	RpcRequestServed(function string, d time.Duration, err error)
+	// ErrorOccurred wird aufgerufen, wenn ein Fehler auf der Verbindung aufgetreten ist.
+	ErrorOccurred(kind string)
+	// ChannelsChanged wird aufgerufen, wenn ein Channel geöffnet (+1) oder geschlossen (-1) wurde.
+	ChannelsChanged(delta int)
+	// ListenersChanged wird aufgerufen, wenn ein Channel Listener geöffnet (+1) oder geschlossen (-1) wurde.
+	ListenersChanged(delta int)
+}
+
+// Arten von Fehlern, welche an Metrics.ErrorOccurred übergeben werden
+const (
+	MetricsErrorEOF       = "eof"       // Die Gegenseite hat die Verbindung geschlossen
+	MetricsErrorConnReset = "connreset" // Die Verbindung wurde zurückgesetzt
+	MetricsErrorPipe      = "epipe"     // Es wurde in eine geschlossene Verbindung geschrieben
+	MetricsErrorChecksum  = "checksum"  // Ein Frame hatte eine fehlerhafte Checksumme
+	MetricsErrorTimeout   = "timeout"   // Die Gegenseite hat nicht auf Keepalive Pings geantwortet
+	MetricsErrorProtocol  = "protocol"  // Sonstiger Protokollfehler
+)
+
+// _NoopMetrics verwirft alle Kennzahlen, es wird verwendet wenn keine Metrics konfiguriert wurden.
+type _NoopMetrics struct{}
+
+func (_NoopMetrics) FrameSent(byte, int)                           {}
+func (_NoopMetrics) FrameReceived(byte, int)                       {}
+func (_NoopMetrics) AckWaited(time.Duration)                       {}
+func (_NoopMetrics) RpcCallFinished(string, time.Duration, error)  {}
+func (_NoopMetrics) RpcRequestServed(string, time.Duration, error) {}
+func (_NoopMetrics) ErrorOccurred(string)                          {}
+func (_NoopMetrics) ChannelsChanged(int)                           {}
+func (_NoopMetrics) ListenersChanged(int)                          {}
+
+// newConnMetrics gibt die Metrics einer Verbindung zurück, ist keine Implementierung vorhanden, werden die Kennzahlen verworfen.
+func newConnMetrics(metrics Metrics) Metrics {
+	if metrics == nil {
+		return _NoopMetrics{}
+	}
+	return metrics
+}
+
+// metricsErrorKind ordnet einen Fehler einer Fehlerart zu.
+//
+// Parameter:
+//   - err error: Der aufgetretene Fehler.
+//
+// Rückgabe:
+//   - string: Die Fehlerart (MetricsError*).
+func metricsErrorKind(err error) string {
+	switch {
+	case errors.Is(err, io.EOF):
+		return MetricsErrorEOF
+	case errors.Is(err, syscall.ECONNRESET):
+		return MetricsErrorConnReset
+	case errors.Is(err, syscall.EPIPE):
+		return MetricsErrorPipe
+	case errors.Is(err, ErrChecksumMismatch), errors.Is(err, ErrFrameNACK):
+		return MetricsErrorChecksum
+	case errors.Is(err, ErrPeerTimeout):
+		return MetricsErrorTimeout
+	default:
+		return MetricsErrorProtocol
+	}
+}
+
+// waitForFrameACK wartet auf das ACK der Gegenseite und erfasst die Wartezeit.
+func waitForFrameACK(o *BngConn) error {
+	start := time.Now()
+	err := o.ackHandle.WaitOfACK()
+	o.metrics.AckWaited(time.Since(start))
+	return err
+}
@@ -0,0 +1,103 @@
+package bngsocket
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go/transport"
+)
+
+// Wird verwendet um eingehende RPC Notifications zu verarbeiten.
+// Es wird keine Antwort gesendet, Fehler werden ausschließlich protokolliert.
+func processRpcNotification(o *BngConn, notification *transport.RpcNotification) {
+	// Wird die Verbindung heruntergefahren, werden keine neuen Aufrufe mehr angenommen
+	if o.draining.Get() {
+		o.logger.Debug("Discard rpc notification, connection is draining", slog.String("function", notification.Name))
+		return
+	}
+
+	// Es wird geprüft ob die gesuchte Zielfunktion vorhanden ist, Stream Funktionen können nicht benachrichtigt werden
+	fn, found := loadRpcFunction(o, notification.Name)
+	if !found || isStreamFunction(fn.Type()) {
+		o.logger.Warn("Discard rpc notification for unkown function", slog.String("function", notification.Name))
+		return
+	}
+
+	// Der Trace Context des Aufrufers wird übernommen und ein Span für die Ausführung erzeugt
+	traceCtx := o.propagator.Extract(context.Background(), notification.Metadata)
+	traceCtx, span := o.tracer.Start(traceCtx, notification.Name, SpanKindServer)
+
+	requestCtx, cancel := newRpcRequestContext(o, withHandlerOperations(traceCtx, o, 0), 0)
+	defer cancel()
+
+	req := &BngRequest{
+		Conn:     o,
+		ctx:      requestCtx,
+		method:   notification.Name,
+		metadata: notification.Metadata,
+	}
+
+	// Die Funktion wird ausgeführt, die Rückgabewerte werden verworfen
+	start := time.Now()
+	_, callErr, err := callUnaryRpcFunction(o, req, fn, notification.Params)
+	if callErr == nil {
+		callErr = err
+	}
+	if callErr != nil {
+		span.RecordError(callErr)
+		o.logger.Warn("RPC notification failed", slog.String("function", notification.Name), slog.String(logKeyError, callErr.Error()))
+	}
+	span.End()
+	o.metrics.RpcRequestServed(notification.Name, time.Since(start), callErr)
+}
+
+// Ruft eine Funktion auf der Gegenseite auf, ohne auf eine Antwort zu warten
+func _Notify(ctx context.Context, s *BngConn, nameorid string, params []interface{}) error {
+	// Es wird geprüft ob die Verbindung getrennt wurde
+	if connectionIsClosed(s) {
+		return io.EOF
+	}
+
+	// Es wird geprüft ob neue Aufrufe gestartet werden dürfen
+	if err := acceptsNewOperations(s); err != nil {
+		return err
+	}
+
+	// Es wird geprüft ob die Verwendeten Parameter Zulässigen Datentypen sind
+	if err := validateRpcParamsDatatypes(params...); err != nil {
+		return err
+	}
+
+	// Die Parameter werden umgewandelt
+	convertedParams, err := processRpcGoDataTypeTransportable(messageCodec(s), params...)
+	if err != nil {
+		return fmt.Errorf("bngsocket->_Notify[0]: " + err.Error())
+	}
+
+	// Es wird ein RpcNotification Paket erstellt
+	notification := &transport.RpcNotification{
+		Type:     "rpcntf",
+		Params:   convertedParams,
+		Name:     nameorid,
+		Metadata: injectRpcMetadata(s, ctx),
+	}
+
+	// Das Paket wird in Bytes umgewandelt
+	bytedData, err := marshalMessage(s, notification)
+	if err != nil {
+		return fmt.Errorf("bngsocket->_Notify[1]: " + err.Error())
+	}
+
+	// Das Paket wird gesendet
+	if err := writeBytesIntoSocketConn(s, bytedData); err != nil {
+		if connectionIsClosed(s) {
+			return io.EOF
+		}
+		return fmt.Errorf("bngsocket->_Notify[2]: " + err.Error())
+	}
+
+	return nil
+}
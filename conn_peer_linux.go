@@ -0,0 +1,34 @@
+//go:build linux
+
+package bngsocket
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// peerCredentials liest die Zugangsdaten des Prozesses der Gegenseite über SO_PEERCRED aus.
+// Dies ist nur bei Unix-Sockets möglich.
+func peerCredentials(conn net.Conn) (*PeerCredentials, error) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return nil, ErrPeerCredentialsUnsupported
+	}
+	rawConn, err := unixConn.SyscallConn()
+	if err != nil {
+		return nil, fmt.Errorf("peerCredentials[0]: %w", err)
+	}
+
+	var ucred *syscall.Ucred
+	var credErr error
+	if err := rawConn.Control(func(fd uintptr) {
+		ucred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	}); err != nil {
+		return nil, fmt.Errorf("peerCredentials[1]: %w", err)
+	}
+	if credErr != nil {
+		return nil, fmt.Errorf("peerCredentials[2]: %w", credErr)
+	}
+	return &PeerCredentials{PID: ucred.Pid, UID: ucred.Uid, GID: ucred.Gid}, nil
+}
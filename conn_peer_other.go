@@ -0,0 +1,10 @@
+//go:build !linux
+
+package bngsocket
+
+import "net"
+
+// peerCredentials wird auf diesem Betriebssystem nicht unterstützt.
+func peerCredentials(conn net.Conn) (*PeerCredentials, error) {
+	return nil, ErrPeerCredentialsUnsupported
+}
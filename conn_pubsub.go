@@ -0,0 +1,412 @@
+package bngsocket
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go/transport"
+	"github.com/google/uuid"
+)
+
+// Wildcards, welche in abonnierten Topics verwendet werden können.
+// Die Teile eines Topics werden durch einen Punkt getrennt (z.B. "jobs.42.status").
+const (
+	TopicWildcardOne  = "*" // Passt auf genau einen Teil des Topics
+	TopicWildcardRest = ">" // Passt auf einen oder mehrere verbleibende Teile, nur am Ende zulässig
+)
+
+const (
+	// Maximale Anzahl an Nachrichten, welche wegen einer fehlenden Vorgängernummer zurückgehalten werden.
+	// Wird die Anzahl überschritten, wird die Lücke übersprungen.
+	maxPendingPublishes = 1024
+
+	// Zeit, nach der eine fehlende Nachricht übersprungen wird, damit die nachfolgenden Nachrichten zugestellt werden
+	publishGapTimeout = time.Second
+)
+
+// Decode liest den Wert der Nachricht in v ein.
+//
+// Parameter:
+//   - v interface{}: Ein Zeiger, in den der Wert eingelesen wird.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls der Wert nicht eingelesen werden konnte, ansonsten nil.
+func (m *BngMessage) Decode(v interface{}) error {
+	if err := unmarshalMessage(m.payload, v); err != nil {
+		return fmt.Errorf("bngsocket->BngMessage.Decode: " + err.Error())
+	}
+	return nil
+}
+
+// Topic gibt das abonnierte Topic zurück.
+func (s *BngSubscription) Topic() string {
+	return s.topic
+}
+
+// Unsubscribe beendet das Abonnement, die Gegenseite sendet anschließend keine Nachrichten mehr für dieses Abonnement.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls die Gegenseite nicht informiert werden konnte, ansonsten nil.
+func (s *BngSubscription) Unsubscribe() error {
+	// Das Abonnement wird nur einmal beendet
+	if _, loaded := s.conn.localSubscriptions.LoadAndDelete(s.id); !loaded {
+		return nil
+	}
+
+	// Es wird geprüft ob die Verbindung getrennt wurde
+	if connectionIsClosed(s.conn) {
+		return nil
+	}
+
+	if err := convertAndWriteBytesIntoChan(s.conn, &transport.TopicUnsubscribe{Type: "unsub", Id: s.id}); err != nil {
+		return fmt.Errorf("bngsocket->Unsubscribe: " + err.Error())
+	}
+	return nil
+}
+
+// validateTopic prüft ob ein Topic zulässig ist. Wildcards sind nur in abonnierten Topics erlaubt.
+//
+// Parameter:
+//   - topic string: Das zu prüfende Topic.
+//   - allowWildcards bool: Gibt an ob Wildcards verwendet werden dürfen.
+//
+// Rückgabe:
+//   - error: ErrInvalidTopic, falls das Topic ungültig ist, ansonsten nil.
+func validateTopic(topic string, allowWildcards bool) error {
+	tokens := strings.Split(topic, ".")
+	for i, token := range tokens {
+		switch {
+		case token == "":
+			return fmt.Errorf("%w: empty token in %q", ErrInvalidTopic, topic)
+		case token == TopicWildcardOne || token == TopicWildcardRest:
+			if !allowWildcards {
+				return fmt.Errorf("%w: wildcards are not allowed in %q", ErrInvalidTopic, topic)
+			}
+			if token == TopicWildcardRest && i != len(tokens)-1 {
+				return fmt.Errorf("%w: %q must be the last token in %q", ErrInvalidTopic, TopicWildcardRest, topic)
+			}
+		case strings.ContainsAny(token, TopicWildcardOne+TopicWildcardRest):
+			return fmt.Errorf("%w: wildcard inside token in %q", ErrInvalidTopic, topic)
+		}
+	}
+	return nil
+}
+
+// topicMatches gibt an, ob ein Topic auf ein abonniertes Topic (mit Wildcards) passt.
+func topicMatches(pattern string, topic string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	topicTokens := strings.Split(topic, ".")
+	for i, token := range patternTokens {
+		if token == TopicWildcardRest {
+			return len(topicTokens) > i
+		}
+		if i >= len(topicTokens) {
+			return false
+		}
+		if token != TopicWildcardOne && token != topicTokens[i] {
+			return false
+		}
+	}
+	return len(patternTokens) == len(topicTokens)
+}
+
+// peerSubscribedTo gibt an, ob die Gegenseite ein passendes Topic abonniert hat.
+func peerSubscribedTo(o *BngConn, topic string) bool {
+	found := false
+	o.remoteSubscriptions.Range(func(_, pattern any) bool {
+		found = topicMatches(pattern.(string), topic)
+		return !found
+	})
+	return found
+}
+
+// Wird verwendet um eingehende Abonnement-Anfragen zu verarbeiten
+func processTopicSubscribe(o *BngConn, subscribe *transport.TopicSubscribe) error {
+	ack := &transport.TopicSubscribeAck{Type: "suback", Id: subscribe.Id}
+	if err := validateTopic(subscribe.Topic, true); err != nil {
+		ack.Error = err.Error()
+	} else {
+		o.remoteSubscriptions.Store(subscribe.Id, subscribe.Topic)
+		o.logger.Debug("Peer subscribed topic", slog.String("topic", subscribe.Topic))
+	}
+
+	if err := convertAndWriteBytesIntoChan(o, ack); err != nil {
+		if connectionIsClosed(o) {
+			return io.EOF
+		}
+		return fmt.Errorf("bngsocket->processTopicSubscribe: " + err.Error())
+	}
+	return nil
+}
+
+// Wird verwendet um eingehende Abonnement-Bestätigungen zu verarbeiten
+func processTopicSubscribeAck(o *BngConn, ack *transport.TopicSubscribeAck) {
+	ackChan, found := o.openSubscribeRequests.LoadAndDelete(ack.Id)
+	if !found {
+		o.logger.Debug("Discard acknowledgement for unkown subscription", slog.String("subscription", ack.Id))
+		return
+	}
+	ackChan.(chan *transport.TopicSubscribeAck) <- ack
+}
+
+// Wird verwendet um eingehende Nachrichten in der Reihenfolge ihrer Veröffentlichung an alle passenden Abonnements zu übergeben
+func processTopicPublish(o *BngConn, publish *transport.TopicPublish) {
+	// Nachrichten ohne Nummer (Gegenseite ohne Reihenfolge) werden direkt zugestellt
+	if publish.Seq == 0 {
+		dispatchTopicPublish(o, publish)
+		return
+	}
+
+	o.publishes.recvMu.Lock()
+	defer o.publishes.recvMu.Unlock()
+
+	// Bereits zugestellte Nummern (z.B. nach einer Wiederholung) werden nicht zurückgehalten
+	if publish.Seq <= o.publishes.recvSeq {
+		dispatchTopicPublish(o, publish)
+		return
+	}
+
+	// Die Nachricht wird zurückgehalten, bis alle Vorgänger zugestellt wurden
+	if o.publishes.pending == nil {
+		o.publishes.pending = make(map[uint64]*transport.TopicPublish)
+	}
+	o.publishes.pending[publish.Seq] = publish
+	flushPendingPublishes(o)
+
+	// Werden zu viele Nachrichten zurückgehalten, wird die Lücke sofort übersprungen
+	if len(o.publishes.pending) > maxPendingPublishes {
+		skipPublishGap(o)
+	}
+
+	// Für eine verbleibende Lücke wird ein Timeout gestartet
+	armPublishGapTimer(o)
+}
+
+// flushPendingPublishes stellt alle zurückgehaltenen Nachrichten zu, deren Vorgänger bereits zugestellt wurden.
+// Der Aufrufer muss recvMu halten.
+func flushPendingPublishes(o *BngConn) {
+	for {
+		next, found := o.publishes.pending[o.publishes.recvSeq+1]
+		if !found {
+			return
+		}
+		delete(o.publishes.pending, o.publishes.recvSeq+1)
+		o.publishes.recvSeq++
+		dispatchTopicPublish(o, next)
+	}
+}
+
+// skipPublishGap überspringt die fehlenden Nachrichten bis zur kleinsten zurückgehaltenen Nummer und stellt
+// die zurückgehaltenen Nachrichten anschließend zu. Der Aufrufer muss recvMu halten.
+func skipPublishGap(o *BngConn) {
+	if len(o.publishes.pending) == 0 {
+		return
+	}
+
+	// Die kleinste zurückgehaltene Nummer wird ermittelt
+	next := uint64(0)
+	for seq := range o.publishes.pending {
+		if next == 0 || seq < next {
+			next = seq
+		}
+	}
+
+	// LOG
+	o.logger.Warn("Skipping missing published messages",
+		slog.Uint64("first_missing", o.publishes.recvSeq+1),
+		slog.Uint64("last_missing", next-1),
+		slog.Int("pending", len(o.publishes.pending)))
+
+	o.publishes.recvSeq = next - 1
+	flushPendingPublishes(o)
+}
+
+// armPublishGapTimer startet den Timeout für eine Lücke, sofern noch Nachrichten zurückgehalten werden.
+// Ist die Lücke nach Ablauf noch vorhanden, wird sie übersprungen. Der Aufrufer muss recvMu halten.
+func armPublishGapTimer(o *BngConn) {
+	if len(o.publishes.pending) == 0 {
+		if o.publishes.gapTimer != nil {
+			o.publishes.gapTimer.Stop()
+			o.publishes.gapTimer = nil
+		}
+		return
+	}
+
+	// Für die selbe Lücke läuft bereits ein Timeout
+	if o.publishes.gapTimer != nil && o.publishes.gapSeq == o.publishes.recvSeq {
+		return
+	}
+	if o.publishes.gapTimer != nil {
+		o.publishes.gapTimer.Stop()
+	}
+
+	gapSeq := o.publishes.recvSeq
+	o.publishes.gapSeq = gapSeq
+	o.publishes.gapTimer = time.AfterFunc(publishGapTimeout, func() {
+		o.publishes.recvMu.Lock()
+		defer o.publishes.recvMu.Unlock()
+
+		// Die Lücke wurde inzwischen geschlossen oder ein neuer Timeout gestartet
+		if o.publishes.gapSeq != gapSeq || o.publishes.recvSeq != gapSeq || connectionIsClosed(o) {
+			return
+		}
+		o.publishes.gapTimer = nil
+		skipPublishGap(o)
+		armPublishGapTimer(o)
+	})
+}
+
+// dispatchTopicPublish übergibt eine Nachricht an die Warteschlangen aller passenden Abonnements.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - publish *transport.TopicPublish: Die empfangene Nachricht.
+func dispatchTopicPublish(o *BngConn, publish *transport.TopicPublish) {
+	message := &BngMessage{Topic: publish.Topic, payload: publish.Payload}
+	o.localSubscriptions.Range(func(_, value any) bool {
+		subscription := value.(*BngSubscription)
+		if topicMatches(subscription.topic, publish.Topic) {
+			subscription.enqueue(message)
+		}
+		return true
+	})
+}
+
+// enqueue stellt eine Nachricht in die Warteschlange des Abonnements. Arbeitet noch keine Routine
+// die Warteschlange ab, wird eine gestartet, sodass der Handler nie nebenläufig aufgerufen wird.
+//
+// Parameter:
+//   - message *BngMessage: Die zuzustellende Nachricht.
+func (s *BngSubscription) enqueue(message *BngMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.queue = append(s.queue, message)
+	if s.delivering {
+		return
+	}
+	s.delivering = true
+
+	s.conn.backgroundProcesses.Add(1)
+	go s.deliver()
+}
+
+// deliver übergibt die Nachrichten der Warteschlange nacheinander an den Handler.
+func (s *BngSubscription) deliver() {
+	defer s.conn.backgroundProcesses.Done()
+
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			s.delivering = false
+			s.mu.Unlock()
+			return
+		}
+		message := s.queue[0]
+		s.queue[0] = nil
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		// Der Handler wird PANIC Sicher ausgeführt
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					s.conn.logger.Error("Subscription handler panicked", slog.String("topic", message.Topic), slog.Any(logKeyError, r))
+				}
+			}()
+			s.handler(message)
+		}()
+	}
+}
+
+// Abonniert ein Topic bei der Gegenseite
+func _Subscribe(s *BngConn, topic string, handler func(*BngMessage)) (*BngSubscription, error) {
+	// Es wird geprüft ob die Verbindung getrennt wurde
+	if connectionIsClosed(s) {
+		return nil, io.EOF
+	}
+
+	// Das Topic wird geprüft
+	if err := validateTopic(topic, true); err != nil {
+		return nil, err
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("bngsocket->_Subscribe[0]: handler is nil")
+	}
+
+	// Das Abonnement wird vor dem Senden registriert, damit keine Nachricht nach der Bestätigung verloren geht
+	subscription := &BngSubscription{
+		conn:    s,
+		id:      strings.ReplaceAll(uuid.NewString(), "-", ""),
+		topic:   topic,
+		handler: handler,
+	}
+	s.localSubscriptions.Store(subscription.id, subscription)
+	ackChan := make(chan *transport.TopicSubscribeAck, 1)
+	s.openSubscribeRequests.Store(subscription.id, ackChan)
+
+	// Die Anfrage wird gesendet
+	request := &transport.TopicSubscribe{Type: "subreq", Id: subscription.id, Topic: topic}
+	if err := convertAndWriteBytesIntoChan(s, request); err != nil {
+		s.localSubscriptions.Delete(subscription.id)
+		s.openSubscribeRequests.Delete(subscription.id)
+		if connectionIsClosed(s) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("bngsocket->_Subscribe[1]: " + err.Error())
+	}
+
+	// Es wird auf die Bestätigung gewartet, wurde der Chan geschlossen, ist die Verbindung beendet worden
+	ack, ok := <-ackChan
+	if !ok {
+		s.localSubscriptions.Delete(subscription.id)
+		return nil, connectionTerminationError(s)
+	}
+	if ack.Error != "" {
+		s.localSubscriptions.Delete(subscription.id)
+		return nil, processError(ack.Error)
+	}
+
+	return subscription, nil
+}
+
+// Veröffentlicht eine Nachricht, sofern die Gegenseite ein passendes Topic abonniert hat
+func _Publish(s *BngConn, topic string, value interface{}) error {
+	// Es wird geprüft ob die Verbindung getrennt wurde
+	if connectionIsClosed(s) {
+		return io.EOF
+	}
+
+	// Das Topic wird geprüft
+	if err := validateTopic(topic, false); err != nil {
+		return err
+	}
+
+	// Hat die Gegenseite kein passendes Topic abonniert, wird nichts übertragen
+	if !peerSubscribedTo(s, topic) {
+		return nil
+	}
+
+	// Der Wert wird umgewandelt
+	payload, err := marshalMessage(s, value)
+	if err != nil {
+		return fmt.Errorf("bngsocket->_Publish[0]: " + err.Error())
+	}
+
+	// Die Nachricht wird nummeriert gesendet, die Nummern werden in ihrer Reihenfolge übertragen
+	s.publishes.sendMu.Lock()
+	defer s.publishes.sendMu.Unlock()
+	s.publishes.sendSeq++
+	if err := convertAndWriteBytesIntoChan(s, &transport.TopicPublish{Type: "pubmsg", Topic: topic, Payload: payload, Seq: s.publishes.sendSeq}); err != nil {
+		// Die Nummer wurde nicht zugestellt und wird erneut vergeben
+		s.publishes.sendSeq--
+		if connectionIsClosed(s) {
+			return io.EOF
+		}
+		return fmt.Errorf("bngsocket->_Publish[1]: " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package bngsocket
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go/transport"
+)
+
+// Registriert ein Abonnement, welches die Nummern der zugestellten Nachrichten aufzeichnet
+func newRecordingSubscription(conn *BngConn) func() []uint64 {
+	var mu sync.Mutex
+	var received []uint64
+	conn.localSubscriptions.Store("record", &BngSubscription{
+		conn:  conn,
+		id:    "record",
+		topic: TopicWildcardRest,
+		handler: func(m *BngMessage) {
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, uint64(m.payload[0]))
+		},
+	})
+	return func() []uint64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]uint64(nil), received...)
+	}
+}
+
+// Wartet bis die erwartete Anzahl an Nachrichten zugestellt wurde
+func waitForPublishes(t *testing.T, received func() []uint64, count int) []uint64 {
+	deadline := time.Now().Add(5 * time.Second)
+	for len(received()) < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d messages, got %v", count, received())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return received()
+}
+
+func TestTopicMatches(t *testing.T) {
+	cases := []struct {
+		pattern string
+		topic   string
+		match   bool
+	}{
+		{"jobs.42.status", "jobs.42.status", true},
+		{"jobs.*.status", "jobs.42.status", true},
+		{"jobs.*.status", "jobs.42.result", false},
+		{"jobs.*", "jobs.42.status", false},
+		{"jobs.>", "jobs.42.status", true},
+		{"jobs.>", "jobs", false},
+		{">", "config", true},
+		{"config", "config.db", false},
+	}
+	for _, c := range cases {
+		if got := topicMatches(c.pattern, c.topic); got != c.match {
+			t.Errorf("topicMatches(%q, %q) = %v, want %v", c.pattern, c.topic, got, c.match)
+		}
+	}
+
+	for _, topic := range []string{"", "a..b", "a.>.b", "a.b*"} {
+		if err := validateTopic(topic, true); err == nil {
+			t.Errorf("topic %q should be invalid", topic)
+		}
+	}
+	if err := validateTopic("a.*", false); err == nil {
+		t.Error("wildcards must not be allowed when publishing")
+	}
+}
+
+func TestPublishGapTimeout(t *testing.T) {
+	conn := newTestReadingConn(t, nil, nil)
+	received := newRecordingSubscription(conn)
+
+	// Die Nachricht 2 fehlt, die Nachricht 3 wird zunächst zurückgehalten
+	for _, seq := range []uint64{1, 3} {
+		processTopicPublish(conn, &transport.TopicPublish{Topic: "jobs", Payload: []byte{byte(seq)}, Seq: seq})
+	}
+	if got := waitForPublishes(t, received, 1); len(got) != 1 {
+		t.Fatalf("message after the gap was delivered early: %v", got)
+	}
+
+	// Nach dem Timeout wird die Lücke übersprungen
+	got := waitForPublishes(t, received, 2)
+	if got[0] != 1 || got[1] != 3 {
+		t.Fatalf("unexpected order %v", got)
+	}
+
+	// Nachfolgende Nachrichten werden wieder direkt zugestellt
+	processTopicPublish(conn, &transport.TopicPublish{Topic: "jobs", Payload: []byte{4}, Seq: 4})
+	if got := waitForPublishes(t, received, 3); got[2] != 4 {
+		t.Fatalf("unexpected order %v", got)
+	}
+}
+
+func TestPublishPendingLimit(t *testing.T) {
+	conn := newTestReadingConn(t, nil, nil)
+	received := newRecordingSubscription(conn)
+
+	// Die Nachricht 1 fehlt, sobald zu viele Nachrichten zurückgehalten werden, wird die Lücke übersprungen
+	for seq := uint64(2); seq <= maxPendingPublishes+2; seq++ {
+		processTopicPublish(conn, &transport.TopicPublish{Topic: "jobs", Payload: []byte{byte(seq)}, Seq: seq})
+	}
+	conn.publishes.recvMu.Lock()
+	pending, recvSeq := len(conn.publishes.pending), conn.publishes.recvSeq
+	conn.publishes.recvMu.Unlock()
+	if pending != 0 || recvSeq != maxPendingPublishes+2 {
+		t.Fatalf("gap was not skipped: pending=%d recvSeq=%d", pending, recvSeq)
+	}
+	waitForPublishes(t, received, maxPendingPublishes+1)
+}
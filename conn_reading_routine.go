@@ -3,19 +3,32 @@ package bngsocket
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"hash/crc32"
 	"io"
+	"log/slog"
+	"os"
+	"time"
 
 	"github.com/custodia-cenv/bngsocket-go/transport"
-	"github.com/vmihailenco/msgpack/v5"
 )
 
 // Nimmt eintreffende Daten entgegen
 func processReadedData(o *BngConn, data []byte) {
+	// Der Codec der Nachricht wird ermittelt
+	codec, data, err := unwrapMessage(data)
+	if err != nil {
+		// Aus Sicherheitsgründen wird die Verbindung terminiert
+		consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[0a]: "+err.Error()))
+
+		// Wird beendet
+		return
+	}
+
 	// Dynamisches Unmarshallen in eine map[string]interface{} oder interface{}
 	var typeInfo transport.TypeInfo
-	err := msgpack.Unmarshal(data, &typeInfo)
+	err = codec.Unmarshal(data, &typeInfo)
 	if err != nil {
 		// Aus Sicherheitsgründen wird die Verbindung terminiert
 		consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[0]: "+err.Error()))
@@ -25,17 +38,17 @@ func processReadedData(o *BngConn, data []byte) {
 	}
 
 	// LOG
-	_DebugPrint(fmt.Sprintf("BngConn(%s): Enter data: %s", o._innerhid, typeInfo.Type))
+	o.logger.Debug("Enter data", slog.String("type", typeInfo.Type))
 
 	// Dynamische Verarbeitung basierend auf dem Typ des Wertes
 	switch typeInfo.Type {
 	// RPC Pakete
-	case "rpcreq", "rpcres":
+	case "rpcreq", "rpcres", "rpcntf", "rpcbatchreq", "rpcbatchres":
 		switch typeInfo.Type {
 		case "rpcreq":
 			// Der Datensatz wird als RPC Regquest eingelesen
 			var rpcRequest *transport.RpcRequest
-			err := msgpack.Unmarshal(data, &rpcRequest)
+			err := codec.Unmarshal(data, &rpcRequest)
 			if err != nil {
 				// Aus Sicherheitsgründen wird die Verbindung terminiert
 				consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[1]: "+err.Error()))
@@ -45,7 +58,7 @@ func processReadedData(o *BngConn, data []byte) {
 			}
 
 			// LOG
-			_DebugPrint(fmt.Sprintf("BngConn(%s): Enter RPC-Request: %s", o._innerhid, rpcRequest.Id))
+			o.logger.Debug("Enter RPC-Request", slog.String(logKeyRpcId, rpcRequest.Id))
 
 			// Das Paket wird weiterverarbeitet
 			if err := processRpcRequest(o, rpcRequest); err != nil {
@@ -58,7 +71,7 @@ func processReadedData(o *BngConn, data []byte) {
 		case "rpcres":
 			// Der Datensatz wird als RPC Regquest eingelesen
 			var rpcResponse *transport.RpcResponse
-			err := msgpack.Unmarshal(data, &rpcResponse)
+			err := codec.Unmarshal(data, &rpcResponse)
 			if err != nil {
 				// Aus Sicherheitsgründen wird die Verbindung terminiert
 				consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[3]: "+err.Error()))
@@ -68,7 +81,7 @@ func processReadedData(o *BngConn, data []byte) {
 			}
 
 			// LOG
-			_DebugPrint(fmt.Sprintf("BngConn(%s): Enter RPC-Response: %s", o._innerhid, rpcResponse.Id))
+			o.logger.Debug("Enter RPC-Response", slog.String(logKeyRpcId, rpcResponse.Id))
 
 			// Das Paket wird weiterverarbeitet
 			if err := processRpcResponse(o, rpcResponse); err != nil {
@@ -78,6 +91,53 @@ func processReadedData(o *BngConn, data []byte) {
 				// Wird beendet
 				return
 			}
+		case "rpcbatchreq":
+			// Der Datensatz wird als RPC Batch Request eingelesen
+			var batchRequest *transport.RpcBatchRequest
+			if err := codec.Unmarshal(data, &batchRequest); err != nil {
+				// Aus Sicherheitsgründen wird die Verbindung terminiert
+				consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[4b]: "+err.Error()))
+				return
+			}
+
+			// Das Paket wird weiterverarbeitet
+			if err := processRpcBatchRequest(o, batchRequest); err != nil {
+				// Aus Sicherheitsgründen wird die Verbindung terminiert
+				consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[4c]: "+err.Error()))
+				return
+			}
+		case "rpcbatchres":
+			// Der Datensatz wird als RPC Batch Response eingelesen
+			var batchResponse *transport.RpcBatchResponse
+			if err := codec.Unmarshal(data, &batchResponse); err != nil {
+				// Aus Sicherheitsgründen wird die Verbindung terminiert
+				consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[4d]: "+err.Error()))
+				return
+			}
+
+			// Das Paket wird weiterverarbeitet
+			if err := processRpcBatchResponse(o, batchResponse); err != nil {
+				// Aus Sicherheitsgründen wird die Verbindung terminiert
+				consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[4e]: "+err.Error()))
+				return
+			}
+		case "rpcntf":
+			// Der Datensatz wird als RPC Notification eingelesen
+			var rpcNotification *transport.RpcNotification
+			err := codec.Unmarshal(data, &rpcNotification)
+			if err != nil {
+				// Aus Sicherheitsgründen wird die Verbindung terminiert
+				consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[4a]: "+err.Error()))
+
+				// Wird beendet
+				return
+			}
+
+			// LOG
+			o.logger.Debug("Enter RPC-Notification", slog.String("function", rpcNotification.Name))
+
+			// Das Paket wird weiterverarbeitet, es wird keine Antwort gesendet
+			processRpcNotification(o, rpcNotification)
 		}
 	// Channel Pakete
 	case "chreq", "chreqresp", "chst", "chsig", "chtsr":
@@ -85,7 +145,7 @@ func processReadedData(o *BngConn, data []byte) {
 		case "chreq":
 			// Der Datensatz wird ChannelRequest eingelesen
 			var channlrequest *transport.ChannelRequest
-			err := msgpack.Unmarshal(data, &channlrequest)
+			err := codec.Unmarshal(data, &channlrequest)
 			if err != nil {
 				// Aus Sicherheitsgründen wird die Verbindung terminiert
 				consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[3]: "+err.Error()))
@@ -105,7 +165,7 @@ func processReadedData(o *BngConn, data []byte) {
 		case "chreqresp":
 			// Der Datensatz wird als ChannelRequestResponse eingelesen
 			var channlrequest *transport.ChannelRequestResponse
-			err := msgpack.Unmarshal(data, &channlrequest)
+			err := codec.Unmarshal(data, &channlrequest)
 			if err != nil {
 				// Aus Sicherheitsgründen wird die Verbindung terminiert
 				consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[5]: "+err.Error()))
@@ -125,7 +185,7 @@ func processReadedData(o *BngConn, data []byte) {
 		case "chst":
 			// Der Datensatz wird als ChannelSessionDataTransport eingelesen
 			var channlrequest *transport.ChannelSessionDataTransport
-			err := msgpack.Unmarshal(data, &channlrequest)
+			err := codec.Unmarshal(data, &channlrequest)
 			if err != nil {
 				// Aus Sicherheitsgründen wird die Verbindung terminiert
 				consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[7]: "+err.Error()))
@@ -145,7 +205,7 @@ func processReadedData(o *BngConn, data []byte) {
 		case "chsig":
 			// Der Datensatz wird als ChannelSessionTransportSignal eingelesen
 			var channlrequest *transport.ChannlSessionTransportSignal
-			err := msgpack.Unmarshal(data, &channlrequest)
+			err := codec.Unmarshal(data, &channlrequest)
 			if err != nil {
 				// Aus Sicherheitsgründen wird die Verbindung terminiert
 				consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[9]: "+err.Error()))
@@ -165,7 +225,7 @@ func processReadedData(o *BngConn, data []byte) {
 		case "chtsr":
 			// Der Datensatz wird als ChannelTransportStateResponse eingelesen
 			var channlrequest *transport.ChannelTransportStateResponse
-			err := msgpack.Unmarshal(data, &channlrequest)
+			err := codec.Unmarshal(data, &channlrequest)
 			if err != nil {
 				// Aus Sicherheitsgründen wird die Verbindung terminiert
 				consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[11]: "+err.Error()))
@@ -183,6 +243,46 @@ func processReadedData(o *BngConn, data []byte) {
 				return
 			}
 		}
+	// Pub/Sub Pakete
+	case "subreq", "suback", "unsub", "pubmsg":
+		switch typeInfo.Type {
+		case "subreq":
+			var subscribe *transport.TopicSubscribe
+			if err := codec.Unmarshal(data, &subscribe); err != nil {
+				// Aus Sicherheitsgründen wird die Verbindung terminiert
+				consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[13a]: "+err.Error()))
+				return
+			}
+			if err := processTopicSubscribe(o, subscribe); err != nil {
+				// Aus Sicherheitsgründen wird die Verbindung terminiert
+				consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[13b]: "+err.Error()))
+				return
+			}
+		case "suback":
+			var ack *transport.TopicSubscribeAck
+			if err := codec.Unmarshal(data, &ack); err != nil {
+				// Aus Sicherheitsgründen wird die Verbindung terminiert
+				consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[13c]: "+err.Error()))
+				return
+			}
+			processTopicSubscribeAck(o, ack)
+		case "unsub":
+			var unsubscribe *transport.TopicUnsubscribe
+			if err := codec.Unmarshal(data, &unsubscribe); err != nil {
+				// Aus Sicherheitsgründen wird die Verbindung terminiert
+				consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[13d]: "+err.Error()))
+				return
+			}
+			o.remoteSubscriptions.Delete(unsubscribe.Id)
+		case "pubmsg":
+			var publish *transport.TopicPublish
+			if err := codec.Unmarshal(data, &publish); err != nil {
+				// Aus Sicherheitsgründen wird die Verbindung terminiert
+				consensusProtocolTermination(o, fmt.Errorf("bngsocket->_ProcessReadedData[13e]: "+err.Error()))
+				return
+			}
+			processTopicPublish(o, publish)
+		}
 	// Unbekannter Pakettyp
 	default:
 		// Aus Sicherheitsgründen wird die Verbindung terminiert
@@ -196,68 +296,131 @@ func processReadedData(o *BngConn, data []byte) {
 // handleMessage liest und verarbeitet eine MSG-Nachricht aus dem Reader der BngConn.
 // Die empfangenen Daten werden in den bereitgestellten Cache geschrieben. Die Funktion
 // liest zunächst die Länge der Nachricht (Big-Endian), anschließend die eigentlichen
-// Daten. Nach dem Schreiben in den Cache wird eine Checksumme der Daten berechnet und
-// eine Debug-Ausgabe erzeugt.
+// Daten. Längenangaben über frameChunkSize werden abgelehnt, bevor ein Puffer angelegt
+// wird. Handelt es sich um einen Frame mit Checksumme ('m'), wird im Anschluss die
+// CRC32 Checksumme über Längenangabe und Daten gelesen und geprüft, fehlerhafte Chunks
+// werden nicht in den Cache übernommen.
 //
 // Parameter:
 //   - cache *bytes.Buffer: Ein Puffer, in den die empfangenen Daten geschrieben werden.
 //   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung und
 //     zugehörige Ressourcen verwaltet.
+//   - withChecksum bool: Gibt an ob der Frame eine Checksumme enthält.
 //
 // Rückgabe:
 //   - error: Ein Fehler, falls beim Lesen oder Verarbeiten der Nachricht ein Problem
-//     aufgetreten ist, ansonsten nil.s
-func handleMessage(cache *bytes.Buffer, o *BngConn) error {
+//     aufgetreten ist, ansonsten nil. Bei einer fehlerhaften Checksumme wird
+//     ErrChecksumMismatch, bei einer zu großen Längenangabe ErrFrameTooLarge zurückgegeben.
+func handleMessage(cache *bytes.Buffer, o *BngConn, withChecksum bool) error {
 	// Lesen der Datenlänge (Big-Endian)
 	var dataLength uint32
 	if err := binary.Read(o.reader, binary.BigEndian, &dataLength); err != nil {
 		return fmt.Errorf("%s: %w: %v", o._innerhid, ErrMessageLength, err)
 	}
 
+	// Die Länge darf die maximale Chunkgröße nicht überschreiten, sie wird vor dem Anlegen des Puffers geprüft
+	if dataLength > frameChunkSize {
+		return fmt.Errorf("%s: %w: %d bytes", o._innerhid, ErrFrameTooLarge, dataLength)
+	}
+
 	// Lesen der Nachrichtendaten
 	data := make([]byte, dataLength)
 	if _, err := io.ReadFull(o.reader, data); err != nil {
 		return fmt.Errorf("%s: %w: %v", o._innerhid, ErrMessageRead, err)
 	}
 
+	// Berechne die Checksumme über Längenangabe und Daten
+	checksum := chunkChecksum(dataLength, data)
+
+	// Die übertragene Checksumme wird gelesen und geprüft
+	if withChecksum {
+		var transmittedChecksum uint32
+		if err := binary.Read(o.reader, binary.BigEndian, &transmittedChecksum); err != nil {
+			return fmt.Errorf("%s: %w: %v", o._innerhid, ErrReadChecksum, err)
+		}
+		if transmittedChecksum != checksum {
+			return fmt.Errorf("%s: %w: expected %08x, got %08x", o._innerhid, ErrChecksumMismatch, transmittedChecksum, checksum)
+		}
+	}
+
 	// Speichern der Daten im Cache
 	cache.Write(data)
 
-	// Berechne die Checksumme
-	checksum := crc32.ChecksumIEEE(data)
+	// Der Frame wird erfasst (Typ + Länge + Daten + ggf. Checksumme)
+	frameSize := 5 + len(data)
+	if withChecksum {
+		frameSize += 4
+	}
+	o.metrics.FrameReceived(chunkFrameType(withChecksum), frameSize)
 
 	// Debug-Ausgabe: Checksumme und Länge der Nachricht
-	_DebugPrint(fmt.Sprintf("BngConn(%s): MSG received, length=%d, checksum=%08x", o._innerhid, len(data), checksum))
+	o.logger.Debug("MSG received", logFrame(chunkFrameType(withChecksum)), slog.Int("length", len(data)), slog.String("checksum", fmt.Sprintf("%08x", checksum)))
 
 	return nil
 }
 
 // handleEndTransfer verarbeitet das Ende eines Datentransfers (ET-Nachricht).
 // Die Funktion berechnet die Checksumme der im Cache gespeicherten Daten und startet
-// die Verarbeitung dieser Daten in einer separaten Goroutine. Nach der erfolgreichen
+// die Verarbeitung dieser Daten in einer separaten Goroutine. Handelt es sich um einen
+// Frame mit Checksumme ('e'), wird die Checksumme der gesamten Nachricht geprüft.
+// Komprimierte Daten werden vor der Verarbeitung dekomprimiert. Nach der erfolgreichen
 // Verarbeitung wird der Cache geleert.
 //
 // Parameter:
 //   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung und
 //     zugehörige Ressourcen verwaltet.
 //   - cache *bytes.Buffer: Ein Puffer, der die empfangenen Daten enthält.
+//   - withChecksum bool: Gibt an ob der Frame eine Checksumme enthält.
 //
 // Rückgabe:
 //   - error: Ein Fehler, falls bei der Verarbeitung des Datensatzes ein Problem
-//     aufgetreten ist, ansonsten nil.s
-func handleEndTransfer(o *BngConn, cache *bytes.Buffer) error {
-	// Berechne die Checksumme der Daten im Cache
-	data := cache.Bytes()
+//     aufgetreten ist, ansonsten nil. Bei einer fehlerhaften Checksumme wird
+//     ErrChecksumMismatch zurückgegeben.
+func handleEndTransfer(o *BngConn, cache *bytes.Buffer, withChecksum bool) error {
+	// Berechne die Checksumme der Daten im Cache, die Daten werden kopiert da der Cache wiederverwendet wird
+	data := bytes.Clone(cache.Bytes())
 	checksum := crc32.ChecksumIEEE(data)
 
+	// Die übertragene Checksumme wird gelesen und geprüft
+	if withChecksum {
+		var transmittedChecksum uint32
+		if err := binary.Read(o.reader, binary.BigEndian, &transmittedChecksum); err != nil {
+			return fmt.Errorf("%s: %w: %v", o._innerhid, ErrReadChecksum, err)
+		}
+		if transmittedChecksum != checksum {
+			return fmt.Errorf("%s: %w: expected %08x, got %08x", o._innerhid, ErrChecksumMismatch, transmittedChecksum, checksum)
+		}
+	}
+
+	// Der Frame wird erfasst (Typ + ggf. Checksumme)
+	if withChecksum {
+		o.metrics.FrameReceived(endTransferFrameType(withChecksum), 5)
+	} else {
+		o.metrics.FrameReceived(endTransferFrameType(withChecksum), 1)
+	}
+
 	// Debug-Ausgabe: Länge und Checksumme der Daten
-	_DebugPrint(fmt.Sprintf("BngConn(%s): ET received: Processing data (length=%d, checksum=%08x)", o._innerhid, len(data), checksum))
+	o.logger.Debug("ET received, processing data", logFrame(endTransferFrameType(withChecksum)), slog.Int("length", len(data)), slog.String("checksum", fmt.Sprintf("%08x", checksum)))
+
+	// Die Nachricht wird noch vor dem ACK als laufend markiert, damit beim Herunterfahren
+	// auch auf bereits bestätigte, aber noch nicht verarbeitete Aufrufe gewartet wird
+	o.runningRpcCalls.Add(1)
 
 	// Starte die Verarbeitung in einer Goroutine
 	o.backgroundProcesses.Add(1) // Informiere Wartungsgruppe
 	go func(data []byte) {
 		defer o.backgroundProcesses.Done() // Abschluss melden
-		processReadedData(o, data)         // Interne Verarbeitung
+		defer o.runningRpcCalls.Sub(1)
+
+		// Die Daten werden ggf. dekomprimiert
+		data, err := decompressIncomingPayload(o, data)
+		if err != nil {
+			// Aus Sicherheitsgründen wird die Verbindung terminiert
+			consensusProtocolTermination(o, fmt.Errorf("bngsocket->handleEndTransfer: "+err.Error()))
+			return
+		}
+
+		processReadedData(o, data) // Interne Verarbeitung
 	}(data)
 
 	// Cache leeren
@@ -267,9 +430,10 @@ func handleEndTransfer(o *BngConn, cache *bytes.Buffer) error {
 	return nil
 }
 
-// handleACK verarbeitet eine eingehende ACK-Nachricht.
+// handleACK verarbeitet eine eingehende ACK- bzw. NACK-Nachricht.
 // Die Funktion liest die ACK-Daten aus dem Reader der BngConn, prüft die
 // Korrektheit der Nachricht und bestätigt das ACK durch Aufruf von EnterACK.
+// Handelt es sich um ein NACK ("ANK"), wird EnterNACK aufgerufen.
 // Bei erfolgreicher Verarbeitung wird eine Debug-Ausgabe erzeugt.
 //
 // Parameter:
@@ -284,19 +448,85 @@ func handleACK(o *BngConn) error {
 	if _, err := io.ReadFull(o.reader, ack); err != nil {
 		return fmt.Errorf("%s: %w: %v", o._innerhid, ErrACKReadFailure, err)
 	}
+	o.metrics.FrameReceived('A', 3)
 
 	// Prüfen, ob die Nachricht korrekt ist
-	if string(ack) != "CK" {
+	switch string(ack) {
+	case "CK":
+		// Rufe die Methode `EnterACK` auf, um ACK zu bestätigen
+		if err := o.ackHandle.EnterACK(); err != nil {
+			return fmt.Errorf("%s: failed to process ACK: %v", o._innerhid, err)
+		}
+		o.logger.Debug("ACK successfully processed", logFrame('A'))
+	case "NK":
+		// Rufe die Methode `EnterNACK` auf, der Frame wird erneut gesendet
+		if err := o.ackHandle.EnterNACK(); err != nil {
+			return fmt.Errorf("%s: failed to process NACK: %v", o._innerhid, err)
+		}
+		o.logger.Debug("NACK successfully processed", logFrame('A'))
+	default:
 		return fmt.Errorf("%s: %w", o._innerhid, ErrInvalidACK)
 	}
 
-	// Rufe die Methode `EnterACK` auf, um ACK zu bestätigen
-	if err := o.ackHandle.EnterACK(); err != nil {
-		return fmt.Errorf("%s: failed to process ACK: %v", o._innerhid, err)
+	return nil
+}
+
+// rejectCorruptedFrame wird aufgerufen, wenn ein Frame mit einer fehlerhaften Checksumme oder Länge
+// empfangen wurde. Im Modus FrameIntegrityRetransmit werden die restlichen Bytes des Frames verworfen
+// und der Frame wird mittels NACK erneut angefordert, andernfalls wird die Verbindung beendet.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - err error: Der aufgetretene Fehler.
+//
+// Rückgabe:
+//   - bool: true, wenn die Verbindung geschlossen wurde, andernfalls false.
+func rejectCorruptedFrame(o *BngConn, err error) bool {
+	// Es wird geprüft ob der Frame erneut angefordert werden soll
+	if o.config.FrameIntegrity != FrameIntegrityRetransmit {
+		return readProcessErrorHandling(o, err)
+	}
+
+	// LOG
+	o.logger.Warn("Corrupted frame, requesting retransmission", slog.Any(logKeyError, err))
+	o.metrics.ErrorOccurred(MetricsErrorChecksum)
+
+	// Nach einer beschädigten Längenangabe können noch Bytes des Frames ausstehen, diese werden verworfen
+	if err := discardCorruptedFrame(o); err != nil {
+		return readProcessErrorHandling(o, err)
 	}
 
-	_DebugPrint(fmt.Sprintf("BngConn(%s): ACK successfully processed", o._innerhid))
-	return nil
+	// Der Frame wird erneut angefordert
+	if err := writePacketNACK(o); err != nil {
+		return readProcessErrorHandling(o, err)
+	}
+
+	return false
+}
+
+// discardCorruptedFrame verwirft alle Bytes, welche nach einem beschädigten Frame noch eintreffen.
+// Da der Sender vor dem nächsten Frame auf ein ACK bzw. NACK wartet, ist der Stream wieder synchron,
+// sobald für corruptedFrameDrainTimeout keine weiteren Bytes eingetroffen sind.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls beim Lesen ein anderer Fehler als das Ablaufen der Deadline auftritt.
+func discardCorruptedFrame(o *BngConn) error {
+	defer o.conn.SetReadDeadline(time.Time{})
+
+	for {
+		if err := o.conn.SetReadDeadline(time.Now().Add(corruptedFrameDrainTimeout)); err != nil {
+			return fmt.Errorf("bngsocket->discardCorruptedFrame[0]: " + err.Error())
+		}
+		if _, err := o.reader.Discard(max(o.reader.Buffered(), 1)); err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return nil
+			}
+			return fmt.Errorf("bngsocket->discardCorruptedFrame[1]: " + err.Error())
+		}
+	}
 }
 
 // constantReading führt eine kontinuierliche Leseschleife auf der Socket-Verbindung des
@@ -304,14 +534,20 @@ func handleACK(o *BngConn) error {
 // auf ihrem Typ:
 //   - 'M' (MSG): Ein Teil des Datensatzes wird empfangen und verarbeitet. Nach erfolgreicher
 //     Verarbeitung wird eine Bestätigung (ACK) zurückgesendet.
+//   - 'm' (MSG mit Checksumme): Wie 'M', zusätzlich wird die CRC32 Checksumme des Chunks geprüft.
 //   - 'E' (ET): Das Ende eines Datensatzes wird empfangen. Der gesamte Datensatz aus dem
 //     Cache wird verarbeitet und eine Bestätigung (ACK) wird zurückgesendet.
-//   - 'A' (ACK): Eine eingehende Bestätigung wird verarbeitet.
+//   - 'e' (ET mit Checksumme): Wie 'E', zusätzlich wird die CRC32 Checksumme der gesamten Nachricht geprüft.
+//   - 'A' (ACK/NACK): Eine eingehende Bestätigung wird verarbeitet.
+//   - 'P' (PING): Ein Ping der Gegenseite wird mit einem Pong beantwortet.
+//   - 'O' (PONG): Ein Pong der Gegenseite wird verarbeitet und die RTT berechnet.
+//   - 'G' (GOAWAY): Die Gegenseite fährt herunter, es werden keine neuen Vorgänge mehr gestartet.
 //
 // Bei Auftreten von Fehlern während des Lese- oder Verarbeitungsprozesses wird die
 // Funktion `readProcessErrorHandling` aufgerufen, um den Fehler zu behandeln. Abhängig von der
 // Fehlerbehandlung kann die Verbindung geschlossen oder der Cache zurückgesetzt werden, um
-// den Vorgang neu zu starten.
+// den Vorgang neu zu starten. Bei einer fehlerhaften Checksumme wird im Modus
+// FrameIntegrityRetransmit ein NACK gesendet, woraufhin die Gegenseite den Frame erneut sendet.
 //
 // Die Schleife läuft solange, wie die Funktion `runningBackgroundServingLoop(o)` den Wert
 // `true` zurückgibt. Beim Start und beim Stoppen der Leseschleife werden Debug-Informationen
@@ -323,11 +559,11 @@ func handleACK(o *BngConn) error {
 //     Ressourcen verwaltet.s
 func constantReading(o *BngConn) {
 	defer func() {
-		_DebugPrint(fmt.Sprintf("BngConn(%s): Constant reading from Socket was stopped", o._innerhid))
+		o.logger.Debug("Constant reading from Socket was stopped")
 		o.backgroundProcesses.Done()
 	}()
 
-	_DebugPrint(fmt.Sprintf("BngConn(%s): Constant reading from Socket was started", o._innerhid))
+	o.logger.Debug("Constant reading from Socket was started")
 
 	var cache bytes.Buffer // Cache für MSG-Daten
 
@@ -340,9 +576,17 @@ func constantReading(o *BngConn) {
 		}
 
 		switch msgType {
-		case 'M': // MSG: Ein Teil des Datensatzes
-			_DebugPrint(fmt.Sprintf("BngConn(%s): MSG received", o._innerhid))
-			if err := handleMessage(&cache, o); err != nil {
+		case 'M', 'm': // MSG: Ein Teil des Datensatzes
+			o.logger.Debug("MSG received", logFrame(msgType))
+			if err := handleMessage(&cache, o, msgType == 'm'); err != nil {
+				// Bei einer fehlerhaften Checksumme oder Länge wird der Chunk ggf. erneut angefordert
+				if errors.Is(err, ErrChecksumMismatch) || errors.Is(err, ErrFrameTooLarge) {
+					if rejectCorruptedFrame(o, err) {
+						return
+					}
+					continue
+				}
+
 				// Der Fehler wird verarbeitet ggf wird die Verbindung geschlossen
 				if readProcessErrorHandling(o, err) {
 					return
@@ -363,10 +607,19 @@ func constantReading(o *BngConn) {
 					continue
 				}
 			}
-		case 'E': // ET: Ende des Datensatzes
-			_DebugPrint(fmt.Sprintf("BngConn(%s): ET received", o._innerhid))
+		case 'E', 'e': // ET: Ende des Datensatzes
+			o.logger.Debug("ET received", logFrame(msgType))
 			// Jetzt den kompletten Datensatz aus dem Cache verarbeiten
-			if err := handleEndTransfer(o, &cache); err != nil {
+			if err := handleEndTransfer(o, &cache, msgType == 'e'); err != nil {
+				// Bei einer fehlerhaften Checksumme wird die gesamte Nachricht ggf. erneut angefordert
+				if errors.Is(err, ErrChecksumMismatch) {
+					cache.Reset()
+					if rejectCorruptedFrame(o, err) {
+						return
+					}
+					continue
+				}
+
 				// Der Fehler wird verarbeitet ggf wird die Verbindung geschlossen
 				if readProcessErrorHandling(o, err) {
 					return
@@ -388,7 +641,7 @@ func constantReading(o *BngConn) {
 				}
 			}
 		case 'A': // ACK: Eingehende Bestätigung
-			_DebugPrint(fmt.Sprintf("BngConn(%s): ACK received", o._innerhid))
+			o.logger.Debug("ACK received", logFrame(msgType))
 			if err := handleACK(o); err != nil {
 				// Der Fehler wird verarbeitet ggf wird die Verbindung geschlossen
 				if readProcessErrorHandling(o, err) {
@@ -399,6 +652,27 @@ func constantReading(o *BngConn) {
 					continue
 				}
 			}
+		case 'P': // PING: Die Gegenseite prüft ob die Verbindung noch besteht
+			if err := handlePing(o); err != nil {
+				// Der Fehler wird verarbeitet ggf wird die Verbindung geschlossen
+				if readProcessErrorHandling(o, err) {
+					return
+				}
+			}
+		case 'G': // GOAWAY: Die Gegenseite fährt die Verbindung herunter
+			if err := handleGoAway(o); err != nil {
+				// Der Fehler wird verarbeitet ggf wird die Verbindung geschlossen
+				if readProcessErrorHandling(o, err) {
+					return
+				}
+			}
+		case 'O': // PONG: Antwort auf einen gesendeten Ping
+			if err := handlePong(o); err != nil {
+				// Der Fehler wird verarbeitet ggf wird die Verbindung geschlossen
+				if readProcessErrorHandling(o, err) {
+					return
+				}
+			}
 		default:
 			// Der Fehler wird verarbeitet ggf wird die Verbindung geschlossen
 			if readProcessErrorHandling(o, ErrUnknownMessageType) {
@@ -0,0 +1,91 @@
+package bngsocket
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"net"
+	"testing"
+)
+
+// Erzeugt ein BngConn Objekt, welches die übergebenen Bytes liest
+func newTestReadingConn(t *testing.T, input []byte, config *BngConnConfig) *BngConn {
+	local, remote := net.Pipe()
+	t.Cleanup(func() {
+		local.Close()
+		remote.Close()
+	})
+	conn := _NewBaseBngSocketObject(local, normalizeBngConnConfig(config))
+	conn.reader = bufio.NewReader(bytes.NewReader(input))
+	return conn
+}
+
+// Baut einen 'm' Frame ohne das Typ-Byte zusammen
+func buildChecksumChunk(data []byte, checksum uint32) []byte {
+	var frame bytes.Buffer
+	binary.Write(&frame, binary.BigEndian, uint32(len(data)))
+	frame.Write(data)
+	binary.Write(&frame, binary.BigEndian, checksum)
+	return frame.Bytes()
+}
+
+func TestHandleMessageChecksum(t *testing.T) {
+	data := []byte("checksum protected chunk")
+
+	// Ein korrekter Chunk wird in den Cache übernommen
+	var cache bytes.Buffer
+	conn := newTestReadingConn(t, buildChecksumChunk(data, chunkChecksum(uint32(len(data)), data)), nil)
+	if err := handleMessage(&cache, conn, true); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(cache.Bytes(), data) {
+		t.Fatal("chunk was not written into cache")
+	}
+
+	// Ein fehlerhafter Chunk darf nicht in den Cache übernommen werden
+	cache.Reset()
+	conn = newTestReadingConn(t, buildChecksumChunk(data, chunkChecksum(uint32(len(data)), data)+1), nil)
+	if err := handleMessage(&cache, conn, true); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if cache.Len() != 0 {
+		t.Fatal("corrupted chunk was written into cache")
+	}
+
+	// Die Checksumme umfasst auch die Längenangabe
+	cache.Reset()
+	frame := buildChecksumChunk(data, crc32.ChecksumIEEE(data))
+	conn = newTestReadingConn(t, frame, nil)
+	if err := handleMessage(&cache, conn, true); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+
+	// Eine zu große Längenangabe wird vor dem Lesen der Daten abgelehnt
+	cache.Reset()
+	conn = newTestReadingConn(t, buildChecksumChunk(make([]byte, frameChunkSize+1), 0), nil)
+	if err := handleMessage(&cache, conn, true); !errors.Is(err, ErrFrameTooLarge) {
+		t.Fatalf("expected frame too large, got %v", err)
+	}
+}
+
+func TestHandleACKWithNACK(t *testing.T) {
+	conn := newTestReadingConn(t, []byte("NKCK"), nil)
+
+	// Ein NACK muss an den wartenden Writer weitergegeben werden
+	if err := handleACK(conn); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.ackHandle.WaitOfACK(); !errors.Is(err, ErrFrameNACK) {
+		t.Fatalf("expected NACK, got %v", err)
+	}
+
+	// Ein darauf folgendes ACK wird normal verarbeitet
+	if err := handleACK(conn); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.ackHandle.WaitOfACK(); err != nil {
+		t.Fatal(err)
+	}
+}
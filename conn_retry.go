@@ -0,0 +1,322 @@
+package bngsocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go/transport"
+	"github.com/google/uuid"
+)
+
+// Standardwerte für Wiederholungen und die Deduplizierung von Aufrufen
+const (
+	DefaultRetryInitialBackoff = 100 * time.Millisecond // Wartezeit vor der ersten Wiederholung
+	DefaultRetryMaxBackoff     = 5 * time.Second        // Maximale Wartezeit zwischen zwei Versuchen
+	DefaultRetryMultiplier     = 2.0                    // Faktor, um den die Wartezeit nach jedem Versuch wächst
+	DefaultIdempotencyTTL      = 5 * time.Minute        // Dauer, für welche die Antwort eines Aufrufs mit Idempotency Key vorgehalten wird
+)
+
+// RetryPolicy legt fest, wie oft und in welchem Abstand ein fehlgeschlagener Aufruf wiederholt wird.
+// Alle Versuche eines Aufrufs werden mit demselben Idempotency Key gesendet, wodurch die Gegenseite
+// Wiederholungen eines bereits erfolgreich ausgeführten Aufrufs erkennt und die zwischengespeicherte Antwort zurückgibt.
+// Die Wiederholungen erfolgen über dieselbe Verbindung, sie eignen sich daher für vorübergehende Fehler der Gegenseite
+// (z.B. ausgelastet). Nimmt die Verbindung keine neuen Aufrufe mehr an (GOAWAY, beendet), wird nicht wiederholt.
+type RetryPolicy struct {
+	// Maximale Anzahl an Versuchen einschließlich des ersten Versuchs, bei 1 oder weniger wird nicht wiederholt.
+	MaxAttempts int
+
+	// Wartezeit vor der ersten Wiederholung, ist der Wert 0 wird DefaultRetryInitialBackoff verwendet.
+	InitialBackoff time.Duration
+
+	// Maximale Wartezeit zwischen zwei Versuchen, ist der Wert 0 wird DefaultRetryMaxBackoff verwendet.
+	MaxBackoff time.Duration
+
+	// Faktor, um den die Wartezeit nach jedem Versuch wächst, ist der Wert 0 wird DefaultRetryMultiplier verwendet.
+	Multiplier float64
+
+	// Fehler, bei denen der Aufruf wiederholt wird. Die Fehler werden über errors.Is verglichen, Fehler der
+	// Gegenseite daher über ihren übertragenen Code (siehe RpcError). Ist die Liste leer, wird nicht wiederholt.
+	RetryableErrors []error
+}
+
+// Schlüssel, unter welchen die Wiederholungsrichtlinie und der Idempotency Key eines Aufrufs im Context gespeichert werden
+type retryPolicyKey struct{}
+type idempotencyKeyKey struct{}
+
+// ContextWithRetryPolicy gibt einen Context zurück, dessen Wiederholungsrichtlinie bei einem Aufruf von
+// CallFunctionContext verwendet wird. Die Richtlinie hat Vorrang vor der Konfiguration der Verbindung,
+// mit nil werden Wiederholungen für den Aufruf deaktiviert.
+func ContextWithRetryPolicy(ctx context.Context, policy *RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// ContextWithIdempotencyKey gibt einen Context zurück, dessen Aufrufe von CallFunctionContext mit dem übergebenen
+// Idempotency Key gesendet werden. Die Gegenseite führt Aufrufe derselben Funktion mit demselben Schlüssel
+// innerhalb der IdempotencyTTL nur einmal erfolgreich aus. Ohne Schlüssel wird für Aufrufe mit Wiederholungen ein Schlüssel erzeugt.
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey{}, key)
+}
+
+// idempotencyKeyFromContext gibt den mittels ContextWithIdempotencyKey gesetzten Schlüssel zurück.
+func idempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyKey{}).(string)
+	return key
+}
+
+// validateRetryPolicy prüft ob die Wiederholungsrichtlinie verwendet werden kann.
+func validateRetryPolicy(policy *RetryPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	if policy.MaxAttempts < 0 || policy.InitialBackoff < 0 || policy.MaxBackoff < 0 {
+		return ErrInvalidRetryPolicy
+	}
+	if policy.Multiplier != 0 && policy.Multiplier < 1 {
+		return ErrInvalidRetryPolicy
+	}
+	return nil
+}
+
+// lookupRetryPolicy gibt die Wiederholungsrichtlinie eines Aufrufs zurück.
+// Die Richtlinie aus dem Context hat Vorrang vor der Richtlinie der Funktion und der Standardrichtlinie der Verbindung.
+//
+// Parameter:
+//   - s *BngConn: Ein Zeiger auf das BngConn-Objekt, über das der Aufruf gesendet wird.
+//   - ctx context.Context: Der Context des Aufrufs.
+//   - method string: Der Name der aufgerufenen Funktion.
+//
+// Rückgabe:
+//   - *RetryPolicy: Die Richtlinie, nil wenn der Aufruf nicht wiederholt werden soll.
+func lookupRetryPolicy(s *BngConn, ctx context.Context, method string) *RetryPolicy {
+	if policy, ok := ctx.Value(retryPolicyKey{}).(*RetryPolicy); ok {
+		return policy
+	}
+	if policy, found := s.config.RetryPolicies[method]; found {
+		return policy
+	}
+	return s.config.DefaultRetryPolicy
+}
+
+// isRetryableError gibt an ob ein Aufruf nach dem Fehler wiederholt werden soll.
+func isRetryableError(policy *RetryPolicy, err error) bool {
+	// Ein abgebrochener Aufruf wird nicht wiederholt
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	for _, target := range policy.RetryableErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryBackoff gibt die Wartezeit vor dem nächsten Versuch zurück.
+//
+// Parameter:
+//   - policy *RetryPolicy: Die Richtlinie des Aufrufs.
+//   - attempt int: Die Anzahl der bereits durchgeführten Versuche.
+//
+// Rückgabe:
+//   - time.Duration: Die Wartezeit.
+func retryBackoff(policy *RetryPolicy, attempt int) time.Duration {
+	backoff, maxBackoff, multiplier := policy.InitialBackoff, policy.MaxBackoff, policy.Multiplier
+	if backoff == 0 {
+		backoff = DefaultRetryInitialBackoff
+	}
+	if maxBackoff == 0 {
+		maxBackoff = DefaultRetryMaxBackoff
+	}
+	if multiplier == 0 {
+		multiplier = DefaultRetryMultiplier
+	}
+
+	// Die Wartezeit wächst mit jedem Versuch bis zur maximalen Wartezeit
+	wait := float64(backoff)
+	for i := 1; i < attempt && wait < float64(maxBackoff); i++ {
+		wait *= multiplier
+	}
+	return min(time.Duration(wait), maxBackoff)
+}
+
+// _CallFunctionWithRetry ruft eine Funktion der Gegenseite auf und wiederholt den Aufruf gemäß der Wiederholungsrichtlinie.
+// Alle Versuche werden mit demselben Idempotency Key gesendet.
+//
+// Parameter:
+//   - ctx context.Context: Der Context des Aufrufs.
+//   - s *BngConn: Ein Zeiger auf das BngConn-Objekt, über das der Aufruf gesendet wird.
+//   - nameorid string: Der Name der Funktion.
+//   - params []interface{}: Die Parameter des Aufrufs.
+//   - returnDataType []reflect.Type: Die erwarteten Rückgabetypen.
+//
+// Rückgabe:
+//   - []interface{}: Die Rückgabewerte des erfolgreichen Versuchs.
+//   - error: Der Fehler des letzten Versuchs, ansonsten nil.
+func _CallFunctionWithRetry(ctx context.Context, s *BngConn, nameorid string, params []interface{}, returnDataType []reflect.Type) ([]interface{}, error) {
+	// Ohne Richtlinie wird der Aufruf genau einmal ausgeführt
+	policy := lookupRetryPolicy(s, ctx, nameorid)
+	if policy == nil || policy.MaxAttempts <= 1 || len(policy.RetryableErrors) == 0 {
+		return _CallFunction(ctx, s, nameorid, params, returnDataType)
+	}
+	if err := validateRetryPolicy(policy); err != nil {
+		return nil, fmt.Errorf("bngsocket->_CallFunctionWithRetry[0]: %w", err)
+	}
+
+	// Alle Versuche verwenden denselben Idempotency Key
+	if idempotencyKeyFromContext(ctx) == "" {
+		ctx = ContextWithIdempotencyKey(ctx, strings.ReplaceAll(uuid.NewString(), "-", ""))
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, err := _CallFunction(ctx, s, nameorid, params, returnDataType)
+		if err == nil || attempt >= policy.MaxAttempts || !isRetryableError(policy, err) {
+			return result, err
+		}
+
+		// Nimmt die Verbindung keine neuen Aufrufe mehr an, kann eine Wiederholung nicht erfolgreich sein
+		if connectionIsClosed(s) || acceptsNewOperations(s) != nil {
+			return nil, err
+		}
+
+		// LOG
+		s.logger.Debug("Retry rpc function call", slog.String("method", nameorid), slog.Int("attempt", attempt), slog.String(logKeyError, err.Error()))
+
+		// Vor dem nächsten Versuch wird gewartet, ein abgebrochener Context beendet die Wiederholungen
+		timer := time.NewTimer(retryBackoff(policy, attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		}
+	}
+}
+
+// _IdempotencyCache speichert die Antworten von Aufrufen mit Idempotency Key.
+type _IdempotencyCache struct {
+	mu      sync.Mutex
+	entries map[string]*_IdempotencyEntry
+}
+
+// _IdempotencyEntry ist die Antwort eines Aufrufs mit Idempotency Key, done wird geschlossen sobald der Aufruf beendet ist.
+type _IdempotencyEntry struct {
+	done     chan struct{}
+	response *transport.RpcResponse // Die Antwort, nil wenn sie nicht zwischengespeichert werden darf
+	expires  time.Time
+}
+
+// newIdempotencyCache erzeugt einen leeren Cache.
+func newIdempotencyCache() *_IdempotencyCache {
+	return &_IdempotencyCache{entries: make(map[string]*_IdempotencyEntry)}
+}
+
+// begin gibt den Eintrag des Schlüssels zurück. Ist kein gültiger Eintrag vorhanden, wird ein neuer erzeugt
+// und der Aufrufer ist für die Ausführung verantwortlich.
+func (c *_IdempotencyCache) begin(key string) (entry *_IdempotencyEntry, owner bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, found := c.entries[key]; found && (entry.expires.IsZero() || time.Now().Before(entry.expires)) {
+		return entry, false
+	}
+	entry = &_IdempotencyEntry{done: make(chan struct{})}
+	c.entries[key] = entry
+	return entry, true
+}
+
+// finish speichert die Antwort und gibt wartende Aufrufe frei. Ohne Antwort wird der Eintrag sofort entfernt,
+// ansonsten nach Ablauf der ttl.
+func (c *_IdempotencyCache) finish(key string, entry *_IdempotencyEntry, response *transport.RpcResponse, ttl time.Duration) {
+	c.mu.Lock()
+	entry.response = response
+	if response == nil {
+		delete(c.entries, key)
+	} else {
+		entry.expires = time.Now().Add(ttl)
+	}
+	c.mu.Unlock()
+	close(entry.done)
+
+	// Der Eintrag wird nach Ablauf der ttl entfernt, sofern er nicht bereits ersetzt wurde
+	if response != nil {
+		time.AfterFunc(ttl, func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if c.entries[key] == entry {
+				delete(c.entries, key)
+			}
+		})
+	}
+}
+
+// isCacheableRpcResponse gibt an ob eine Antwort für Wiederholungen zwischengespeichert werden darf.
+// Fehlerantworten sowie Antworten mit Callbacks oder Channeln werden nicht zwischengespeichert,
+// da deren IDs nur für den ursprünglichen Aufruf gültig sind.
+func isCacheableRpcResponse(response *transport.RpcResponse) bool {
+	if response.Error != "" {
+		return false
+	}
+	for _, value := range response.Return {
+		if value != nil && (value.Type == "func" || value.Type == "channel") {
+			return false
+		}
+	}
+	return true
+}
+
+// executeIdempotentRpcRequest führt einen Aufruf mit Idempotency Key innerhalb der IdempotencyTTL höchstens einmal erfolgreich aus.
+// Wiederholungen erhalten die zwischengespeicherte Antwort, gleichzeitige Wiederholungen warten auf den laufenden Aufruf.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - rpcReq *transport.RpcRequest: Die eingegangene Anfrage.
+//
+// Rückgabe:
+//   - *transport.RpcResponse: Die Antwort, welche an den Aufrufer gesendet wird.
+//   - error: Ein Fehler bei der Verarbeitung, ansonsten nil.
+func executeIdempotentRpcRequest(o *BngConn, rpcReq *transport.RpcRequest) (*transport.RpcResponse, error) {
+	// Der Schlüssel gilt nur für die jeweilige Funktion
+	key := rpcReq.Name + "\x00" + rpcReq.IdempotencyKey
+
+	for {
+		entry, owner := o.idempotency.begin(key)
+		if owner {
+			response, err := invokeRpcRequest(o, rpcReq)
+
+			// Nur erfolgreiche Aufrufe werden zwischengespeichert, fehlgeschlagene Aufrufe werden bei einer Wiederholung erneut ausgeführt
+			cached := response
+			if err != nil || !isCacheableRpcResponse(response) {
+				cached = nil
+			}
+			o.idempotency.finish(key, entry, cached, o.config.IdempotencyTTL)
+			return response, err
+		}
+
+		// Es wird auf den laufenden Aufruf gewartet
+		select {
+		case <-entry.done:
+		case <-connEnded(o):
+			return newRpcErrorResponse(rpcReq.Id, ErrPeerGoingAway, nil), nil
+		}
+
+		// Konnte die Antwort nicht zwischengespeichert werden, wird der Aufruf ausgeführt
+		if entry.response == nil {
+			continue
+		}
+
+		// LOG
+		o.logger.Debug("Return cached response for repeated rpc call", slog.String(logKeyRpcId, rpcReq.Id))
+
+		// Die zwischengespeicherte Antwort wird mit der ID der Wiederholung zurückgesendet
+		response := *entry.response
+		response.Id = rpcReq.Id
+		return &response, nil
+	}
+}
@@ -0,0 +1,50 @@
+package bngsocket
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/custodia-cenv/bngsocket-go/transport"
+)
+
+func TestRpcErrorCodes(t *testing.T) {
+	// Eigene Fehler werden über ihren Code zugeordnet
+	busy := NewRpcError("busy", "peer busy")
+	response := newRpcErrorResponse("id", busy, nil)
+	if response.Code != "busy" {
+		t.Fatalf("unexpected code %q", response.Code)
+	}
+	err := processRpcError(response.Code, "changed message")
+	if !errors.Is(err, busy) || err.Error() != "changed message" {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// Fehler dieses Pakets werden über ihren Code wiederhergestellt
+	response = newRpcErrorResponse("id", ErrPeerGoingAway, nil)
+	if err := processRpcError(response.Code, response.Error); !errors.Is(err, ErrPeerGoingAway) {
+		t.Fatalf("expected ErrPeerGoingAway, got %v", err)
+	}
+
+	// Ohne Code wird die Fehlermeldung nicht als Code verwendet
+	if err := processRpcError("", "peer busy"); errors.Is(err, busy) {
+		t.Fatal("error without code must not match by message")
+	}
+}
+
+func TestIsCacheableRpcResponse(t *testing.T) {
+	cases := []struct {
+		name      string
+		response  *transport.RpcResponse
+		cacheable bool
+	}{
+		{"value", &transport.RpcResponse{Return: []*transport.RpcDataCapsle{{Type: "string", Value: "ok"}}}, true},
+		{"error", &transport.RpcResponse{Error: "failed"}, false},
+		{"callback", &transport.RpcResponse{Return: []*transport.RpcDataCapsle{{Type: "func", Value: "_bng.cb.1"}}}, false},
+		{"channel", &transport.RpcResponse{Return: []*transport.RpcDataCapsle{{Type: "channel", Value: "session"}}}, false},
+	}
+	for _, c := range cases {
+		if got := isCacheableRpcResponse(c.response); got != c.cacheable {
+			t.Errorf("%s: isCacheableRpcResponse = %v, want %v", c.name, got, c.cacheable)
+		}
+	}
+}
@@ -1,112 +1,276 @@
 package bngsocket
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/custodia-cenv/bngsocket-go/transport"
 	"github.com/google/uuid"
-	"github.com/vmihailenco/msgpack/v5"
 )
 
 // Wird verwendet um RPC Anfragen zu verarbeiten
 func processRpcRequest(o *BngConn, rpcReq *transport.RpcRequest) error {
+	// Stream Aufrufe werden über den Channel des Aufrufs ausgeführt
+	if rpcReq.Stream && !o.draining.Get() {
+		if fn, found := loadRpcFunction(o, rpcReq.Name); found && isStreamFunction(fn.Type()) {
+			return processRpcStreamRequest(o, rpcReq, fn)
+		}
+	}
+
+	// Der Aufruf wird ausgeführt
+	response, err := executeRpcRequest(o, rpcReq)
+	if err != nil {
+		return fmt.Errorf("bngsocket->processRpcRequest[0]: " + err.Error())
+	}
+
+	// Die Antwort wird zurückgesendet
+	if err := convertAndWriteBytesIntoChan(o, response); err != nil {
+		return fmt.Errorf("bngsocket->processRpcRequest[1]: " + err.Error())
+	}
+
+	// Die Antwort wurde erfolgreich zurückgewsendet
+	return nil
+}
+
+// executeRpcRequest führt einen Einzelaufruf aus und erzeugt die Antwort für den Aufrufer.
+// Aufrufe mit Idempotency Key werden innerhalb der IdempotencyTTL nur einmal ausgeführt.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - rpcReq *transport.RpcRequest: Die eingegangene Anfrage.
+//
+// Rückgabe:
+//   - *transport.RpcResponse: Die Antwort, welche an den Aufrufer gesendet wird.
+//   - error: Ein Fehler bei der Verarbeitung (Parameter oder Rückgabewerte ungültig, Panic), ansonsten nil.
+func executeRpcRequest(o *BngConn, rpcReq *transport.RpcRequest) (*transport.RpcResponse, error) {
+	if rpcReq.IdempotencyKey != "" {
+		return executeIdempotentRpcRequest(o, rpcReq)
+	}
+	return invokeRpcRequest(o, rpcReq)
+}
+
+// invokeRpcRequest führt die aufgerufene Funktion aus und erzeugt die Antwort für den Aufrufer.
+// Fehler der Funktion sowie abgelehnte Aufrufe werden als Fehlerantwort zurückgegeben.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - rpcReq *transport.RpcRequest: Die eingegangene Anfrage.
+//
+// Rückgabe:
+//   - *transport.RpcResponse: Die Antwort, welche an den Aufrufer gesendet wird.
+//   - error: Ein Fehler bei der Verarbeitung (Parameter oder Rückgabewerte ungültig, Panic), ansonsten nil.
+func invokeRpcRequest(o *BngConn, rpcReq *transport.RpcRequest) (_ *transport.RpcResponse, err error) {
+	// Wird die Verbindung heruntergefahren, werden keine neuen Aufrufe mehr angenommen
+	if o.draining.Get() {
+		return newRpcErrorResponse(rpcReq.Id, ErrPeerGoingAway, nil), nil
+	}
+
 	// Es wird geprüft ob die gesuchte Zielfunktion vorhanden ist
-	fn, found := o.functions.Load(rpcReq.Name)
+	fn, found := loadRpcFunction(o, rpcReq.Name)
 	if !found {
-		if err := socketWriteRpcErrorResponse(o, ErrUnkownRpcFunction.Error(), rpcReq.Id); err != nil {
-			return fmt.Errorf("bngsocket->processRpcRequest: " + err.Error())
-		}
-		return nil
+		return newRpcErrorResponse(rpcReq.Id, ErrUnkownRpcFunction, nil), nil
+	}
+
+	// Es wird geprüft ob die Art des Aufrufs (Stream oder Einzelaufruf) zur Funktion passt
+	if rpcReq.Stream || isStreamFunction(fn.Type()) {
+		return newRpcErrorResponse(rpcReq.Id, ErrRpcStreamMismatch, nil), nil
+	}
+
+	// Die erwarteten Rückgabetypen des Aufrufers werden vor dem Aufruf mit der Funktion verglichen
+	if err := checkRpcReturnSignature(fn.Type(), rpcReq.ReturnDTypes); err != nil {
+		return newRpcErrorResponse(rpcReq.Id, err, nil), nil
 	}
 
 	// LOG
-	_DebugPrint(fmt.Sprintf("BngConn(%s): Enter incomming rpc function call %s", o._innerhid, rpcReq.Id))
+	o.logger.Debug("Enter incomming rpc function call", slog.String(logKeyRpcId, rpcReq.Id))
+
+	// Der Trace Context des Aufrufers wird übernommen und ein Span für die Ausführung erzeugt
+	traceCtx := o.propagator.Extract(context.Background(), rpcReq.Metadata)
+	traceCtx, span := o.tracer.Start(traceCtx, rpcMethodLabel(rpcReq.Name), SpanKindServer)
+	span.SetAttribute(logKeyRpcId, rpcReq.Id)
+
+	// Die Dauer des Aufrufs wird erfasst und der Span beendet, ein Fehler der Funktion hat Vorrang vor einem Verarbeitungsfehler
+	start := time.Now()
+	var callErr error
+	defer func() {
+		if callErr == nil {
+			callErr = err
+		}
+		if callErr != nil {
+			span.RecordError(callErr)
+		}
+		span.End()
+		o.metrics.RpcRequestServed(rpcMethodLabel(rpcReq.Name), time.Since(start), callErr)
+	}()
+
+	// Der Context wird mit der Deadline des Aufrufers versehen und beim Beenden der Verbindung abgebrochen
+	requestCtx, cancel := newRpcRequestContext(o, withHandlerOperations(traceCtx, o, 0), rpcReq.Timeout)
+	defer cancel()
 
 	// Context erstellen und an die Funktion übergeben
-	ctx := &BngRequest{Conn: o}
+	ctx := &BngRequest{
+		Conn:     o,
+		ctx:      requestCtx,
+		id:       rpcReq.Id,
+		method:   rpcReq.Name,
+		metadata: rpcReq.Metadata,
+	}
 
-	// Es wird versucht die Akommenden Funktionsargumente in den Richtigen Datentypen zu unterteilen
-	in, err := convertRPCCallParameterBackToGoValues(fn, ctx, rpcReq.Params...)
+	// Die Funktion wird über alle Interceptoren ausgeführt
+	values, callErr, err := callUnaryRpcFunction(o, ctx, fn, rpcReq.Params)
 	if err != nil {
-		return fmt.Errorf("processRpcRequest[1]: " + err.Error())
+		return nil, fmt.Errorf("invokeRpcRequest[0]: " + err.Error())
 	}
 
-	// Methode PANIC Sicher ausführen ausführen
-	results, err := func() (results []reflect.Value, err error) {
-		// Defer a function to recover from panic
-		defer func() {
-			if r := recover(); r != nil {
-				err = fmt.Errorf("bngsocket->processRpcRequest[2]: panic occurred: %v", r)
-				results = nil
-			}
-		}()
+	// Hat die Funktion einen Fehler zurückgegeben, wird dieser an den Aufrufer gesendet
+	if callErr != nil {
+		return newRpcErrorResponse(rpcReq.Id, callErr, ctx.trailerMetadata()), nil
+	}
 
-		// Die Funktion wird mittels Reflection aufgerufen
-		results = fn.Call(in)
+	// Zurückgegebene Channel müssen zur Verbindung gehören und geöffnet sein
+	if err := checkChannelValues(o, values); err != nil {
+		return newRpcErrorResponse(rpcReq.Id, err, ctx.trailerMetadata()), nil
+	}
 
-		// Das Ergebniss wird zurückgegeben
-		return results, nil
-	}()
+	// Die Daten werden für den Transport vorbereitet
+	preparedValues, err := processRpcGoDataTypeTransportable(messageCodec(o), values...)
 	if err != nil {
-		return fmt.Errorf("bngsocket->processRpcRequest[3]: " + err.Error())
+		return nil, fmt.Errorf("invokeRpcRequest[1]: " + err.Error())
 	}
 
-	// Es muss mindestens 1 Eintrag vorhanden sein,
-	if len(results) < 1 {
-		return fmt.Errorf("return need more the zero values")
+	// Rückgabewerte, deren Typ erst zur Laufzeit feststeht (interface{}), werden nach dem Aufruf geprüft
+	if err := checkRpcReturnValues(preparedValues, rpcReq.ReturnDTypes); err != nil {
+		return newRpcErrorResponse(rpcReq.Id, err, ctx.trailerMetadata()), nil
 	}
 
-	// Der Letzte Eintrag muss ein Error sein
-	lasteElementOnResultsArray := results[len(results)-1]
-	if lasteElementOnResultsArray.Type().Implements(reflect.TypeOf((*error)(nil)).Elem()) {
-		// Nun prüfe, ob der Fehler tatsächlich nil ist oder nicht
-		if !lasteElementOnResultsArray.IsNil() {
-			// Der Fehler wird zurückgesendet
-			if err := socketWriteRpcErrorResponse(o, lasteElementOnResultsArray.String(), rpcReq.Id); err != nil {
-				return fmt.Errorf("bngsocket->processRpcRequest: " + err.Error())
-			}
-		}
+	// LOG
+	o.logger.Debug("Return data for rpc call", slog.String(logKeyRpcId, rpcReq.Id))
+
+	return &transport.RpcResponse{
+		Type:     "rpcres",
+		Id:       rpcReq.Id,
+		Return:   preparedValues,
+		Metadata: ctx.trailerMetadata(),
+	}, nil
+}
+
+// newRpcErrorResponse erzeugt eine Fehlerantwort für einen RPC Aufruf, neben der Fehlermeldung wird der Code des Fehlers übertragen.
+func newRpcErrorResponse(id string, err error, trailer map[string]string) *transport.RpcResponse {
+	return &transport.RpcResponse{
+		Type:     "rpcres",
+		Id:       id,
+		Error:    err.Error(),
+		Code:     rpcErrorCode(err),
+		Metadata: trailer,
 	}
+}
 
-	// Die Rückgabewerte werden nacheinander abgearbeitet
-	// der Letzte Eintrag im Results Array wird ausgelassen.
-	values := make([]interface{}, 0)
-	for i := range len(results) - 1 {
-		values = append(values, results[i].Interface())
+// checkRpcReturnSignature vergleicht die vom Aufrufer erwarteten Rückgabetypen mit den Rückgabewerten der Funktion.
+// Ausnahme: Der Transportdatentyp eines Rückgabewertes vom Typ interface{} steht erst nach dem Aufruf fest,
+// er wird daher über checkRpcReturnValues geprüft, nachdem die Funktion ausgeführt wurde. Eine Abweichung
+// wird dem Aufrufer in diesem Fall erst nach etwaigen Seiteneffekten der Funktion gemeldet.
+//
+// Parameter:
+//   - fnType reflect.Type: Der Typ der aufgerufenen Funktion.
+//   - returnDTypes []string: Die vom Aufrufer erwarteten Transportdatentypen.
+//
+// Rückgabe:
+//   - error: ErrSignatureMismatch mit der Beschreibung der Abweichung, ansonsten nil.
+func checkRpcReturnSignature(fnType reflect.Type, returnDTypes []string) error {
+	// Der abschließende Fehler wird nicht übertragen
+	outCount := fnType.NumOut()
+	if outCount > 0 && isErrorType(fnType.Out(outCount-1)) {
+		outCount--
 	}
 
-	// Die Daten werden für den Transport vorbereitet
-	preparedValues, err := processRpcGoDataTypeTransportable(values...)
-	if err != nil {
-		return fmt.Errorf("processRpcRequest: " + err.Error())
+	// Die Anzahl der Rückgabewerte muss übereinstimmen
+	if outCount != len(returnDTypes) {
+		return fmt.Errorf("%w: function returns %d values, caller expects %d", ErrSignatureMismatch, outCount, len(returnDTypes))
 	}
 
-	// Es müssen genausoviele Rückgabewerte wie angefordert vorhanden sein
-	if len(preparedValues) != len(rpcReq.ReturnDTypes) {
-		fmt.Println(rpcReq.ReturnDTypes, preparedValues)
-		return fmt.Errorf("processRpcRequest: invalid function signature")
+	// Die Transportdatentypen müssen übereinstimmen
+	for i := 0; i < outCount; i++ {
+		if fnType.Out(i).Kind() == reflect.Interface {
+			continue
+		}
+		dataType, err := rpcTransportDatatype(fnType.Out(i))
+		if err != nil {
+			return fmt.Errorf("%w: return value %d: %s", ErrSignatureMismatch, i, err.Error())
+		}
+		if dataType != returnDTypes[i] {
+			return fmt.Errorf("%w: return value %d is %s, caller expects %s", ErrSignatureMismatch, i, dataType, returnDTypes[i])
+		}
 	}
 
-	// Es wird geprüft ob die R+ckgabedaten mit den Erwarteten Datentypen übereinstimmt
-	for s, item := range preparedValues {
-		if item.Type != rpcReq.ReturnDTypes[s] {
-			return fmt.Errorf("processRpcRequest: returntype not correct, invalid function signautre")
+	return nil
+}
+
+// checkRpcReturnValues vergleicht die aufbereiteten Rückgabewerte mit den vom Aufrufer erwarteten Transportdatentypen.
+// Ein leerer Zeiger (null-struct) entspricht dem erwarteten Struct.
+func checkRpcReturnValues(values []*transport.RpcDataCapsle, returnDTypes []string) error {
+	if len(values) != len(returnDTypes) {
+		return fmt.Errorf("%w: function returned %d values, caller expects %d", ErrSignatureMismatch, len(values), len(returnDTypes))
+	}
+	for i, item := range values {
+		dataType := item.Type
+		if strings.HasPrefix(dataType, "null-struct:") {
+			dataType = "struct:" + strings.TrimPrefix(dataType, "null-struct:")
+		}
+		if dataType != returnDTypes[i] {
+			return fmt.Errorf("%w: return value %d is %s, caller expects %s", ErrSignatureMismatch, i, item.Type, returnDTypes[i])
 		}
 	}
+	return nil
+}
 
-	// LOG
-	_DebugPrint(fmt.Sprintf("BngConn(%s): Return data for rpc call %s", o._innerhid, rpcReq.Id))
+// callUnaryRpcFunction wandelt die übertragenen Parameter um und führt die Funktion über alle
+// Server Interceptoren PANIC Sicher aus.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - req *BngRequest: Die Anfrage, welche an die Funktion übergeben wird.
+//   - fn reflect.Value: Die registrierte Funktion.
+//   - params []*transport.RpcDataCapsle: Die übertragenen Parameter.
+//
+// Rückgabe:
+//   - []interface{}: Die Rückgabewerte der Funktion ohne den abschließenden Fehler.
+//   - error: Der Fehler, welchen die Funktion zurückgegeben hat.
+//   - error: Ein Fehler bei der Verarbeitung (Parameter ungültig, Panic), ansonsten nil.
+func callUnaryRpcFunction(o *BngConn, req *BngRequest, fn reflect.Value, params []*transport.RpcDataCapsle) (values []interface{}, callErr error, err error) {
+	// Es wird versucht die Akommenden Funktionsargumente in den Richtigen Datentypen zu unterteilen
+	in, err := convertRPCCallParameterBackToGoValues(fn, req, params...)
+	if err != nil {
+		// Ungültige Parameter werden dem Aufrufer als Fehler zurückgegeben
+		if errors.Is(err, ErrInvalidParameter) {
+			return nil, err, nil
+		}
+		return nil, nil, fmt.Errorf("callUnaryRpcFunction[0]: " + err.Error())
+	}
 
-	// Die Antwort wird zurückgesendet
-	if err := socketWriteRpcSuccessResponse(o, preparedValues, rpcReq.Id); err != nil {
-		return fmt.Errorf("processRpcRequest: " + err.Error())
+	// Die Argumente werden ohne den BngRequest an die Interceptoren übergeben
+	args := make([]interface{}, 0, len(in)-1)
+	for _, value := range in[1:] {
+		args = append(args, value.Interface())
 	}
 
-	// Die Antwort wurde erfolgreich zurückgewsendet
-	return nil
+	// Die Funktion wird über alle Interceptoren PANIC Sicher ausgeführt
+	handler := chainUnaryServerInterceptors(o.config.ServerInterceptors, req.method, newReflectUnaryHandler(fn))
+	defer func() {
+		if r := recover(); r != nil {
+			values, callErr = nil, nil
+			err = fmt.Errorf("callUnaryRpcFunction[1]: panic occurred: %v", r)
+		}
+	}()
+
+	// Die Funktion wird aufgerufen, ein Fehler der Funktion wird zurückgegeben
+	values, callErr = handler(req, args)
+	return values, callErr, nil
 }
 
 // Wird verwendet um ein RPC Response entgegenzunehmen
@@ -145,6 +309,11 @@ func _RegisterFunction(s *BngConn, nameorid string, fn interface{}) error {
 		return io.EOF
 	}
 
+	// Reservierte Namen sind den eingebauten Funktionen vorbehalten
+	if isReservedFunctionName(nameorid) {
+		return fmt.Errorf("bngsocket->_RegisterFunction[1]: %w: %s", ErrReservedFunctionName, nameorid)
+	}
+
 	// Die RPC Funktion wird validiert
 	fnValue := reflect.ValueOf(fn)
 	fnType := fnValue.Type()
@@ -168,20 +337,252 @@ func _RegisterFunction(s *BngConn, nameorid string, fn interface{}) error {
 	return nil
 }
 
+// Entfernt eine registrierte Funktion, laufende Aufrufe der Funktion werden normal beendet
+func _UnregisterFunction(s *BngConn, nameorid string) error {
+	// Es wird geprüft ob die Verbindung getrennt wurde
+	if connectionIsClosed(s) {
+		return io.EOF
+	}
+
+	// Eingebaute Funktionen können nicht entfernt werden
+	if isReservedFunctionName(nameorid) {
+		return fmt.Errorf("bngsocket->_UnregisterFunction[0]: %w: %s", ErrReservedFunctionName, nameorid)
+	}
+
+	// Der connMutextex wird angewendet
+	s.connMutex.Lock()
+	defer s.connMutex.Unlock()
+
+	// Die Funktion wird entfernt, laufende Aufrufe verwenden weiterhin die zuvor geladene Funktion
+	if _, found := s.functions.LoadAndDelete(nameorid); !found {
+		return fmt.Errorf("bngsocket->_UnregisterFunction[1]: %w: %s", ErrFunctionNotRegistered, nameorid)
+	}
+
+	// Rückgabe
+	return nil
+}
+
+// Ersetzt eine registrierte Funktion oder registriert diese, sofern sie noch nicht vorhanden ist.
+// Laufende Aufrufe der bisherigen Funktion werden normal beendet, neue Aufrufe verwenden die neue Funktion.
+func _ReplaceFunction(s *BngConn, nameorid string, fn interface{}) error {
+	// Es wird geprüft ob die Verbindung getrennt wurde
+	if connectionIsClosed(s) {
+		return io.EOF
+	}
+
+	// Reservierte Namen sind den eingebauten Funktionen vorbehalten
+	if isReservedFunctionName(nameorid) {
+		return fmt.Errorf("bngsocket->_ReplaceFunction[0]: %w: %s", ErrReservedFunctionName, nameorid)
+	}
+
+	// Die RPC Funktion wird validiert
+	fnValue := reflect.ValueOf(fn)
+	if err := validateRPCFunction(fnValue, fnValue.Type(), true); err != nil {
+		return fmt.Errorf("bngsocket->_ReplaceFunction[1]: " + err.Error())
+	}
+
+	// Der connMutextex wird angewendet
+	s.connMutex.Lock()
+	defer s.connMutex.Unlock()
+
+	// Die Funktion wird geschrieben
+	s.functions.Store(nameorid, fnValue)
+
+	// Rückgabe
+	return nil
+}
+
+// Registriert alle exportierten Methoden eines Objektes unter dem Namen "service.Methode".
+// Im Gegensatz zu _RegisterReceiver muss jede exportierte Methode eine gültige RPC Funktion sein,
+// ansonsten wird keine der Methoden registriert.
+func _RegisterService(s *BngConn, service string, obj interface{}) error {
+	// Es wird geprüft ob die Verbindung getrennt wurde
+	if connectionIsClosed(s) {
+		return io.EOF
+	}
+
+	// Der Name des Services wird geprüft
+	if service == "" || obj == nil {
+		return fmt.Errorf("bngsocket->_RegisterService[0]: service name and object required")
+	}
+	if isReservedFunctionName(service + ".") {
+		return fmt.Errorf("bngsocket->_RegisterService[1]: %w: %s", ErrReservedFunctionName, service)
+	}
+
+	// Es werden alle exportierten Methoden validiert
+	methods, err := collectRpcMethods(service, obj, false)
+	if err != nil {
+		return fmt.Errorf("bngsocket->_RegisterService[2]: %w", err)
+	}
+
+	// Die Methoden werden registriert
+	if err := storeRpcFunctions(s, methods); err != nil {
+		return fmt.Errorf("bngsocket->_RegisterService[3]: %w", err)
+	}
+
+	// Rückgabe
+	return nil
+}
+
+// Registriert alle exportierten Methoden eines Objektes, deren erster Parameter ein *BngRequest und deren
+// letzter Rückgabewert ein error ist. Im Gegensatz zu _RegisterService werden andere Methoden übersprungen.
+// Alle ungültigen Methoden werden in einem gemeinsamen Fehler gemeldet, in diesem Fall wird keine der
+// Methoden registriert.
+func _RegisterReceiver(s *BngConn, prefix string, rcvr interface{}) error {
+	// Es wird geprüft ob die Verbindung getrennt wurde
+	if connectionIsClosed(s) {
+		return io.EOF
+	}
+
+	// Es muss ein Objekt übergeben werden, der Präfix darf nicht reserviert sein
+	if rcvr == nil {
+		return fmt.Errorf("bngsocket->_RegisterReceiver[0]: receiver required")
+	}
+	if isReservedFunctionName(prefix + ".") {
+		return fmt.Errorf("bngsocket->_RegisterReceiver[1]: %w: %s", ErrReservedFunctionName, prefix)
+	}
+
+	// Es werden alle exportierten Methoden mit passender Signatur validiert
+	methods, err := collectRpcMethods(prefix, rcvr, true)
+	if err != nil {
+		return fmt.Errorf("bngsocket->_RegisterReceiver[2]: %w", err)
+	}
+
+	// Die Methoden werden registriert
+	if err := storeRpcFunctions(s, methods); err != nil {
+		return fmt.Errorf("bngsocket->_RegisterReceiver[3]: %w", err)
+	}
+
+	// Rückgabe
+	return nil
+}
+
+// collectRpcMethods validiert die exportierten Methoden eines Objektes und gibt diese unter dem Namen
+// "prefix.Methode" (ohne Präfix nur "Methode") zurück. Alle ungültigen Methoden werden in einem
+// gemeinsamen Fehler gemeldet.
+//
+// Parameter:
+//   - prefix string: Der Namensraum, unter dem die Methoden registriert werden.
+//   - obj interface{}: Das Objekt, dessen Methoden geprüft werden.
+//   - skipUnsuitable bool: Gibt an ob Methoden ohne *BngRequest als ersten Parameter oder ohne
+//     abschließenden error übersprungen werden, anstatt als ungültig gemeldet zu werden.
+//
+// Rückgabe:
+//   - map[string]reflect.Value: Die validierten Methoden nach Namen.
+//   - error: Ein Fehler, falls Methoden ungültig sind oder keine Methode vorhanden ist, ansonsten nil.
+func collectRpcMethods(prefix string, obj interface{}, skipUnsuitable bool) (map[string]reflect.Value, error) {
+	requestType := reflect.TypeOf((*BngRequest)(nil))
+	objValue := reflect.ValueOf(obj)
+	objType := objValue.Type()
+	methods := make(map[string]reflect.Value, objType.NumMethod())
+	var errs []error
+	for i := 0; i < objType.NumMethod(); i++ {
+		method := objValue.Method(i)
+		methodType := method.Type()
+
+		// Methoden ohne *BngRequest als ersten Parameter oder ohne abschließenden error werden ggf. übersprungen
+		if skipUnsuitable {
+			if methodType.NumIn() < 1 || methodType.In(0) != requestType {
+				continue
+			}
+			if methodType.NumOut() < 1 || !isErrorType(methodType.Out(methodType.NumOut()-1)) {
+				continue
+			}
+		}
+
+		// Die Methode wird validiert, ungültige Methoden werden gesammelt
+		if err := validateRPCFunction(method, methodType, true); err != nil {
+			errs = append(errs, fmt.Errorf("method %s: %s", objType.Method(i).Name, err.Error()))
+			continue
+		}
+
+		// Der Name setzt sich aus dem Präfix und dem Methodennamen zusammen
+		name := objType.Method(i).Name
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		methods[name] = method
+	}
+
+	// Es wird geprüft ob ungültige Methoden vorhanden sind
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	// Es muss mindestens eine Methode vorhanden sein
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("type %s has no rpc methods", objType)
+	}
+
+	return methods, nil
+}
+
+// storeRpcFunctions registriert mehrere Funktionen gemeinsam.
+// Ist einer der Namen bereits vergeben, wird keine der Funktionen registriert.
+//
+// Parameter:
+//   - s *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - fns map[string]reflect.Value: Die bereits validierten Funktionen nach Namen.
+//
+// Rückgabe:
+//   - error: Ein Fehler für jeden bereits vergebenen Namen, ansonsten nil.
+func storeRpcFunctions(s *BngConn, fns map[string]reflect.Value) error {
+	// Der connMutextex wird angewendet
+	s.connMutex.Lock()
+	defer s.connMutex.Unlock()
+
+	// Es wird geprüft ob es bereits eine Funktion mit einem der Namen gibt
+	var errs []error
+	for name := range fns {
+		if _, found := s.functions.Load(name); found {
+			errs = append(errs, fmt.Errorf("function %s always registrated", name))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	// Die Funktionen werden geschrieben
+	for name, fn := range fns {
+		s.functions.Store(name, fn)
+	}
+
+	// Rückgabe
+	return nil
+}
+
 // Ruft eine Funktion auf der Gegenseite auf
-func _CallFunction(s *BngConn, nameorid string, params []interface{}, returnDataType []reflect.Type) ([]interface{}, error) {
+func _CallFunction(ctx context.Context, s *BngConn, nameorid string, params []interface{}, returnDataType []reflect.Type) (result []interface{}, err error) {
 	// Es wird geprüft ob die Verbindung getrennt wurde
 	if connectionIsClosed(s) {
 		return nil, io.EOF
 	}
 
-	// Es wird geprüft ob die Verwendeten Parameter Zulässigen Datentypen sind
-	if err := validateRpcParamsDatatypes(params...); err != nil {
+	// Es wird ein Span für den Aufruf erzeugt, die Dauer des Aufrufs wird erfasst
+	ctx, span := s.tracer.Start(ctx, rpcMethodLabel(nameorid), SpanKindClient)
+	start := time.Now()
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+		s.metrics.RpcCallFinished(rpcMethodLabel(nameorid), time.Since(start), err)
+	}()
+
+	// Es wird geprüft ob neue Aufrufe gestartet werden dürfen
+	if err := acceptsNewOperations(s); err != nil {
 		return nil, err
 	}
 
+	// Die Parameter werden geprüft, übergebene Funktionen werden bis zum Abschluss des Aufrufs als Callbacks registriert
+	params, hiddenIds, err := prepareRpcParams(s, params)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseHiddenFunctions(s, hiddenIds)
+
 	// Die Parameter werden umgewandelt
-	convertedParams, err := processRpcGoDataTypeTransportable(params...)
+	convertedParams, err := processRpcGoDataTypeTransportable(messageCodec(s), params...)
 	if err != nil {
 		return nil, fmt.Errorf("bngsocket->_CallFunction[0]: " + err.Error())
 	}
@@ -194,15 +595,19 @@ func _CallFunction(s *BngConn, nameorid string, params []interface{}, returnData
 
 	// Es wird ein RpcRequest Paket erstellt
 	rpcreq := &transport.RpcRequest{
-		Type:         "rpcreq",
-		Params:       convertedParams,
-		ReturnDTypes: returnDataTypes,
-		Name:         nameorid,
-		Id:           strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Type:           "rpcreq",
+		Params:         convertedParams,
+		ReturnDTypes:   returnDataTypes,
+		Name:           nameorid,
+		Id:             strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Metadata:       injectRpcMetadata(s, ctx),
+		Timeout:        rpcTimeout(ctx),
+		IdempotencyKey: idempotencyKeyFromContext(ctx),
 	}
+	span.SetAttribute(logKeyRpcId, rpcreq.Id)
 
 	// Das Paket wird in Bytes umgewandelt
-	bytedData, err := msgpack.Marshal(rpcreq)
+	bytedData, err := marshalMessage(s, rpcreq)
 	if err != nil {
 		return nil, fmt.Errorf("bngsocket->_CallFunction[1]: " + err.Error())
 	}
@@ -221,50 +626,118 @@ func _CallFunction(s *BngConn, nameorid string, params []interface{}, returnData
 		return nil, fmt.Errorf("bngsocket->_CallFunction: " + err.Error())
 	}
 
-	// Es wird auf die Antwort gewartet
-	response := <-responseChan
+	// Es wird auf die Antwort gewartet, wurde der Chan geschlossen, ist die Verbindung beendet worden
+	var response *transport.RpcResponse
+	var ok bool
+	select {
+	case response, ok = <-responseChan:
+		if !ok {
+			return nil, connectionTerminationError(s)
+		}
+	case <-ctx.Done():
+		// Die Sitzung bleibt bestehen, bis die Antwort der Gegenseite eingetroffen ist, diese wird verworfen
+		go discardRpcResponse(s, rpcreq.Id, responseChan)
+		return nil, ctx.Err()
+	}
 
 	// Es wird geprüft ob die Verbindung getrennt wurde
 	if connectionIsClosed(s) {
 		return nil, io.EOF
 	}
 
-	// Die Requestssitzung wird entfernt
-	s.openRpcRequests.Delete(rpcreq.Id)
+	// Der Trailer der Antwort wird an den Aufrufer übergeben
+	captureRpcTrailer(ctx, response.Metadata)
 
-	// Der Chan wird vollständig geschlossen
-	close(responseChan)
+	// Die Requestssitzung wird entfernt und der Chan vollständig geschlossen,
+	// sofern dies nicht bereits beim Beenden der Verbindung geschehen ist
+	if _, loaded := s.openRpcRequests.LoadAndDelete(rpcreq.Id); loaded {
+		close(responseChan)
+	}
+
+	// Die Antwort wird in Go Datentypen umgewandelt
+	return decodeRpcResponse(s, response, returnDataType)
+}
 
+// prepareRpcParams bereitet die Parameter eines ausgehenden Aufrufs für die Übertragung vor. Es wird geprüft
+// ob die Datentypen zulässig sind und ob die übergebenen Channel übertragen werden können, übergebene
+// Funktionen werden als versteckte Funktionen registriert und durch ihre ID ersetzt.
+//
+// Parameter:
+//   - s *BngConn: Ein Zeiger auf das BngConn-Objekt, über das der Aufruf gesendet wird.
+//   - params []interface{}: Die Parameter des Aufrufs.
+//
+// Rückgabe:
+//   - []interface{}: Die vorbereiteten Parameter.
+//   - []string: Die IDs der registrierten Funktionen, diese müssen nach dem Aufruf mit releaseHiddenFunctions freigegeben werden.
+//   - error: Ein Fehler, falls ein Parameter nicht übertragen werden kann, ansonsten nil.
+func prepareRpcParams(s *BngConn, params []interface{}) ([]interface{}, []string, error) {
+	// Es wird geprüft ob die Verwendeten Parameter Zulässigen Datentypen sind
+	if err := validateRpcParamsDatatypes(params...); err != nil {
+		return nil, nil, err
+	}
+
+	// Es wird geprüft ob die übergebenen Channel übertragen werden können
+	if err := checkChannelValues(s, params); err != nil {
+		return nil, nil, fmt.Errorf("bngsocket->prepareRpcParams[0]: %w", err)
+	}
+
+	// Übergebene Funktionen werden als Callbacks registriert
+	params, hiddenIds, err := registerHiddenFunctions(s, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bngsocket->prepareRpcParams[1]: %w", err)
+	}
+	return params, hiddenIds, nil
+}
+
+// decodeRpcResponse wandelt die Antwort eines RPC Aufrufs in Go Datentypen um.
+//
+// Parameter:
+//   - s *BngConn: Ein Zeiger auf das BngConn-Objekt, über das der Aufruf gesendet wurde.
+//   - response *transport.RpcResponse: Die empfangene Antwort.
+//   - returnDataType []reflect.Type: Die erwarteten Rückgabetypen.
+//
+// Rückgabe:
+//   - []interface{}: Die Rückgabewerte des Aufrufs.
+//   - error: Der Fehler der aufgerufenen Funktion oder ein Fehler bei der Umwandlung, ansonsten nil.
+func decodeRpcResponse(s *BngConn, response *transport.RpcResponse, returnDataType []reflect.Type) ([]interface{}, error) {
 	// Es wird geprüft ob ein Fehler vorhanden ist
 	if response.Error != "" {
-		// Es wird geprüft ob die Verbindung getrennt wurde
-		if connectionIsClosed(s) {
-			return nil, io.EOF
-		}
-
-		// Der Fehler wird zurückgegeben
-		return nil, processError(response.Error)
+		return nil, processRpcError(response.Code, response.Error)
 	}
 
 	// Es wird geprüft ob ein Rückgabewert vorhanden ist
-	if response.Return != nil {
-		// Es wird geprüft ob die Verbindung getrennt wurde
-		if connectionIsClosed(s) {
-			return nil, io.EOF
-		}
-
+	if len(response.Return) > 0 {
 		// Es wird geprüft ob die Funktion auf der Aufrufendenseite eine Rückgabe erwartet
 		if returnDataType == nil {
-			return nil, fmt.Errorf("bngsocket->_CallFunction[2]: wanted return, none, has return")
+			return nil, fmt.Errorf("bngsocket->decodeRpcResponse[0]: wanted return, none, has return")
+		}
+
+		// Die Gegenseite darf nicht mehr Werte zurückgeben als erwartet werden
+		if len(response.Return) > len(returnDataType) {
+			return nil, fmt.Errorf("bngsocket->decodeRpcResponse[4]: %w: peer returned %d values, expected %d", ErrSignatureMismatch, len(response.Return), len(returnDataType))
 		}
 
 		// Es werden alle Einträge abgearbeitet
 		returnValues := make([]interface{}, 0)
 		for i := range response.Return {
-			fmt.Println(response.Return[i])
+			// Channel werden über ihre Sitzung übernommen
+			if response.Return[i].Type == "channel" {
+				channel, err := resolveChannelValue(s, response.Return[i], returnDataType[i])
+				if err != nil {
+					return nil, fmt.Errorf("bngsocket->decodeRpcResponse[2]: " + err.Error())
+				}
+				returnValues = append(returnValues, channel)
+				continue
+			}
+
+			// Im Strict Modus muss der Rückgabewert exakt dem erwarteten Typ entsprechen
+			if err := checkStrictRpcType(s, response.Return[i], returnDataType[i]); err != nil {
+				return nil, fmt.Errorf("bngsocket->decodeRpcResponse[3]: %w", err)
+			}
+
 			value, err := processRPCCallResponseDataToGoDatatype(response.Return[i], returnDataType[i])
 			if err != nil {
-				return nil, fmt.Errorf("bngsocket->_CallFunction[3]: " + err.Error())
+				return nil, fmt.Errorf("bngsocket->decodeRpcResponse[1]: %w", err)
 			}
 			returnValues = append(returnValues, value)
 		}
@@ -276,3 +749,22 @@ func _CallFunction(s *BngConn, nameorid string, params []interface{}, returnData
 	// Es ist kein Fehler Aufgetreten, aber es sind auch keine Daten vorhanden
 	return nil, nil
 }
+
+// discardRpcResponse wartet auf die Antwort eines abgebrochenen RPC Aufrufs und verwirft diese.
+// Die Sitzung bleibt bis zum Eintreffen der Antwort bestehen, damit die Antwort nicht als unbekannt gilt.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - id string: Die ID des abgebrochenen Aufrufs.
+//   - responseChan chan *transport.RpcResponse: Der Chan, über welchen die Antwort eintrifft.
+func discardRpcResponse(o *BngConn, id string, responseChan chan *transport.RpcResponse) {
+	// Wurde der Chan geschlossen, ist die Verbindung beendet worden
+	if _, ok := <-responseChan; !ok {
+		return
+	}
+
+	// Die Sitzung wird entfernt
+	if _, loaded := o.openRpcRequests.LoadAndDelete(id); loaded {
+		close(responseChan)
+	}
+}
@@ -0,0 +1,265 @@
+package bngsocket
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/custodia-cenv/bngsocket-go/transport"
+)
+
+// Transportdatentyp für benannte Parameter
+const namedArgsType = "named"
+
+// NamedArgs übergibt Parameter über ihren Namen an einen Struct Parameter der aufgerufenen Funktion.
+// Die Namen entsprechen den rpc Tags (oder Feldnamen) des Structs, der Gegenseite unbekannte Namen werden ignoriert.
+// Nur Felder vom Typ Optional dürfen fehlen, fehlende Felder anderer Typen werden als ErrInvalidParameter gemeldet.
+// Dadurch können Optional Felder ergänzt werden, ohne bestehende Aufrufer anzupassen.
+//
+// Beispiel:
+//
+//	conn.CallFunction("createUser", []interface{}{bngsocket.NamedArgs{"name": "bob", "age": 42}}, nil)
+type NamedArgs map[string]interface{}
+
+// Optional kennzeichnet einen optionalen Parameter am Ende einer registrierten Funktion.
+// Übergibt der Aufrufer den Parameter nicht, bleibt Set false und Value enthält den Nullwert. Der Aufrufer
+// übergibt den Wert selbst (nicht Optional). Fehlende Parameter anderer Typen werden als ErrInvalidParameter gemeldet,
+// dadurch kann eine Funktion um Parameter ergänzt werden, ohne dass bestehende Aufrufer angepasst werden müssen.
+//
+// Beispiel:
+//
+//	conn.RegisterFunction("greet", func(req *bngsocket.BngRequest, name string, greeting bngsocket.Optional[string]) (string, error) {
+//		return greeting.Or("hello") + " " + name, nil
+//	})
+type Optional[T any] struct {
+	Value T    // Der übergebene Wert, der Nullwert wenn der Parameter nicht übergeben wurde
+	Set   bool // Gibt an ob der Parameter übergeben wurde
+}
+
+// Get gibt den Wert zurück und ob der Parameter übergeben wurde.
+func (o Optional[T]) Get() (T, bool) {
+	return o.Value, o.Set
+}
+
+// Or gibt den Wert zurück, wurde der Parameter nicht übergeben wird def zurückgegeben.
+func (o Optional[T]) Or(def T) T {
+	if !o.Set {
+		return def
+	}
+	return o.Value
+}
+
+// optionalValueType gibt den Typ des enthaltenen Wertes zurück.
+func (Optional[T]) optionalValueType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// _OptionalParameter wird von allen Optional Typen implementiert.
+type _OptionalParameter interface {
+	optionalValueType() reflect.Type
+}
+
+// Der Typ des Interfaces, über welches Optional Parameter erkannt werden
+var optionalParameterType = reflect.TypeFor[_OptionalParameter]()
+
+// optionalValueType gibt den Typ des Wertes eines Optional Parameters zurück.
+//
+// Parameter:
+//   - t reflect.Type: Der Typ des Parameters.
+//
+// Rückgabe:
+//   - reflect.Type: Der Typ des enthaltenen Wertes.
+//   - bool: Gibt an ob es sich um einen Optional Parameter handelt.
+func optionalValueType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Struct || !t.Implements(optionalParameterType) {
+		return nil, false
+	}
+	return reflect.Zero(t).Interface().(_OptionalParameter).optionalValueType(), true
+}
+
+// newOptionalValue erzeugt einen übergebenen Optional Parameter mit dem Wert.
+func newOptionalValue(t reflect.Type, value reflect.Value) reflect.Value {
+	result := reflect.New(t).Elem()
+	result.Field(0).Set(value)
+	result.Field(1).SetBool(true)
+	return result
+}
+
+// expectedRpcParameterType gibt den erwarteten Typ eines übertragenen Parameters zurück.
+// Bei variadischen Funktionen werden überzählige Parameter als Elemente des variadischen Parameters eingelesen.
+//
+// Parameter:
+//   - fnType reflect.Type: Der Typ der aufgerufenen Funktion.
+//   - beginAt int: Die Position des ersten übertragenen Parameters in der Funktion.
+//   - index int: Die Position des übertragenen Parameters.
+//
+// Rückgabe:
+//   - reflect.Type: Der erwartete Typ.
+//   - error: ErrInvalidParameter, falls die Funktion weniger Parameter entgegennimmt, ansonsten nil.
+func expectedRpcParameterType(fnType reflect.Type, beginAt int, index int) (reflect.Type, error) {
+	fixed := fnType.NumIn() - beginAt
+	if fnType.IsVariadic() {
+		fixed--
+	}
+
+	switch {
+	case index < fixed:
+		return fnType.In(beginAt + index), nil
+	case fnType.IsVariadic():
+		return fnType.In(fnType.NumIn() - 1).Elem(), nil
+	default:
+		return nil, fmt.Errorf("%w: transmitted function call has too many parameters, wanted at most %d, have %d", ErrInvalidParameter, fixed, index+1)
+	}
+}
+
+// completeRpcArguments ergänzt fehlende Optional Parameter am Ende und fasst die
+// Elemente eines variadischen Parameters zu einem Slice zusammen.
+//
+// Parameter:
+//   - fnType reflect.Type: Der Typ der aufgerufenen Funktion.
+//   - beginAt int: Die Position des ersten übertragenen Parameters in der Funktion.
+//   - values []reflect.Value: Die eingelesenen Parameter.
+//
+// Rückgabe:
+//   - []reflect.Value: Genau ein Wert für jeden Parameter der Funktion ab beginAt.
+//   - error: ErrInvalidParameter, falls ein Parameter fehlt, welcher nicht optional ist, ansonsten nil.
+func completeRpcArguments(fnType reflect.Type, beginAt int, values []reflect.Value) ([]reflect.Value, error) {
+	fixed := fnType.NumIn() - beginAt
+	if fnType.IsVariadic() {
+		fixed--
+	}
+
+	// Die festen Parameter werden übernommen, nur Optional Parameter dürfen fehlen
+	args := make([]reflect.Value, 0, fnType.NumIn()-beginAt)
+	args = append(args, values[:min(len(values), fixed)]...)
+	for i := len(args); i < fixed; i++ {
+		paramType := fnType.In(beginAt + i)
+		if _, ok := optionalValueType(paramType); !ok {
+			return nil, fmt.Errorf("%w: parameter %d (%s) is missing", ErrInvalidParameter, i, paramType)
+		}
+		args = append(args, reflect.Zero(paramType))
+	}
+
+	// Die überzähligen Parameter bilden den variadischen Parameter
+	if fnType.IsVariadic() {
+		variadic := reflect.MakeSlice(fnType.In(fnType.NumIn()-1), 0, max(len(values)-fixed, 0))
+		if len(values) > fixed {
+			variadic = reflect.Append(variadic, values[fixed:]...)
+		}
+		args = append(args, variadic)
+	}
+
+	return args, nil
+}
+
+// callRpcFunctionValue ruft eine Funktion mit genau einem Wert je Parameter auf, der variadische Parameter wird als Slice übergeben.
+func callRpcFunctionValue(fn reflect.Value, in []reflect.Value) []reflect.Value {
+	if fn.Type().IsVariadic() {
+		return fn.CallSlice(in)
+	}
+	return fn.Call(in)
+}
+
+// encodeNamedArgs wandelt benannte Parameter in ein RpcDataCapsle um.
+// Die einzelnen Werte werden wie Parameter umgewandelt und gemeinsam mit dem Codec kodiert.
+//
+// Parameter:
+//   - codec Codec: Der Codec der Verbindung.
+//   - args NamedArgs: Die benannten Parameter.
+//
+// Rückgabe:
+//   - *transport.RpcDataCapsle: Die kodierten Parameter.
+//   - error: Ein Fehler, falls ein Wert nicht übertragen werden kann, ansonsten nil.
+func encodeNamedArgs(codec Codec, args NamedArgs) (*transport.RpcDataCapsle, error) {
+	capsles := make(map[string]*transport.RpcDataCapsle, len(args))
+	for name, value := range args {
+		converted, err := processRpcGoDataTypeTransportable(codec, value)
+		if err != nil {
+			return nil, fmt.Errorf("encodeNamedArgs[0]: %s: %w", name, err)
+		}
+		capsles[name] = converted[0]
+	}
+
+	encoded, err := codec.Marshal(capsles)
+	if err != nil {
+		return nil, fmt.Errorf("encodeNamedArgs[1]: %w", err)
+	}
+	return &transport.RpcDataCapsle{Type: namedArgsType, Codec: codec.Name(), Encoded: encoded}, nil
+}
+
+// decodeNamedArgs liest benannte Parameter in einen Struct Parameter ein.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - capsle *transport.RpcDataCapsle: Die übertragenen Parameter.
+//   - expectedType reflect.Type: Der Typ des Struct Parameters (Struct oder Zeiger auf ein Struct).
+//
+// Rückgabe:
+//   - reflect.Value: Der befüllte Struct Parameter.
+//   - error: Ein Fehler, falls die Parameter nicht eingelesen werden konnten, ansonsten nil.
+func decodeNamedArgs(o *BngConn, capsle *transport.RpcDataCapsle, expectedType reflect.Type) (reflect.Value, error) {
+	// Die benannten Parameter können nur an ein Struct übergeben werden
+	structType := expectedType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("named arguments transmitted, expected %s", expectedType)
+	}
+
+	// Die Parameter werden dekodiert
+	codec, found := lookupCodec(capsle.Codec)
+	if !found {
+		return reflect.Value{}, fmt.Errorf("%w: %s", ErrUnsupportedCodec, capsle.Codec)
+	}
+	var capsles map[string]*transport.RpcDataCapsle
+	if err := codec.Unmarshal(capsle.Encoded, &capsles); err != nil {
+		return reflect.Value{}, fmt.Errorf("invalid named arguments: %w", err)
+	}
+
+	// Die Felder werden über ihren Namen befüllt, unbekannte Namen werden ignoriert und nur Optional Felder dürfen fehlen
+	result := reflect.New(structType)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("rpc"), ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		valueType, optional := optionalValueType(field.Type)
+		if !optional {
+			valueType = field.Type
+		}
+		value, found := capsles[name]
+		if !found || value == nil {
+			if optional {
+				continue
+			}
+			return reflect.Value{}, fmt.Errorf("named argument %s (%s) is missing", name, field.Type)
+		}
+
+		if err := checkStrictRpcType(o, value, valueType); err != nil {
+			return reflect.Value{}, fmt.Errorf("%s: %w", name, err)
+		}
+		converted, err := processRpcDataCapsleToGoValue(value, valueType)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%s: %w", name, err)
+		}
+		if !converted.Type().AssignableTo(valueType) {
+			return reflect.Value{}, fmt.Errorf("%s: %s transmitted, expected %s", name, converted.Type(), valueType)
+		}
+
+		// Optional Felder werden als übergeben markiert
+		if optional {
+			converted = newOptionalValue(field.Type, converted)
+		}
+		result.Elem().Field(i).Set(converted)
+	}
+
+	if expectedType.Kind() == reflect.Ptr {
+		return result, nil
+	}
+	return result.Elem(), nil
+}
@@ -0,0 +1,25 @@
+package bngsocket
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/custodia-cenv/bngsocket-go/transport"
+)
+
+func TestDecodeRpcResponseTooManyValues(t *testing.T) {
+	conn := newTestReadingConn(t, nil, nil)
+
+	// Eine Antwort mit mehr Rückgabewerten als erwartet darf nicht zu einem Panic führen
+	response := &transport.RpcResponse{
+		Type: "rpcres",
+		Return: []*transport.RpcDataCapsle{
+			{Type: "string", Value: "a"},
+			{Type: "string", Value: "b"},
+		},
+	}
+	if _, err := decodeRpcResponse(conn, response, []reflect.Type{reflect.TypeFor[string]()}); !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("expected ErrSignatureMismatch, got %v", err)
+	}
+}
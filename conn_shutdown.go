@@ -0,0 +1,211 @@
+package bngsocket
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go/transport"
+)
+
+// Intervall, in dem beim Herunterfahren geprüft wird ob alle Vorgänge abgeschlossen wurden
+const shutdownPollInterval = 10 * time.Millisecond
+
+// Grund, mit dem Channel Anfragen während des Herunterfahrens abgelehnt werden
+const channelRejectGoingAway = "#going_away"
+
+// Schlüssel, unter dem der Context einer aufgerufenen Funktion deren eigene Vorgänge vermerkt
+type _HandlerOperationsKey struct{}
+
+// _HandlerOperations beschreibt die Vorgänge, welche eine aufgerufene Funktion selbst belegt.
+// Wird Shutdown innerhalb der Funktion aufgerufen, wird auf diese Vorgänge nicht gewartet.
+type _HandlerOperations struct {
+	conn     *BngConn // Verbindung, über welche die Funktion aufgerufen wurde
+	channels int      // Anzahl der Channel, welche die Funktion belegt (z.B. der Channel eines Streams)
+}
+
+// Shutdown fährt die Verbindung geordnet herunter.
+// Die Gegenseite wird mittels GOAWAY Frame ('G') darüber informiert, dass keine neuen RPC Aufrufe
+// und Channel Beitritte mehr angenommen werden. Anschließend wird gewartet, bis alle laufenden
+// RPC Anfragen (ein- und ausgehend) abgeschlossen und alle offenen Channel geschlossen wurden.
+// Erst danach wird die Verbindung geschlossen. Läuft der Context vorher ab, wird die Verbindung
+// sofort geschlossen und der Fehler des Contexts zurückgegeben, ohne auf noch laufende Funktionen zu warten.
+// Wird Shutdown innerhalb einer aufgerufenen Funktion mit deren Context (BngRequest.Context) aufgerufen,
+// wird auf den Abschluss dieser Funktion nicht gewartet.
+//
+// Parameter:
+//   - ctx context.Context: Begrenzt die Zeit, die auf laufende Vorgänge gewartet wird.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls beim Herunterfahren ein Problem aufgetreten ist, ansonsten nil.
+func (s *BngConn) Shutdown(ctx context.Context) error {
+	// Es wird geprüft ob die Verbindung bereits getrennt wurde
+	if connectionIsClosed(s) {
+		return connectionTerminationError(s)
+	}
+
+	// Die Verbindung wird als auslaufend markiert, es werden keine neuen Vorgänge mehr angenommen
+	if s.draining.Set(true) != 1 {
+		return ErrConnectionDraining
+	}
+
+	// LOG
+	s.logger.Info("Shutdown started")
+
+	// Der Zustand der Verbindung wird geändert
+	setConnState(s, StateDraining, nil)
+
+	// Der Gegenseite wird mitgeteilt, dass keine neuen Vorgänge mehr gestartet werden sollen
+	if err := writeGoAway(s); err != nil {
+		writeProcessErrorHandling(s, err)
+		return err
+	}
+
+	// Es wird gewartet, bis alle laufenden Vorgänge abgeschlossen wurden
+	if err := waitForPendingOperations(ctx, s); err != nil {
+		// Die Verbindung wird sofort geschlossen, noch laufende Funktionsaufrufe werden im Hintergrund beendet
+		fullCloseConn(s)
+		return err
+	}
+
+	// Die Verbindung wird geschlossen
+	return fullCloseConn(s)
+}
+
+// PeerGoingAway gibt an, ob die Gegenseite mitgeteilt hat, dass sie die Verbindung herunterfährt.
+func (s *BngConn) PeerGoingAway() bool {
+	return s.peerGoingAway.Get()
+}
+
+// waitForPendingOperations wartet, bis keine RPC Anfragen mehr laufen und alle Channel geschlossen wurden.
+//
+// Parameter:
+//   - ctx context.Context: Begrenzt die Zeit, die gewartet wird.
+//   - s *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//
+// Rückgabe:
+//   - error: Der Fehler des Contexts, falls dieser vorher abgelaufen ist, ansonsten nil.
+func waitForPendingOperations(ctx context.Context, s *BngConn) error {
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+
+	// Die Vorgänge der aufrufenden Funktion werden nicht abgewartet
+	own := handlerOperations(ctx, s)
+
+	for {
+		// Es wird geprüft ob noch Vorgänge laufen
+		if !hasPendingOperations(s, own) || connectionIsClosed(s) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// hasPendingOperations gibt an, ob noch RPC Anfragen bzw. Batches laufen oder Channel geöffnet sind.
+// Die Vorgänge der aufrufenden Funktion (own) werden dabei nicht berücksichtigt.
+func hasPendingOperations(s *BngConn, own _HandlerOperations) bool {
+	if s.openRpcRequests.Count() != 0 {
+		return true
+	}
+	if s.openRpcBatches.Count() != 0 {
+		return true
+	}
+	if own.conn == s {
+		// Die Nachricht, über welche die Funktion aufgerufen wurde, gilt als eigener Vorgang
+		return s.runningRpcCalls.Get() > 1 || s.openChannelInstances.Count() > own.channels
+	}
+	if s.runningRpcCalls.Get() != 0 {
+		return true
+	}
+	if s.openChannelInstances.Count() != 0 {
+		return true
+	}
+	return false
+}
+
+// withHandlerOperations vermerkt im Context einer aufgerufenen Funktion die Vorgänge, welche sie selbst belegt.
+func withHandlerOperations(ctx context.Context, o *BngConn, channels int) context.Context {
+	return context.WithValue(ctx, _HandlerOperationsKey{}, _HandlerOperations{conn: o, channels: channels})
+}
+
+// handlerOperations gibt die im Context vermerkten Vorgänge der aufrufenden Funktion zurück.
+func handlerOperations(ctx context.Context, s *BngConn) _HandlerOperations {
+	own, _ := ctx.Value(_HandlerOperationsKey{}).(_HandlerOperations)
+	if own.conn != s {
+		return _HandlerOperations{}
+	}
+	return own
+}
+
+// acceptsNewOperations prüft ob über die Verbindung neue RPC Aufrufe oder Channel Beitritte gestartet werden dürfen.
+//
+// Rückgabe:
+//   - error: ErrConnectionDraining wenn die eigene Seite herunterfährt, ErrPeerGoingAway wenn die Gegenseite
+//     herunterfährt, ansonsten nil.
+func acceptsNewOperations(s *BngConn) error {
+	if s.draining.Get() {
+		return ErrConnectionDraining
+	}
+	if s.peerGoingAway.Get() {
+		return ErrPeerGoingAway
+	}
+	return nil
+}
+
+// writeGoAway sendet einen GOAWAY Frame ('G') an die Gegenseite.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls beim Senden des Frames ein Problem aufgetreten ist, ansonsten nil.
+func writeGoAway(o *BngConn) error {
+	o.writerMutex.Lock()
+	defer o.writerMutex.Unlock()
+
+	// Schreibe den GOAWAY Frame
+	if err := o.writer.WriteByte('G'); err != nil {
+		return fmt.Errorf("%w: %v", ErrWriteGoAway, err)
+	}
+
+	// Flushe den Writer
+	if err := o.writer.Flush(); err != nil {
+		return fmt.Errorf("%w: %v", ErrFlushWriter, err)
+	}
+
+	o.metrics.FrameSent('G', 1)
+	o.logger.Debug("GOAWAY sent", logFrame('G'))
+	return nil
+}
+
+// handleGoAway verarbeitet einen GOAWAY Frame der Gegenseite.
+// Ab diesem Zeitpunkt werden keine neuen RPC Aufrufe und Channel Beitritte mehr an die Gegenseite gesendet.
+func handleGoAway(o *BngConn) error {
+	o.peerGoingAway.Set(true)
+	o.metrics.FrameReceived('G', 1)
+	o.logger.Info("GOAWAY received", logFrame('G'))
+	return nil
+}
+
+// responseChannelGoingAway lehnt eine Channel Anfrage ab, da die Verbindung heruntergefahren wird.
+//
+// Parameter:
+//   - conn *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - sourceId string: Die ID der ursprünglichen Anfrage.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls beim Senden der Antwort ein Problem aufgetreten ist, ansonsten nil.
+func responseChannelGoingAway(conn *BngConn, sourceId string) error {
+	rt := &transport.ChannelRequestResponse{
+		Type:                "chreqresp",
+		ReqId:               sourceId,
+		NotAcceptedByReason: channelRejectGoingAway,
+	}
+
+	return convertAndWriteBytesIntoChan(conn, rt)
+}
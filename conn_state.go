@@ -0,0 +1,142 @@
+package bngsocket
+
+// State beschreibt den Zustand einer BngConn.
+type State uint8
+
+const (
+	// Die Verbindung wird aufgebaut
+	StateConnecting State = iota
+	// Die Verbindung ist bereit und nimmt RPC Aufrufe sowie Channel an
+	StateReady
+	// Die Verbindung wird heruntergefahren, es werden keine neuen Vorgänge mehr angenommen
+	StateDraining
+	// Die Verbindung wurde ordnungsgemäß geschlossen
+	StateClosed
+	// Die Verbindung wurde aufgrund eines Fehlers beendet
+	StateFailed
+)
+
+// String gibt den Namen des Zustands zurück.
+func (s State) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateReady:
+		return "ready"
+	case StateDraining:
+		return "draining"
+	case StateClosed:
+		return "closed"
+	case StateFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// isFinal gibt an, ob der Zustand nicht mehr verlassen werden kann.
+func (s State) isFinal() bool {
+	return s == StateClosed || s == StateFailed
+}
+
+// State gibt den aktuellen Zustand der Verbindung zurück.
+func (s *BngConn) State() State {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	return s.state.current
+}
+
+// OnStateChange registriert eine Funktion, welche bei jeder Zustandsänderung der Verbindung aufgerufen wird.
+// Die Funktion erhält den alten und den neuen Zustand, beim Übergang nach StateFailed zusätzlich den Fehler,
+// mit dem die Verbindung beendet wurde. Die Funktion wird in der Routine aufgerufen, welche die
+// Zustandsänderung ausgelöst hat, und sollte daher nicht blockieren. Da die Verbindung beim Upgrade bereits
+// in den Zustand StateReady wechselt, kann dieser Übergang nur über BngConnConfig.OnStateChange beobachtet werden.
+//
+// Parameter:
+//   - fn func(old, new State, err error): Die Funktion, welche bei einer Zustandsänderung aufgerufen wird.
+func (s *BngConn) OnStateChange(fn func(old, new State, err error)) {
+	if fn == nil {
+		return
+	}
+
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	s.state.hooks = append(s.state.hooks, fn)
+}
+
+// Done gibt einen Chan zurück, welcher geschlossen wird, sobald die Verbindung den Zustand
+// StateClosed oder StateFailed erreicht hat und alle Hintergrundaufgaben (z.B. noch laufende
+// Funktionsaufrufe) beendet wurden. Innerhalb einer aufgerufenen Funktion darf daher nicht auf
+// Done gewartet werden.
+func (s *BngConn) Done() <-chan struct{} {
+	return s.state.done
+}
+
+// connEnded gibt einen Chan zurück, welcher geschlossen wird, sobald die Verbindung einen finalen Zustand erreicht hat.
+// Hintergrundaufgaben verwenden diesen anstelle von Done, da Done erst nach ihrem Abschluss geschlossen wird.
+func connEnded(o *BngConn) <-chan struct{} {
+	return o.state.ended
+}
+
+// newConnState erzeugt den Zustand einer neuen Verbindung.
+func newConnState() *_ConnState {
+	return &_ConnState{
+		current: StateConnecting,
+		ended:   make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// setConnState ändert den Zustand der Verbindung und ruft die registrierten Funktionen auf.
+// Ein finaler Zustand (StateClosed, StateFailed) wird nicht mehr verlassen.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - next State: Der neue Zustand der Verbindung.
+//   - err error: Der Fehler, mit dem die Verbindung beendet wurde (nur bei StateFailed).
+//
+// Rückgabe:
+//   - bool: true, wenn der Zustand geändert wurde, ansonsten false.
+func setConnState(o *BngConn, next State, err error) bool {
+	o.state.mu.Lock()
+	old := o.state.current
+	if old == next || old.isFinal() {
+		o.state.mu.Unlock()
+		return false
+	}
+	o.state.current = next
+	if next == StateFailed {
+		o.state.err = err
+	}
+	hooks := make([]func(old, new State, err error), len(o.state.hooks))
+	copy(hooks, o.state.hooks)
+	if next.isFinal() {
+		close(o.state.ended)
+
+		// Done wird erst geschlossen, wenn alle Hintergrundaufgaben beendet wurden. Es wird nicht in der
+		// aufrufenden Routine gewartet, da diese selbst eine Hintergrundaufgabe (z.B. eine Funktion) sein kann
+		go func() {
+			o.backgroundProcesses.Wait()
+			close(o.state.done)
+		}()
+	}
+	o.state.mu.Unlock()
+
+	// Die registrierten Funktionen werden außerhalb der Sperre aufgerufen
+	for _, hook := range hooks {
+		hook(old, next, err)
+	}
+
+	return true
+}
+
+// connStateError gibt den Fehler zurück, mit dem die Verbindung beendet wurde.
+// Wurde die Verbindung ordnungsgemäß geschlossen, wird ErrConnectionClosedEOF zurückgegeben.
+func connStateError(o *BngConn) error {
+	o.state.mu.Lock()
+	defer o.state.mu.Unlock()
+	if o.state.current == StateFailed && o.state.err != nil {
+		return o.state.err
+	}
+	return ErrConnectionClosedEOF
+}
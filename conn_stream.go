@@ -0,0 +1,543 @@
+package bngsocket
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go/transport"
+	"github.com/google/uuid"
+)
+
+// Arten der Frames, welche über den Channel eines Streams übertragen werden
+const (
+	streamFrameOpen byte = 0 // Der Stream wurde von der aufgerufenen Seite angenommen
+	streamFrameData byte = 1 // Der Frame enthält einen Wert
+	streamFrameEnd  byte = 2 // Die Senderichtung wurde geschlossen
+)
+
+// Größe des Headers eines Stream Frames (Art + Länge)
+const streamFrameHeaderSize = 5
+
+// Send sendet einen Wert an die Gegenseite. Der Wert wird mit dem ausgehandelten Codec übertragen
+// und kann von der Gegenseite mit Recv bzw. Next in einen passenden Typen eingelesen werden.
+//
+// Parameter:
+//   - v interface{}: Der zu sendende Wert.
+//
+// Rückgabe:
+//   - error: ErrStreamClosed falls der Stream beendet wurde, ErrStreamSendClosed falls CloseSend aufgerufen wurde, ansonsten nil.
+func (st *BngStream) Send(v interface{}) error {
+	// Es wird geprüft ob der Context des Aufrufs abgelaufen ist
+	if err := st.ctx.Err(); err != nil {
+		return err
+	}
+
+	// Der Wert wird umgewandelt
+	payload, err := marshalMessage(st.conn, v)
+	if err != nil {
+		return fmt.Errorf("bngsocket->BngStream.Send[0]: " + err.Error())
+	}
+
+	st.sendMu.Lock()
+	defer st.sendMu.Unlock()
+
+	// Es wird geprüft ob die Senderichtung bereits geschlossen wurde
+	if st.sendClosed.Get() {
+		return ErrStreamSendClosed
+	}
+
+	// Der Wert wird übertragen
+	if err := writeStreamFrame(st, streamFrameData, payload); err != nil {
+		return fmt.Errorf("bngsocket->BngStream.Send[1]: %w", err)
+	}
+
+	return nil
+}
+
+// CloseSend schließt die Senderichtung des Aufrufers, die aufgerufene Funktion erhält
+// anschließend io.EOF beim Lesen. Empfangen werden kann weiterhin.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls der Stream bereits beendet wurde, ansonsten nil.
+func (st *BngStream) CloseSend() error {
+	st.sendMu.Lock()
+	defer st.sendMu.Unlock()
+
+	// Die Senderichtung wird nur einmal geschlossen
+	if st.sendClosed.Set(true) != 1 {
+		return nil
+	}
+
+	if err := writeStreamFrame(st, streamFrameEnd, nil); err != nil {
+		return fmt.Errorf("bngsocket->BngStream.CloseSend: %w", err)
+	}
+	return nil
+}
+
+// Recv liest den nächsten Wert der Gegenseite in v ein.
+// Hat die Gegenseite ihre Senderichtung geschlossen, wird io.EOF zurückgegeben. Auf Seite des Aufrufers
+// wird stattdessen der Fehler der aufgerufenen Funktion zurückgegeben, sofern diese einen Fehler zurückgegeben hat.
+//
+// Parameter:
+//   - v interface{}: Ein Zeiger, in den der empfangene Wert eingelesen wird.
+//
+// Rückgabe:
+//   - error: io.EOF am Ende des Streams, ansonsten der aufgetretene Fehler oder nil.
+func (st *BngStream) Recv(v interface{}) error {
+	st.recvMu.Lock()
+	defer st.recvMu.Unlock()
+
+	// Die Gegenseite hat ihre Senderichtung bereits geschlossen
+	if st.recvClosed.Get() {
+		if st.clientSide {
+			return st.result()
+		}
+		return io.EOF
+	}
+
+	// Der nächste Frame wird gelesen
+	kind, payload, err := readStreamFrame(st.channel)
+	if err != nil {
+		// Ein zu großer Frame beendet den Stream, da die restlichen Bytes des Frames nicht gelesen wurden
+		if !errors.Is(err, ErrFrameTooLarge) {
+			err = streamTerminationError(st)
+		}
+		if st.clientSide {
+			st.finish(err, false)
+			return st.result()
+		}
+		return err
+	}
+
+	switch kind {
+	case streamFrameData:
+		if err := unmarshalMessage(payload, v); err != nil {
+			return fmt.Errorf("bngsocket->BngStream.Recv[0]: " + err.Error())
+		}
+		return nil
+	case streamFrameEnd:
+		st.recvClosed.Set(true)
+		if st.clientSide {
+			awaitStreamResponse(st)
+			return st.result()
+		}
+		return io.EOF
+	default:
+		return fmt.Errorf("bngsocket->BngStream.Recv[1]: unexpected stream frame %d", kind)
+	}
+}
+
+// Next liest den nächsten Wert der Gegenseite in v ein und gibt an, ob ein Wert gelesen wurde.
+// Nach dem Ende des Streams gibt Err den aufgetretenen Fehler zurück.
+//
+//	for stream.Next(&value) {
+//		...
+//	}
+//	if err := stream.Err(); err != nil {
+//		...
+//	}
+func (st *BngStream) Next(v interface{}) bool {
+	if err := st.Recv(v); err != nil {
+		if !errors.Is(err, io.EOF) {
+			st.iterErr = err
+		}
+		return false
+	}
+	return true
+}
+
+// Err gibt den Fehler zurück, mit dem Next beendet wurde. Wurde der Stream ordnungsgemäß beendet, wird nil zurückgegeben.
+func (st *BngStream) Err() error {
+	return st.iterErr
+}
+
+// Close bricht den Stream ab. Die Gegenseite erhält ErrStreamClosed beim Senden und Lesen.
+func (st *BngStream) Close() error {
+	if st.clientSide {
+		st.finish(ErrStreamClosed, false)
+		return nil
+	}
+	return st.channel.processClose(true)
+}
+
+// Context gibt den Context des Aufrufs zurück.
+func (st *BngStream) Context() context.Context {
+	return st.ctx
+}
+
+// RequestID gibt die ID des RPC Aufrufs zurück, diese entspricht der ID der Channel Sitzung.
+func (st *BngStream) RequestID() string {
+	return st.id
+}
+
+// result gibt das Ergebnis des Aufrufs zurück, wurde dieser ohne Fehler beendet, wird io.EOF zurückgegeben.
+func (st *BngStream) result() error {
+	<-st.finished
+	if st.err == nil {
+		return io.EOF
+	}
+	return st.err
+}
+
+// finish schließt den Aufruf auf Seite des Aufrufers ab.
+// Wurde die abschließende Antwort nicht empfangen, wird die Gegenseite über das Schließen des Channels
+// informiert und eine später eintreffende Antwort verworfen.
+//
+// Parameter:
+//   - err error: Das Ergebnis des Aufrufs.
+//   - responseReceived bool: Gibt an ob die abschließende Antwort der Gegenseite empfangen wurde.
+func (st *BngStream) finish(err error, responseReceived bool) {
+	st.finishOnce.Do(func() {
+		st.err = err
+		st.channel.processClose(!responseReceived)
+		if responseReceived {
+			if _, loaded := st.conn.openRpcRequests.LoadAndDelete(st.id); loaded {
+				close(st.responseChan)
+			}
+		} else {
+			go discardRpcResponse(st.conn, st.id, st.responseChan)
+		}
+		close(st.finished)
+		if st.onFinish != nil {
+			st.onFinish(err)
+		}
+	})
+}
+
+// streamTerminationError gibt den Fehler zurück, mit dem ein Stream unerwartet beendet wurde.
+func streamTerminationError(st *BngStream) error {
+	if err := st.ctx.Err(); err != nil {
+		return err
+	}
+	if connectionIsClosed(st.conn) {
+		return connectionTerminationError(st.conn)
+	}
+	return ErrStreamClosed
+}
+
+// awaitStreamResponse wartet auf die abschließende Antwort der aufgerufenen Funktion und schließt den Aufruf ab.
+func awaitStreamResponse(st *BngStream) {
+	select {
+	case response, ok := <-st.responseChan:
+		if !ok {
+			st.finish(connectionTerminationError(st.conn), true)
+			return
+		}
+		captureRpcTrailer(st.ctx, response.Metadata)
+		if response.Error != "" {
+			st.finish(processRpcError(response.Code, response.Error), true)
+			return
+		}
+		st.finish(nil, true)
+	case <-st.ctx.Done():
+		st.finish(st.ctx.Err(), false)
+	case <-st.finished:
+	}
+}
+
+// writeStreamFrame überträgt einen Frame über den Channel eines Streams.
+// Jeder Frame besteht aus der Art (1 Byte), der Länge der Nutzdaten (4 Byte, BigEndian) und den Nutzdaten.
+func writeStreamFrame(st *BngStream, kind byte, payload []byte) error {
+	frame := make([]byte, streamFrameHeaderSize+len(payload))
+	frame[0] = kind
+	binary.BigEndian.PutUint32(frame[1:streamFrameHeaderSize], uint32(len(payload)))
+	copy(frame[streamFrameHeaderSize:], payload)
+
+	if _, err := st.channel.Write(frame); err != nil {
+		if st.channel.isClosed.Get() || errors.Is(err, io.EOF) {
+			return streamTerminationError(st)
+		}
+		return err
+	}
+	return nil
+}
+
+// readStreamFrame liest einen Frame aus dem Channel eines Streams.
+// Frames, deren Länge MaxStreamFrameSize überschreitet, werden vor dem Anlegen des Puffers mit ErrFrameTooLarge abgelehnt.
+func readStreamFrame(channel *BngConnChannel) (byte, []byte, error) {
+	header := make([]byte, streamFrameHeaderSize)
+	if _, err := io.ReadFull(channel, header); err != nil {
+		return 0, nil, err
+	}
+
+	// Die Länge wird vor dem Anlegen des Puffers geprüft
+	length := binary.BigEndian.Uint32(header[1:])
+	if uint64(length) > uint64(channel.socket.config.MaxStreamFrameSize) {
+		return 0, nil, fmt.Errorf("%w: stream frame of %d bytes", ErrFrameTooLarge, length)
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(channel, payload); err != nil {
+		return 0, nil, err
+	}
+
+	return header[0], payload, nil
+}
+
+// newBngStream erzeugt einen neuen Stream für die Channel Sitzung eines RPC Aufrufs.
+func newBngStream(conn *BngConn, channel *BngConnChannel, ctx context.Context, id string, method string) *BngStream {
+	return &BngStream{
+		conn:       conn,
+		channel:    channel,
+		ctx:        ctx,
+		id:         id,
+		method:     method,
+		sendClosed: newSafeBool(false),
+		recvClosed: newSafeBool(false),
+		finished:   make(chan struct{}),
+	}
+}
+
+// isStreamFunction gibt an, ob es sich um eine Stream Funktion handelt (func(*BngRequest, *BngStream, ...) error).
+func isStreamFunction(fnType reflect.Type) bool {
+	return fnType.NumIn() >= 2 && fnType.In(1) == reflect.TypeOf((*BngStream)(nil))
+}
+
+// Wird verwendet um eingehende Stream-RPC Anfragen zu verarbeiten
+func processRpcStreamRequest(o *BngConn, rpcReq *transport.RpcRequest, fn reflect.Value) (err error) {
+	// LOG
+	o.logger.Debug("Enter incomming rpc stream call", slog.String(logKeyRpcId, rpcReq.Id))
+
+	// Der Trace Context des Aufrufers wird übernommen und ein Span für die Ausführung erzeugt
+	traceCtx := o.propagator.Extract(context.Background(), rpcReq.Metadata)
+	traceCtx, span := o.tracer.Start(traceCtx, rpcReq.Name, SpanKindServer)
+	span.SetAttribute(logKeyRpcId, rpcReq.Id)
+
+	// Die Dauer des Aufrufs wird erfasst und der Span beendet
+	start := time.Now()
+	var callErr error
+	defer func() {
+		if callErr == nil {
+			callErr = err
+		}
+		if callErr != nil {
+			span.RecordError(callErr)
+		}
+		span.End()
+		o.metrics.RpcRequestServed(rpcReq.Name, time.Since(start), callErr)
+	}()
+
+	// Der Context wird mit der Deadline des Aufrufers versehen und beim Beenden der Verbindung abgebrochen
+	requestCtx, cancel := newRpcRequestContext(o, withHandlerOperations(traceCtx, o, 1), rpcReq.Timeout)
+	defer cancel()
+
+	req := &BngRequest{
+		Conn:     o,
+		ctx:      requestCtx,
+		id:       rpcReq.Id,
+		method:   rpcReq.Name,
+		metadata: rpcReq.Metadata,
+	}
+
+	// Die Parameter werden eingelesen, ab dem dritten Parameter der Funktion
+	fnType := fn.Type()
+	in := make([]reflect.Value, 0, fnType.NumIn())
+	for i, param := range rpcReq.Params {
+		// Ungültige Parameter werden dem Aufrufer als Fehler zurückgegeben
+		expectedType, err := expectedRpcParameterType(fnType, 2, i)
+		if err != nil {
+			callErr = err
+			if err := socketWriteRpcErrorResponse(o, callErr, rpcReq.Id, nil); err != nil {
+				return fmt.Errorf("bngsocket->processRpcStreamRequest[0]: " + err.Error())
+			}
+			return nil
+		}
+		value, err := convertIncomingRpcParameter(req, i, param, expectedType)
+		if err != nil {
+			callErr = err
+			if err := socketWriteRpcErrorResponse(o, callErr, rpcReq.Id, nil); err != nil {
+				return fmt.Errorf("bngsocket->processRpcStreamRequest[1]: " + err.Error())
+			}
+			return nil
+		}
+		in = append(in, value)
+	}
+	in, err = completeRpcArguments(fnType, 2, in)
+	if err != nil {
+		callErr = err
+		if err := socketWriteRpcErrorResponse(o, callErr, rpcReq.Id, nil); err != nil {
+			return fmt.Errorf("bngsocket->processRpcStreamRequest[1a]: " + err.Error())
+		}
+		return nil
+	}
+
+	// Die Channel Sitzung des Streams wird mit der ID des Aufrufs registriert
+	channel, err := o._RegisterNewChannelSession(rpcReq.Id)
+	if err != nil {
+		return fmt.Errorf("bngsocket->processRpcStreamRequest[2]: " + err.Error())
+	}
+	stream := newBngStream(o, channel, requestCtx, rpcReq.Id, rpcReq.Name)
+
+	// Läuft der Context ab, wird der Channel geschlossen, damit wartende Sende- und Lesevorgänge beendet werden
+	stop := context.AfterFunc(requestCtx, func() { channel.processClose(true) })
+	defer stop()
+
+	// Dem Aufrufer wird mitgeteilt dass der Stream angenommen wurde
+	if err := writeStreamFrame(stream, streamFrameOpen, nil); err != nil {
+		channel.processClose(false)
+		callErr = err
+		return nil
+	}
+
+	// Die Funktion wird PANIC Sicher ausgeführt
+	callErr = func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("bngsocket->processRpcStreamRequest[3]: panic occurred: %v", r)
+			}
+		}()
+		results := callRpcFunctionValue(fn, append([]reflect.Value{reflect.ValueOf(req), reflect.ValueOf(stream)}, in...))
+		if result := results[0]; !result.IsNil() {
+			return result.Interface().(error)
+		}
+		return nil
+	}()
+
+	// Die Senderichtung wird geschlossen, der Aufrufer liest anschließend die abschließende Antwort
+	stream.CloseSend()
+	channel.processClose(true)
+
+	// LOG
+	o.logger.Debug("Return result for rpc stream call", slog.String(logKeyRpcId, rpcReq.Id))
+
+	// Die abschließende Antwort wird gesendet
+	if callErr != nil {
+		if err := socketWriteRpcErrorResponse(o, callErr, rpcReq.Id, req.trailerMetadata()); err != nil {
+			return fmt.Errorf("bngsocket->processRpcStreamRequest[4]: " + err.Error())
+		}
+		return nil
+	}
+	if err := socketWriteRpcSuccessResponse(o, nil, rpcReq.Id, req.trailerMetadata()); err != nil {
+		return fmt.Errorf("bngsocket->processRpcStreamRequest[5]: " + err.Error())
+	}
+
+	return nil
+}
+
+// Ruft eine Stream Funktion auf der Gegenseite auf
+func _CallStream(ctx context.Context, s *BngConn, nameorid string, params []interface{}) (_ *BngStream, err error) {
+	// Es wird geprüft ob die Verbindung getrennt wurde
+	if connectionIsClosed(s) {
+		return nil, io.EOF
+	}
+
+	// Es wird geprüft ob neue Aufrufe gestartet werden dürfen
+	if err := acceptsNewOperations(s); err != nil {
+		return nil, err
+	}
+
+	// Die Parameter werden geprüft, übergebene Funktionen werden bis zum Ende des Streams als Callbacks registriert
+	params, hiddenIds, err := prepareRpcParams(s, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Die Parameter werden umgewandelt
+	convertedParams, err := processRpcGoDataTypeTransportable(messageCodec(s), params...)
+	if err != nil {
+		releaseHiddenFunctions(s, hiddenIds)
+		return nil, fmt.Errorf("bngsocket->_CallStream[0]: " + err.Error())
+	}
+
+	// Es wird ein Span für den Aufruf erzeugt, die Dauer des Aufrufs wird bis zum Ende des Streams erfasst
+	ctx, span := s.tracer.Start(ctx, nameorid, SpanKindClient)
+	start := time.Now()
+	onFinish := func(err error) {
+		releaseHiddenFunctions(s, hiddenIds)
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+		s.metrics.RpcCallFinished(nameorid, time.Since(start), err)
+	}
+
+	// Es wird ein RpcRequest Paket erstellt
+	rpcreq := &transport.RpcRequest{
+		Type:     "rpcreq",
+		Params:   convertedParams,
+		Name:     nameorid,
+		Id:       strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Metadata: injectRpcMetadata(s, ctx),
+		Timeout:  rpcTimeout(ctx),
+		Stream:   true,
+	}
+	span.SetAttribute(logKeyRpcId, rpcreq.Id)
+
+	// Das Paket wird in Bytes umgewandelt
+	bytedData, err := marshalMessage(s, rpcreq)
+	if err != nil {
+		onFinish(err)
+		return nil, fmt.Errorf("bngsocket->_CallStream[1]: " + err.Error())
+	}
+
+	// Die Channel Sitzung wird vor dem Senden registriert, damit die ersten Frames der Gegenseite zugestellt werden können
+	channel, err := s._RegisterNewChannelSession(rpcreq.Id)
+	if err != nil {
+		onFinish(err)
+		return nil, fmt.Errorf("bngsocket->_CallStream[2]: " + err.Error())
+	}
+	stream := newBngStream(s, channel, ctx, rpcreq.Id, nameorid)
+	stream.clientSide = true
+	stream.responseChan = make(chan *transport.RpcResponse)
+	stream.onFinish = onFinish
+	s.openRpcRequests.Store(rpcreq.Id, stream.responseChan)
+
+	// Das Paket wird gesendet
+	if err := writeBytesIntoSocketConn(s, bytedData); err != nil {
+		if connectionIsClosed(s) {
+			err = io.EOF
+		} else {
+			err = fmt.Errorf("bngsocket->_CallStream[3]: " + err.Error())
+		}
+		stream.finish(err, false)
+		return nil, err
+	}
+
+	// Es wird gewartet bis die Gegenseite den Stream angenommen oder abgelehnt hat
+	opened := make(chan error, 1)
+	go func() {
+		kind, _, err := readStreamFrame(channel)
+		if err == nil && kind != streamFrameOpen {
+			err = fmt.Errorf("bngsocket->_CallStream[4]: unexpected stream frame %d", kind)
+		}
+		opened <- err
+	}()
+	select {
+	case err := <-opened:
+		if err != nil {
+			err = streamTerminationError(stream)
+			stream.finish(err, false)
+			return nil, err
+		}
+	case response, ok := <-stream.responseChan:
+		// Die Gegenseite hat den Aufruf abgelehnt
+		if !ok {
+			stream.finish(connectionTerminationError(s), true)
+		} else {
+			captureRpcTrailer(ctx, response.Metadata)
+			stream.finish(processRpcError(response.Code, response.Error), true)
+		}
+		return nil, stream.err
+	case <-ctx.Done():
+		stream.finish(ctx.Err(), false)
+		return nil, ctx.Err()
+	}
+
+	// Läuft der Context ab, wird der Stream abgebrochen
+	go func() {
+		select {
+		case <-ctx.Done():
+			stream.finish(ctx.Err(), false)
+		case <-stream.finished:
+		}
+	}()
+
+	return stream, nil
+}
@@ -0,0 +1,194 @@
+package bngsocket
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+)
+
+// Schlüssel der Metadaten, über welche der W3C Trace Context übertragen wird
+const (
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+// SpanKind gibt an, auf welcher Seite eines RPC Aufrufs ein Span erzeugt wurde.
+type SpanKind uint8
+
+const (
+	// Der Span beschreibt einen ausgehenden RPC Aufruf
+	SpanKindClient SpanKind = iota
+	// Der Span beschreibt die Ausführung eines eingehenden RPC Aufrufs
+	SpanKindServer
+)
+
+// Span beschreibt einen laufenden Abschnitt eines Traces.
+type Span interface {
+	// SetAttribute setzt ein Attribut des Spans.
+	SetAttribute(key string, value string)
+	// RecordError vermerkt einen Fehler im Span.
+	RecordError(err error)
+	// End beendet den Span.
+	End()
+}
+
+// Tracer erzeugt die Spans für ausgehende und eingehende RPC Aufrufe.
+// Über diese Schnittstelle kann z.B. ein OpenTelemetry Tracer angebunden werden.
+type Tracer interface {
+	// Start erzeugt einen neuen Span und gibt einen Context zurück, welcher den Span enthält.
+	Start(ctx context.Context, name string, kind SpanKind) (context.Context, Span)
+}
+
+// Propagator überträgt den Trace Context eines Aufrufers über die Metadaten eines RPC Aufrufs.
+type Propagator interface {
+	// Inject schreibt den Trace Context aus ctx in die Metadaten.
+	Inject(ctx context.Context, metadata map[string]string)
+	// Extract liest den Trace Context aus den Metadaten und gibt einen Context zurück, welcher diesen enthält.
+	Extract(ctx context.Context, metadata map[string]string) context.Context
+}
+
+// SpanContext enthält die Daten eines W3C Trace Contexts.
+type SpanContext struct {
+	TraceID    [16]byte // ID des Traces
+	SpanID     [8]byte  // ID des Spans
+	TraceFlags byte     // Flags des Traces (0x01 = sampled)
+	TraceState string   // Herstellerspezifische Daten (tracestate), werden unverändert weitergegeben
+}
+
+// IsValid gibt an, ob TraceID und SpanID gesetzt sind.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// TraceParent gibt den SpanContext im Format des W3C traceparent Headers zurück.
+func (sc SpanContext) TraceParent() string {
+	return "00-" + hex.EncodeToString(sc.TraceID[:]) + "-" + hex.EncodeToString(sc.SpanID[:]) + "-" + hex.EncodeToString([]byte{sc.TraceFlags})
+}
+
+// Schlüssel, unter welchem der SpanContext in einem Context gespeichert wird
+type spanContextKey struct{}
+
+// ContextWithSpanContext gibt einen Context zurück, welcher den SpanContext enthält.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext gibt den SpanContext zurück, welcher im Context gespeichert wurde.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
+
+// TraceContextPropagator überträgt den Trace Context im Format des W3C traceparent/tracestate Headers.
+// Der Trace Context wird mittels ContextWithSpanContext im Context des Aufrufers erwartet.
+type TraceContextPropagator struct{}
+
+// Inject implementiert Propagator.
+func (TraceContextPropagator) Inject(ctx context.Context, metadata map[string]string) {
+	sc, ok := SpanContextFromContext(ctx)
+	if !ok {
+		return
+	}
+	metadata[TraceParentHeader] = sc.TraceParent()
+	if sc.TraceState != "" {
+		metadata[TraceStateHeader] = sc.TraceState
+	}
+}
+
+// Extract implementiert Propagator.
+func (TraceContextPropagator) Extract(ctx context.Context, metadata map[string]string) context.Context {
+	sc, err := ParseTraceParent(metadata[TraceParentHeader])
+	if err != nil {
+		return ctx
+	}
+	sc.TraceState = metadata[TraceStateHeader]
+	return ContextWithSpanContext(ctx, sc)
+}
+
+// ParseTraceParent liest einen W3C traceparent Header ("00-<trace-id>-<span-id>-<flags>").
+//
+// Parameter:
+//   - value string: Der Wert des traceparent Headers.
+//
+// Rückgabe:
+//   - SpanContext: Der gelesene SpanContext.
+//   - error: ErrInvalidTraceParent, falls der Header ungültig ist, ansonsten nil.
+func ParseTraceParent(value string) (SpanContext, error) {
+	var sc SpanContext
+
+	parts := strings.Split(value, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, ErrInvalidTraceParent
+	}
+
+	// Die Version "ff" ist ungültig, die Version "00" darf keine weiteren Felder enthalten
+	if parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return sc, ErrInvalidTraceParent
+	}
+
+	// Die Felder dürfen nur aus kleingeschriebenen Hex Zeichen bestehen
+	for _, part := range parts[:4] {
+		if strings.ToLower(part) != part {
+			return sc, ErrInvalidTraceParent
+		}
+	}
+
+	var flags [1]byte
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return sc, ErrInvalidTraceParent
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return sc, ErrInvalidTraceParent
+	}
+	if _, err := hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return sc, ErrInvalidTraceParent
+	}
+	sc.TraceFlags = flags[0]
+
+	if !sc.IsValid() {
+		return sc, ErrInvalidTraceParent
+	}
+	return sc, nil
+}
+
+// _NoopTracer erzeugt keine Spans, er wird verwendet wenn kein Tracer konfiguriert wurde.
+type _NoopTracer struct{}
+
+func (_NoopTracer) Start(ctx context.Context, _ string, _ SpanKind) (context.Context, Span) {
+	return ctx, _NoopSpan{}
+}
+
+// _NoopSpan verwirft alle Angaben.
+type _NoopSpan struct{}
+
+func (_NoopSpan) SetAttribute(string, string) {}
+func (_NoopSpan) RecordError(error)           {}
+func (_NoopSpan) End()                        {}
+
+// newConnTracer gibt den Tracer einer Verbindung zurück, ist keiner vorhanden, werden keine Spans erzeugt.
+func newConnTracer(tracer Tracer) Tracer {
+	if tracer == nil {
+		return _NoopTracer{}
+	}
+	return tracer
+}
+
+// newConnPropagator gibt den Propagator einer Verbindung zurück, standardmäßig wird der W3C Trace Context verwendet.
+func newConnPropagator(propagator Propagator) Propagator {
+	if propagator == nil {
+		return TraceContextPropagator{}
+	}
+	return propagator
+}
+
+// injectRpcMetadata erzeugt die Metadaten eines ausgehenden RPC Aufrufs aus den mittels
+// ContextWithMetadata gesetzten Metadaten und dem Trace Context.
+// Sind keine Metadaten vorhanden, wird nil zurückgegeben.
+func injectRpcMetadata(o *BngConn, ctx context.Context) map[string]string {
+	metadata := OutgoingMetadataFromContext(ctx)
+	o.propagator.Inject(ctx, metadata)
+	if len(metadata) == 0 {
+		return nil
+	}
+	return metadata
+}
@@ -0,0 +1,38 @@
+package bngsocket
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTraceContextPropagator(t *testing.T) {
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	// Der traceparent Header muss unverändert übertragen werden
+	metadata := map[string]string{TraceParentHeader: traceParent, TraceStateHeader: "vendor=value"}
+	ctx := TraceContextPropagator{}.Extract(context.Background(), metadata)
+	sc, ok := SpanContextFromContext(ctx)
+	if !ok || sc.TraceFlags != 1 || sc.TraceState != "vendor=value" {
+		t.Fatalf("invalid span context: %+v", sc)
+	}
+
+	injected := make(map[string]string)
+	TraceContextPropagator{}.Inject(ctx, injected)
+	if injected[TraceParentHeader] != traceParent || injected[TraceStateHeader] != "vendor=value" {
+		t.Fatalf("invalid injected metadata: %v", injected)
+	}
+
+	// Ungültige Header werden ignoriert
+	for _, invalid := range []string{
+		"",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if _, err := ParseTraceParent(invalid); !errors.Is(err, ErrInvalidTraceParent) {
+			t.Errorf("expected ErrInvalidTraceParent for %q, got %v", invalid, err)
+		}
+	}
+}
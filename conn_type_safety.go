@@ -0,0 +1,196 @@
+package bngsocket
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/custodia-cenv/bngsocket-go/transport"
+)
+
+// convertRpcNumber wandelt eine übertragene Zahl in den erwarteten Zahlentyp um.
+// Im Gegensatz zu reflect.Value.Convert wird geprüft ob der Wert ohne Verlust in den Zieltyp passt.
+//
+// Parameter:
+//   - val reflect.Value: Die übertragene Zahl.
+//   - expectedType reflect.Type: Der erwartete Zahlentyp.
+//
+// Rückgabe:
+//   - reflect.Value: Die umgewandelte Zahl.
+//   - error: ErrValueOutOfRange, falls der Wert nicht verlustfrei umgewandelt werden kann, ansonsten nil.
+func convertRpcNumber(val reflect.Value, expectedType reflect.Type) (reflect.Value, error) {
+	// Übertragene Werte können als interface{} vorliegen
+	if val.Kind() == reflect.Interface {
+		val = val.Elem()
+	}
+
+	result := reflect.New(expectedType).Elem()
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v := val.Int()
+		switch {
+		case isIntKind(expectedType.Kind()):
+			if result.OverflowInt(v) {
+				return reflect.Value{}, fmt.Errorf("%w: %d does not fit into %s", ErrValueOutOfRange, v, expectedType)
+			}
+			result.SetInt(v)
+		case isUintKind(expectedType.Kind()):
+			if v < 0 || result.OverflowUint(uint64(v)) {
+				return reflect.Value{}, fmt.Errorf("%w: %d does not fit into %s", ErrValueOutOfRange, v, expectedType)
+			}
+			result.SetUint(uint64(v))
+		case isFloatKind(expectedType.Kind()):
+			if !floatHoldsExactly(expectedType.Kind(), float64(v)) || int64(float64(v)) != v {
+				return reflect.Value{}, fmt.Errorf("%w: %d can not be represented exactly as %s", ErrValueOutOfRange, v, expectedType)
+			}
+			result.SetFloat(float64(v))
+		default:
+			return reflect.Value{}, fmt.Errorf("invalid integer transmitted, expected %s", expectedType)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v := val.Uint()
+		switch {
+		case isIntKind(expectedType.Kind()):
+			if v > math.MaxInt64 || result.OverflowInt(int64(v)) {
+				return reflect.Value{}, fmt.Errorf("%w: %d does not fit into %s", ErrValueOutOfRange, v, expectedType)
+			}
+			result.SetInt(int64(v))
+		case isUintKind(expectedType.Kind()):
+			if result.OverflowUint(v) {
+				return reflect.Value{}, fmt.Errorf("%w: %d does not fit into %s", ErrValueOutOfRange, v, expectedType)
+			}
+			result.SetUint(v)
+		case isFloatKind(expectedType.Kind()):
+			if !floatHoldsExactly(expectedType.Kind(), float64(v)) || uint64(float64(v)) != v {
+				return reflect.Value{}, fmt.Errorf("%w: %d can not be represented exactly as %s", ErrValueOutOfRange, v, expectedType)
+			}
+			result.SetFloat(float64(v))
+		default:
+			return reflect.Value{}, fmt.Errorf("invalid integer transmitted, expected %s", expectedType)
+		}
+	case reflect.Float32, reflect.Float64:
+		v := val.Float()
+		switch {
+		case isIntKind(expectedType.Kind()):
+			if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 || result.OverflowInt(int64(v)) {
+				return reflect.Value{}, fmt.Errorf("%w: %v does not fit into %s", ErrValueOutOfRange, v, expectedType)
+			}
+			result.SetInt(int64(v))
+		case isUintKind(expectedType.Kind()):
+			if v != math.Trunc(v) || v < 0 || v >= math.MaxUint64 || result.OverflowUint(uint64(v)) {
+				return reflect.Value{}, fmt.Errorf("%w: %v does not fit into %s", ErrValueOutOfRange, v, expectedType)
+			}
+			result.SetUint(uint64(v))
+		case isFloatKind(expectedType.Kind()):
+			if !floatHoldsExactly(expectedType.Kind(), v) {
+				return reflect.Value{}, fmt.Errorf("%w: %v can not be represented exactly as %s", ErrValueOutOfRange, v, expectedType)
+			}
+			result.SetFloat(v)
+		default:
+			return reflect.Value{}, fmt.Errorf("invalid float transmitted, expected %s", expectedType)
+		}
+	default:
+		return reflect.Value{}, fmt.Errorf("number expected for %s, transmitted %s", expectedType, val.Kind())
+	}
+
+	return result, nil
+}
+
+// floatHoldsExactly gibt an ob der Wert ohne Genauigkeitsverlust als Gleitkommazahl der Art gespeichert werden kann.
+func floatHoldsExactly(kind reflect.Kind, v float64) bool {
+	if kind == reflect.Float64 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return true
+	}
+	return float64(float32(v)) == v
+}
+
+// isIntKind gibt an ob es sich um einen vorzeichenbehafteten Ganzzahltyp handelt.
+func isIntKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+// isUintKind gibt an ob es sich um einen vorzeichenlosen Ganzzahltyp handelt.
+func isUintKind(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uintptr
+}
+
+// isFloatKind gibt an ob es sich um einen Gleitkommatyp handelt.
+func isFloatKind(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+// checkStrictRpcType prüft im Strict Modus ob der übertragene Wert exakt dem erwarteten Typ entspricht.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - capsle *transport.RpcDataCapsle: Der übertragene Wert.
+//   - expectedType reflect.Type: Der erwartete Typ.
+//
+// Rückgabe:
+//   - error: ErrTypeMismatch, falls der Typ nicht übereinstimmt, ansonsten nil.
+func checkStrictRpcType(o *BngConn, capsle *transport.RpcDataCapsle, expectedType reflect.Type) error {
+	// Ohne Strict Modus werden die Werte umgewandelt
+	if !o.config.StrictTypes {
+		return nil
+	}
+
+	// Callbacks und Channel werden über ihre ID übertragen und separat geprüft, benannte Parameter je Feld
+	if capsle.Type == "func" || capsle.Type == "channel" || capsle.Type == namedArgsType {
+		return nil
+	}
+
+	// Der genaue Typ muss übertragen worden sein und übereinstimmen
+	if capsle.Kind == "" {
+		return fmt.Errorf("%w: %s transmitted without exact type, expected %s", ErrTypeMismatch, capsle.Type, expectedType)
+	}
+	if capsle.Kind != expectedType.String() {
+		return fmt.Errorf("%w: %s transmitted, expected %s", ErrTypeMismatch, capsle.Kind, expectedType)
+	}
+
+	return nil
+}
+
+// convertRpcParameter liest einen übertragenen Parameter als erwarteten Typ ein.
+// Ungültige Parameter werden als ErrInvalidParameter an den Aufrufer zurückgegeben.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - index int: Die Position des Parameters.
+//   - capsle *transport.RpcDataCapsle: Der übertragene Parameter.
+//   - expectedType reflect.Type: Der erwartete Typ.
+//
+// Rückgabe:
+//   - reflect.Value: Der eingelesene Parameter.
+//   - error: Ein Fehler, falls der Parameter nicht eingelesen werden konnte, ansonsten nil.
+func convertRpcParameter(o *BngConn, index int, capsle *transport.RpcDataCapsle, expectedType reflect.Type) (reflect.Value, error) {
+	// Bei Optional Parameter wird der enthaltene Wert eingelesen
+	if valueType, ok := optionalValueType(expectedType); ok {
+		value, err := convertRpcParameter(o, index, capsle, valueType)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return newOptionalValue(expectedType, value), nil
+	}
+
+	if err := checkStrictRpcType(o, capsle, expectedType); err != nil {
+		return reflect.Value{}, fmt.Errorf("%w: parameter %d: %w", ErrInvalidParameter, index, err)
+	}
+
+	// Benannte Parameter werden in den Struct Parameter eingelesen
+	if capsle.Type == namedArgsType {
+		value, err := decodeNamedArgs(o, capsle, expectedType)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%w: parameter %d: %w", ErrInvalidParameter, index, err)
+		}
+		return value, nil
+	}
+
+	value, err := processRpcDataCapsleToGoValue(capsle, expectedType)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%w: parameter %d: %w", ErrInvalidParameter, index, err)
+	}
+	if !value.Type().AssignableTo(expectedType) {
+		return reflect.Value{}, fmt.Errorf("%w: parameter %d: %s transmitted, expected %s", ErrInvalidParameter, index, value.Type(), expectedType)
+	}
+	return value, nil
+}
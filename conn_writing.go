@@ -2,13 +2,23 @@ package bngsocket
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"hash/crc32"
+	"log/slog"
 )
 
 // writeBytesIntoSocketConn sendet die gegebenen Daten in 1024-Byte-Chunks über die Socket-Verbindung des BngConn-Objekts.
 // Die Funktion teilt die Daten in kleinere Teile auf, sendet jeden Chunk mit dem Typ 'M' (Message) und wartet auf eine ACK-Bestätigung.
+// Wurde mit der Gegenseite eine Kompression ausgehandelt, werden ausreichend große Nachrichten vorher komprimiert.
 // Nach dem Senden aller Chunks wird ein EndTransfer ('E') gesendet und erneut auf eine ACK-Bestätigung gewartet.
 //
+// Ist eine Integritätsprüfung konfiguriert, werden stattdessen die Frames 'm' und 'e' verwendet, welche eine
+// CRC32 Checksumme enthalten. Meldet die Gegenseite eine fehlerhafte Checksumme (NACK), wird der Chunk bzw.
+// die gesamte Nachricht im Modus FrameIntegrityRetransmit erneut gesendet.
+//
+// Es wird immer nur eine Nachricht gleichzeitig übertragen, damit die ACKs eindeutig zugeordnet werden können.
+//
 // Parameter:
 //   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
 //   - data []byte: Die zu sendenden Daten.
@@ -16,102 +26,225 @@ import (
 // Rückgabe:
 //   - error: Ein Fehler, falls beim Senden der Daten ein Problem aufgetreten ist, ansonsten nil.
 func writeBytesIntoSocketConn(o *BngConn, data []byte) error {
-	const chunkSize = 1024 // Maximale Größe eines Chunks in Bytes
+	// Die Daten werden komprimiert, sofern dies mit der Gegenseite ausgehandelt wurde
+	data, err := compressOutgoingPayload(o, data)
+	if err != nil {
+		return err
+	}
+
+	// Es wird immer nur eine Nachricht zur selben Zeit übertragen
+	o.transferMutex.Lock()
+	defer o.transferMutex.Unlock()
 
 	// Gesamtlänge der Daten
-	totalLength := len(data)
-	_DebugPrint(fmt.Sprintf("BngConn(%s): Sending %d bytes in %d-byte chunks", o._innerhid, totalLength, chunkSize))
+	o.logger.Debug("Sending message", slog.Int("length", len(data)), slog.Int("chunk_size", frameChunkSize))
+
+	// Die Nachricht wird übertragen, im Retransmit Modus wird die Nachricht bei einem NACK auf das ET erneut gesendet
+	for attempt := 0; ; attempt++ {
+		err := writeMessageFrames(o, data)
+		if err == nil {
+			return nil
+		}
+
+		// Es wird geprüft ob die Nachricht erneut gesendet werden darf
+		if !errors.Is(err, ErrFrameNACK) || !canRetransmitFrame(o, attempt) {
+			writeProcessErrorHandling(o, err)
+			return err
+		}
+
+		o.logger.Warn("NACK for ET received, retransmitting message", logFrame('e'))
+	}
+}
+
+// writeMessageFrames sendet alle Chunks einer Nachricht sowie das abschließende EndTransfer.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - data []byte: Die zu sendenden Daten.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls beim Senden der Daten ein Problem aufgetreten ist, ansonsten nil.
+func writeMessageFrames(o *BngConn, data []byte) error {
+	withChecksum := o.config.FrameIntegrity != FrameIntegrityNone
 
 	// Aufteilen und Senden der Daten
-	for start := 0; start < totalLength; start += chunkSize {
-		end := start + chunkSize
+	totalLength := len(data)
+	for start := 0; start < totalLength; start += frameChunkSize {
+		end := start + frameChunkSize
 		if end > totalLength {
 			end = totalLength // Der letzte Chunk hat möglicherweise weniger als 1024 Bytes
 		}
 
-		chunk := data[start:end]
+		// Der Chunk wird gesendet, bei einem NACK wird der Chunk erneut gesendet
+		for attempt := 0; ; attempt++ {
+			if err := writeChunkFrame(o, data[start:end], withChecksum); err != nil {
+				return err
+			}
 
-		// Kritischer Abschnitt: Sperre den Mutex
-		o.connMutex.Lock()
-		defer o.connMutex.Unlock()
+			o.logger.Debug("Chunk sent", logFrame(chunkFrameType(withChecksum)), slog.Int("start", start), slog.Int("end", end))
 
-		// Schreibe den Typ 'M' (Message)
-		if err := o.writer.WriteByte('M'); err != nil {
-			errmsg := fmt.Errorf("%w: %v", ErrWriteMessageType, err)
-			writeProcessErrorHandling(o, errmsg)
-			return errmsg
-		}
+			// Warte auf ACK
+			err := waitForFrameACK(o)
+			if err == nil {
+				break
+			}
+
+			// Es wird geprüft ob der Chunk erneut gesendet werden darf
+			if !errors.Is(err, ErrFrameNACK) || !canRetransmitFrame(o, attempt) {
+				return fmt.Errorf("%w for chunk [%d:%d]: %v", ErrWaitForACK, start, end, err)
+			}
 
-		// Schreibe die Länge des Chunks (Big-Endian)
-		chunkLength := uint32(len(chunk))
-		if err := binary.Write(o.writer, binary.BigEndian, chunkLength); err != nil {
-			errmsg := fmt.Errorf("%w: %v", ErrWriteChunkLength, err)
-			writeProcessErrorHandling(o, errmsg)
-			return errmsg
+			o.logger.Warn("NACK for chunk received, retransmitting", logFrame('m'), slog.Int("start", start), slog.Int("end", end))
 		}
+	}
 
-		// Schreibe den Chunk selbst
-		bytesToWrite := len(chunk)
-		for bytesWritten := 0; bytesWritten < bytesToWrite; {
-			n, err := o.writer.Write(chunk[bytesWritten:])
-			if err != nil {
-				errmsg := fmt.Errorf("%w: %v", ErrWriteChunk, err)
-				writeProcessErrorHandling(o, errmsg)
-				return errmsg
-			}
+	// Senden von EndTransfer (ET)
+	if err := writeEndTransferFrame(o, data, withChecksum); err != nil {
+		return err
+	}
 
-			bytesWritten += n
-			if n == 0 {
-				errmsg := fmt.Errorf("%w: no further bytes written, connection may be broken", ErrWriteChunk)
-				writeProcessErrorHandling(o, errmsg)
-				return errmsg
-			}
+	o.logger.Debug("ET sent", logFrame(endTransferFrameType(withChecksum)))
+
+	// Warten auf ACK für ET
+	if err := waitForFrameACK(o); err != nil {
+		return fmt.Errorf("%w after ET: %w", ErrWaitForACK, err)
+	}
+
+	return nil
+}
+
+// writeChunkFrame schreibt einen einzelnen Chunk ('M' bzw. 'm' mit Checksumme) in den Writer.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - chunk []byte: Die Daten des Chunks.
+//   - withChecksum bool: Gibt an ob eine CRC32 Checksumme angehängt werden soll.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls beim Schreiben ein Problem aufgetreten ist, ansonsten nil.
+func writeChunkFrame(o *BngConn, chunk []byte, withChecksum bool) error {
+	// Kritischer Abschnitt: Sperre den Writer
+	o.writerMutex.Lock()
+	defer o.writerMutex.Unlock()
+
+	// Schreibe den Typ 'M' (Message) bzw. 'm' (Message mit Checksumme)
+	if err := o.writer.WriteByte(chunkFrameType(withChecksum)); err != nil {
+		return fmt.Errorf("%w: %v", ErrWriteMessageType, err)
+	}
+
+	// Schreibe die Länge des Chunks (Big-Endian)
+	chunkLength := uint32(len(chunk))
+	if err := binary.Write(o.writer, binary.BigEndian, chunkLength); err != nil {
+		return fmt.Errorf("%w: %v", ErrWriteChunkLength, err)
+	}
+
+	// Schreibe den Chunk selbst
+	bytesToWrite := len(chunk)
+	for bytesWritten := 0; bytesWritten < bytesToWrite; {
+		n, err := o.writer.Write(chunk[bytesWritten:])
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrWriteChunk, err)
 		}
 
-		// Flush die Daten
-		if err := o.writer.Flush(); err != nil {
-			errmsg := fmt.Errorf("%w: %v", ErrFlushWriter, err)
-			writeProcessErrorHandling(o, errmsg)
-			return errmsg
+		bytesWritten += n
+		if n == 0 {
+			return fmt.Errorf("%w: no further bytes written, connection may be broken", ErrWriteChunk)
 		}
+	}
 
-		_DebugPrint(fmt.Sprintf("BngConn(%s): Chunk [%d:%d] sent", o._innerhid, start, end))
+	// Schreibe die Checksumme über Längenangabe und Chunk (Big-Endian)
+	if withChecksum {
+		if err := binary.Write(o.writer, binary.BigEndian, chunkChecksum(chunkLength, chunk)); err != nil {
+			return fmt.Errorf("%w: %v", ErrWriteChecksum, err)
+		}
+	}
 
-		// Mutex freigeben, bevor auf ACK gewartet wird
-		o.connMutex.Unlock()
-		defer o.connMutex.Lock()
+	// Flush die Daten
+	if err := o.writer.Flush(); err != nil {
+		return fmt.Errorf("%w: %v", ErrFlushWriter, err)
+	}
 
-		// Warte auf ACK
-		if err := o.ackHandle.WaitOfACK(); err != nil {
-			errmsg := fmt.Errorf("%w for chunk [%d:%d]: %v", ErrWaitForACK, start, end, err)
-			writeProcessErrorHandling(o, errmsg)
-			return errmsg
-		}
+	// Der Frame wird erfasst (Typ + Länge + Daten + ggf. Checksumme)
+	frameSize := 5 + len(chunk)
+	if withChecksum {
+		frameSize += 4
 	}
+	o.metrics.FrameSent(chunkFrameType(withChecksum), frameSize)
 
-	// Senden von EndTransfer (ET)
-	o.connMutex.Lock()
-	defer o.connMutex.Unlock()
+	return nil
+}
+
+// writeEndTransferFrame schreibt das Ende einer Nachricht ('E' bzw. 'e' mit Checksumme der gesamten Nachricht).
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//   - data []byte: Die gesamte Nachricht, über welche die Checksumme berechnet wird.
+//   - withChecksum bool: Gibt an ob eine CRC32 Checksumme angehängt werden soll.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls beim Schreiben ein Problem aufgetreten ist, ansonsten nil.
+func writeEndTransferFrame(o *BngConn, data []byte, withChecksum bool) error {
+	// Kritischer Abschnitt: Sperre den Writer
+	o.writerMutex.Lock()
+	defer o.writerMutex.Unlock()
+
+	// Schreibe den Typ 'E' (EndTransfer) bzw. 'e' (EndTransfer mit Checksumme)
+	if err := o.writer.WriteByte(endTransferFrameType(withChecksum)); err != nil {
+		return fmt.Errorf("%w: %v", ErrWriteEndTransfer, err)
+	}
 
-	if err := o.writer.WriteByte('E'); err != nil {
-		errmsg := fmt.Errorf("%w: %v", ErrWriteEndTransfer, err)
-		writeProcessErrorHandling(o, errmsg)
-		return errmsg
+	// Schreibe die Checksumme der gesamten Nachricht (Big-Endian)
+	if withChecksum {
+		if err := binary.Write(o.writer, binary.BigEndian, crc32.ChecksumIEEE(data)); err != nil {
+			return fmt.Errorf("%w: %v", ErrWriteChecksum, err)
+		}
 	}
+
 	if err := o.writer.Flush(); err != nil {
-		errmsg := fmt.Errorf("%w after ET: %v", ErrFlushWriter, err)
-		writeProcessErrorHandling(o, errmsg)
-		return errmsg
+		return fmt.Errorf("%w after ET: %v", ErrFlushWriter, err)
 	}
 
-	_DebugPrint(fmt.Sprintf("BngConn(%s): ET sent", o._innerhid))
-
-	// Warten auf ACK für ET
-	if err := o.ackHandle.WaitOfACK(); err != nil {
-		errmsg := fmt.Errorf("%w after ET: %v", ErrWaitForACK, err)
-		writeProcessErrorHandling(o, errmsg)
-		return errmsg
+	// Der Frame wird erfasst (Typ + ggf. Checksumme)
+	if withChecksum {
+		o.metrics.FrameSent(endTransferFrameType(withChecksum), 5)
+	} else {
+		o.metrics.FrameSent(endTransferFrameType(withChecksum), 1)
 	}
 
 	return nil
 }
+
+// canRetransmitFrame gibt an ob nach einem NACK ein weiterer Sendeversuch unternommen werden darf.
+func canRetransmitFrame(o *BngConn, attempt int) bool {
+	return o.config.FrameIntegrity == FrameIntegrityRetransmit && attempt < o.config.MaxFrameRetransmits
+}
+
+// chunkFrameType gibt den Typ eines Chunk Frames zurück ('M' bzw. 'm' mit Checksumme).
+func chunkFrameType(withChecksum bool) byte {
+	if withChecksum {
+		return 'm'
+	}
+	return 'M'
+}
+
+// endTransferFrameType gibt den Typ eines EndTransfer Frames zurück ('E' bzw. 'e' mit Checksumme).
+func endTransferFrameType(withChecksum bool) byte {
+	if withChecksum {
+		return 'e'
+	}
+	return 'E'
+}
+
+// chunkChecksum berechnet die CRC32 Checksumme eines 'm' Frames. Die Checksumme umfasst
+// neben den Daten auch die Längenangabe, damit eine beschädigte Länge ebenfalls erkannt wird.
+//
+// Parameter:
+//   - chunkLength uint32: Die im Frame übertragene Länge des Chunks.
+//   - chunk []byte: Die Daten des Chunks.
+//
+// Rückgabe:
+//   - uint32: Die CRC32 Checksumme über Längenangabe und Daten.
+func chunkChecksum(chunkLength uint32, chunk []byte) uint32 {
+	header := binary.BigEndian.AppendUint32(nil, chunkLength)
+	return crc32.Update(crc32.ChecksumIEEE(header), crc32.IEEETable, chunk)
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/custodia-cenv/bngsocket-go/transport"
-	"github.com/vmihailenco/msgpack/v5"
 )
 
 // writePacketACK sendet ein ACK (Acknowledgment) über die Socket-Verbindung des BngConn-Objekts.
@@ -32,7 +31,38 @@ func writePacketACK(o *BngConn) error {
 		return fmt.Errorf("%w: %v", ErrFlushACK, err)
 	}
 
-	_DebugPrint(fmt.Sprintf("BngConn(%s): ACK sent", o._innerhid))
+	o.metrics.FrameSent('A', 3)
+	o.logger.Debug("ACK sent", logFrame('A'))
+	return nil
+}
+
+// writePacketNACK sendet ein NACK (Negative Acknowledgment) über die Socket-Verbindung des BngConn-Objekts.
+// Das NACK teilt der Gegenseite mit, dass der zuletzt empfangene Frame eine fehlerhafte Checksumme hatte
+// und erneut gesendet werden muss.
+//
+// Parameter:
+//   - o *BngConn: Ein Zeiger auf das BngConn-Objekt, das die Socket-Verbindung verwaltet.
+//
+// Rückgabe:
+//   - error: Ein Fehler, falls beim Senden des NACKs ein Problem aufgetreten ist, ansonsten nil.
+func writePacketNACK(o *BngConn) error {
+	o.writerMutex.Lock()
+	defer o.writerMutex.Unlock()
+
+	nack := []byte("ANK")
+
+	// Schreibe das NACK
+	if _, err := o.writer.Write(nack); err != nil {
+		return fmt.Errorf("%w: %v", ErrWriteNACK, err)
+	}
+
+	// Flushe den Writer
+	if err := o.writer.Flush(); err != nil {
+		return fmt.Errorf("%w: %v", ErrFlushACK, err)
+	}
+
+	o.metrics.FrameSent('A', 3)
+	o.logger.Debug("NACK sent", logFrame('A'))
 	return nil
 }
 
@@ -47,7 +77,7 @@ func writePacketACK(o *BngConn) error {
 //   - error: Ein Fehler, falls beim Serialisieren oder Senden der Daten ein Problem aufgetreten ist, ansonsten nil.
 func convertAndWriteBytesIntoChan(conn *BngConn, data interface{}) error {
 	// Den RpcRequest in Bytes serialisieren.
-	bdata, err := msgpack.Marshal(data)
+	bdata, err := marshalMessage(conn, data)
 	if err != nil {
 		return fmt.Errorf("channelWriteACK[0]: %s", err.Error())
 	}
@@ -157,7 +187,7 @@ func channelDataTransport(socket *BngConn, data []byte, channelSessionId string)
 	}
 
 	// Die Daten in Bytes serialisieren.
-	bdata, err := msgpack.Marshal(rt)
+	bdata, err := marshalMessage(socket, rt)
 	if err != nil {
 		return 0, -1, fmt.Errorf("channelDataTransport[0]: %s", err.Error())
 	}
@@ -208,11 +238,12 @@ func channelWriteACK(conn *BngConn, pid uint64, sessionId string) error {
 //
 // Rückgabe:
 //   - error: Ein Fehler, falls beim Serialisieren oder Senden der Daten ein Problem aufgetreten ist, ansonsten nil.
-func socketWriteRpcSuccessResponse(conn *BngConn, value []*transport.RpcDataCapsle, id string) error {
+func socketWriteRpcSuccessResponse(conn *BngConn, value []*transport.RpcDataCapsle, id string, trailer map[string]string) error {
 	rt := &transport.RpcResponse{
-		Type:   "rpcres",
-		Id:     id,
-		Return: value,
+		Type:     "rpcres",
+		Id:       id,
+		Return:   value,
+		Metadata: trailer,
 	}
 
 	err := convertAndWriteBytesIntoChan(conn, rt)
@@ -234,14 +265,8 @@ func socketWriteRpcSuccessResponse(conn *BngConn, value []*transport.RpcDataCaps
 //
 // Rückgabe:
 //   - error: Ein Fehler, falls beim Senden der Antwort ein Problem aufgetreten ist, ansonsten nil.
-func socketWriteRpcErrorResponse(conn *BngConn, errstr string, id string) error {
-	rt := &transport.RpcResponse{
-		Type:  "rpcres",
-		Id:    id,
-		Error: errstr,
-	}
-
-	err := convertAndWriteBytesIntoChan(conn, rt)
+func socketWriteRpcErrorResponse(conn *BngConn, callErr error, id string, trailer map[string]string) error {
+	err := convertAndWriteBytesIntoChan(conn, newRpcErrorResponse(id, callErr, trailer))
 	if err != nil {
 		return err
 	}
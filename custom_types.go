@@ -0,0 +1,195 @@
+package bngsocket
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/custodia-cenv/bngsocket-go/transport"
+)
+
+// Transportdatentypen eigener Typen beginnen mit diesem Präfix, gefolgt vom Namen des Typs
+const customTypePrefix = "custom:"
+
+// _CustomType beschreibt, wie ein eigener Typ für die Übertragung kodiert wird.
+type _CustomType struct {
+	name   string                                    // Name des Typs, welcher im RpcDataCapsle übertragen wird
+	encode func(value reflect.Value) ([]byte, error) // Kodiert einen Wert des Typs
+	decode func(data []byte) (reflect.Value, error)  // Dekodiert einen Wert des Typs
+}
+
+// Speichert alle registrierten eigenen Typen
+var customTypeRegistry = struct {
+	mu    sync.RWMutex
+	types map[reflect.Type]*_CustomType
+}{
+	types: make(map[reflect.Type]*_CustomType),
+}
+
+// Die Typen der Marshaler Interfaces
+var (
+	binaryMarshalerType   = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+	textMarshalerType     = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType   = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// RegisterType registriert einen eigenen Typ, welcher anschließend direkt als Parameter oder Rückgabewert
+// einer RPC Funktion verwendet werden kann. Typen, welche encoding.BinaryMarshaler oder encoding.TextMarshaler
+// (sowie das passende Unmarshaler Interface) implementieren, müssen nicht registriert werden.
+//
+// Parameter:
+//   - encode func(T) ([]byte, error): Kodiert einen Wert des Typs.
+//   - decode func([]byte) (T, error): Dekodiert einen Wert des Typs.
+//
+// Rückgabe:
+//   - error: ErrTypeAlreadyRegistered, falls der Typ bereits registriert wurde, ansonsten nil.
+func RegisterType[T any](encode func(T) ([]byte, error), decode func([]byte) (T, error)) error {
+	// Es müssen beide Funktionen vorhanden sein
+	if encode == nil || decode == nil {
+		return fmt.Errorf("bngsocket->RegisterType[0]: encode and decode functions required")
+	}
+
+	// Interfaces können nicht registriert werden, da der konkrete Typ nicht bekannt ist
+	t := reflect.TypeFor[T]()
+	if t.Kind() == reflect.Interface {
+		return fmt.Errorf("bngsocket->RegisterType[1]: interface type %s not allowed", t)
+	}
+
+	customType := &_CustomType{
+		name: customTypeName(t),
+		encode: func(value reflect.Value) ([]byte, error) {
+			return encode(value.Interface().(T))
+		},
+		decode: func(data []byte) (reflect.Value, error) {
+			value, err := decode(data)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(&value).Elem(), nil
+		},
+	}
+
+	customTypeRegistry.mu.Lock()
+	defer customTypeRegistry.mu.Unlock()
+
+	// Es wird geprüft ob der Typ bereits registriert wurde
+	if _, found := customTypeRegistry.types[t]; found {
+		return fmt.Errorf("bngsocket->RegisterType[2]: %s: %w", t, ErrTypeAlreadyRegistered)
+	}
+	customTypeRegistry.types[t] = customType
+
+	return nil
+}
+
+// customTypeName gibt den Namen zurück, unter dem ein eigener Typ übertragen wird.
+func customTypeName(t reflect.Type) string {
+	if t.Name() != "" && t.PkgPath() != "" {
+		return t.PkgPath() + "." + t.Name()
+	}
+	return t.String()
+}
+
+// lookupCustomType gibt die Kodierung eines eigenen Typs zurück.
+// Registrierte Typen haben Vorrang vor den Marshaler Interfaces.
+//
+// Parameter:
+//   - t reflect.Type: Der Typ des Wertes.
+//
+// Rückgabe:
+//   - *_CustomType: Die Kodierung des Typs.
+//   - bool: Gibt an ob es sich um einen eigenen Typ handelt.
+func lookupCustomType(t reflect.Type) (*_CustomType, bool) {
+	if t == nil {
+		return nil, false
+	}
+
+	customTypeRegistry.mu.RLock()
+	customType, found := customTypeRegistry.types[t]
+	customTypeRegistry.mu.RUnlock()
+	if found {
+		return customType, true
+	}
+
+	return marshalerCustomType(t)
+}
+
+// marshalerCustomType erzeugt die Kodierung eines Typs, welcher die Binary oder Text Marshaler Interfaces implementiert.
+// Der Typ muss den Marshaler und ein Zeiger auf den Typ den Unmarshaler implementieren.
+func marshalerCustomType(t reflect.Type) (*_CustomType, bool) {
+	ptrType := reflect.PointerTo(t)
+	switch {
+	case t.Implements(binaryMarshalerType) && ptrType.Implements(binaryUnmarshalerType):
+		return &_CustomType{
+			name: customTypeName(t),
+			encode: func(value reflect.Value) ([]byte, error) {
+				return value.Interface().(encoding.BinaryMarshaler).MarshalBinary()
+			},
+			decode: func(data []byte) (reflect.Value, error) {
+				value := reflect.New(t)
+				if err := value.Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(data); err != nil {
+					return reflect.Value{}, err
+				}
+				return value.Elem(), nil
+			},
+		}, true
+	case t.Implements(textMarshalerType) && ptrType.Implements(textUnmarshalerType):
+		return &_CustomType{
+			name: customTypeName(t),
+			encode: func(value reflect.Value) ([]byte, error) {
+				return value.Interface().(encoding.TextMarshaler).MarshalText()
+			},
+			decode: func(data []byte) (reflect.Value, error) {
+				value := reflect.New(t)
+				if err := value.Interface().(encoding.TextUnmarshaler).UnmarshalText(data); err != nil {
+					return reflect.Value{}, err
+				}
+				return value.Elem(), nil
+			},
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// isCustomType gibt an ob es sich um einen eigenen Typ handelt.
+func isCustomType(t reflect.Type) bool {
+	_, found := lookupCustomType(t)
+	return found
+}
+
+// encodeCustomValue wandelt einen Wert eines eigenen Typs in ein RpcDataCapsle um.
+// Die Daten werden unabhängig vom Codec der Verbindung in Encoded übertragen.
+func encodeCustomValue(customType *_CustomType, value reflect.Value) (*transport.RpcDataCapsle, error) {
+	encoded, err := customType.encode(value)
+	if err != nil {
+		return nil, fmt.Errorf("encodeCustomValue[0]: encoding %s failed: %w", customType.name, err)
+	}
+	return &transport.RpcDataCapsle{Type: customTypePrefix + customType.name, Encoded: encoded, Kind: value.Type().String()}, nil
+}
+
+// decodeCustomValue liest einen Wert eines eigenen Typs aus einem RpcDataCapsle ein.
+//
+// Parameter:
+//   - capsle *transport.RpcDataCapsle: Der übertragene Wert.
+//   - expectedType reflect.Type: Der erwartete Typ.
+//
+// Rückgabe:
+//   - reflect.Value: Der eingelesene Wert.
+//   - error: Ein Fehler, falls der Typ nicht übereinstimmt oder der Wert nicht dekodiert werden konnte, ansonsten nil.
+func decodeCustomValue(capsle *transport.RpcDataCapsle, expectedType reflect.Type) (reflect.Value, error) {
+	// Der übertragene Typ muss dem erwarteten Typ entsprechen
+	customType, found := lookupCustomType(expectedType)
+	if !found || strings.TrimPrefix(capsle.Type, customTypePrefix) != customType.name {
+		return reflect.Value{}, fmt.Errorf("decodeCustomValue[0]: %s transmitted, expected %s", capsle.Type, expectedType)
+	}
+
+	// Der Wert wird dekodiert
+	value, err := customType.decode(capsle.Encoded)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("decodeCustomValue[1]: decoding %s failed: %w", customType.name, err)
+	}
+	return value, nil
+}
@@ -2,6 +2,7 @@ package bngsocket
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 )
 
@@ -29,13 +30,151 @@ var (
 	ErrWriteACK                    = errors.New("failed to write ACK")
 	ErrFlushACK                    = errors.New("failed to flush ACK writer")
 	ErrUnsupportedSocketType       = errors.New("unsupported socket type")
+	ErrUnsupportedCompression      = errors.New("unsupported compression")
+	ErrUnsupportedCodec            = errors.New("unsupported codec")
+	ErrTypeAlreadyRegistered       = errors.New("type already registered")
+	ErrInvalidParameter            = errors.New("invalid rpc parameter")
+	ErrValueOutOfRange             = errors.New("value out of range")
+	ErrTypeMismatch                = errors.New("rpc type mismatch")
+	ErrSignatureMismatch           = errors.New("rpc signature mismatch")
+	ErrInvalidRetryPolicy          = errors.New("invalid retry policy")
+	ErrDecompressPayload           = errors.New("failed to decompress payload")
+	ErrUnsupportedFrameIntegrity   = errors.New("unsupported frame integrity mode")
+	ErrWriteChecksum               = errors.New("failed to write checksum")
+	ErrReadChecksum                = errors.New("failed to read checksum")
+	ErrChecksumMismatch            = errors.New("frame checksum mismatch")
+	ErrFrameTooLarge               = errors.New("frame exceeds maximum chunk size")
+	ErrFrameNACK                   = errors.New("frame was rejected by peer (NACK)")
+	ErrWriteNACK                   = errors.New("failed to write NACK")
+	ErrInvalidKeepalive            = errors.New("invalid keepalive configuration")
+	ErrWritePing                   = errors.New("failed to write ping")
+	ErrWritePong                   = errors.New("failed to write pong")
+	ErrReadPing                    = errors.New("failed to read ping")
+	ErrPeerTimeout                 = errors.New("peer did not answer keepalive pings")
+	ErrWriteGoAway                 = errors.New("failed to write GOAWAY")
+	ErrPeerGoingAway               = errors.New("peer is going away")
+	ErrConnectionDraining          = errors.New("connection is shutting down")
+	ErrInvalidTraceParent          = errors.New("invalid traceparent")
+	ErrRpcStreamMismatch           = errors.New("rpc function does not match call type (stream/unary)")
+	ErrStreamClosed                = errors.New("stream was closed")
+	ErrStreamSendClosed            = errors.New("stream send direction was closed")
+	ErrInvalidTopic                = errors.New("invalid topic")
+	ErrReservedFunctionName        = errors.New("function name is reserved")
+	ErrFunctionNotRegistered       = errors.New("function is not registered")
+	ErrCallbackReleased            = errors.New("callback was released")
+	ErrChannelClosed               = errors.New("channel was closed")
+	ErrBatchTooLarge               = errors.New("rpc batch exceeds maximum size")
+	ErrPeerCredentialsUnsupported  = errors.New("peer credentials are not supported for this connection")
 )
 
+// RpcError ist ein Fehler mit einem stabilen Code, welcher der Gegenseite zusammen mit der Fehlermeldung
+// übertragen wird. Gibt eine aufgerufene Funktion einen RpcError zurück, erhält der Aufrufer einen RpcError
+// mit dem selben Code, welcher sich über errors.Is unabhängig von der Fehlermeldung vergleichen lässt
+// (z.B. in RetryPolicy.RetryableErrors).
+type RpcError struct {
+	Code    string // Stabiler Code des Fehlers, z.B. "busy"
+	Message string // Beschreibung des Fehlers
+}
+
+// NewRpcError erzeugt einen Fehler mit einem stabilen Code.
+//
+// Parameter:
+//   - code string: Der Code des Fehlers.
+//   - message string: Die Beschreibung des Fehlers.
+//
+// Rückgabe:
+//   - *RpcError: Der Fehler.
+func NewRpcError(code string, message string) *RpcError {
+	return &RpcError{Code: code, Message: message}
+}
+
+// Error gibt die Beschreibung des Fehlers zurück, ist keine vorhanden wird der Code zurückgegeben.
+func (e *RpcError) Error() string {
+	if e.Message == "" {
+		return e.Code
+	}
+	return e.Message
+}
+
+// Is gibt an ob target ein RpcError mit dem selben Code ist.
+func (e *RpcError) Is(target error) bool {
+	t, ok := target.(*RpcError)
+	return ok && t.Code != "" && t.Code == e.Code
+}
+
+// Stabile Codes der Fehler, welche der Gegenseite übertragen werden
+var rpcErrorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrUnkownRpcFunction, "unknown_function"},
+	{ErrPeerGoingAway, "going_away"},
+	{ErrRpcStreamMismatch, "stream_mismatch"},
+	{ErrSignatureMismatch, "signature_mismatch"},
+	{ErrInvalidParameter, "invalid_parameter"},
+	{ErrBatchTooLarge, "batch_too_large"},
+	{ErrInvalidTopic, "invalid_topic"},
+}
+
+// rpcErrorCode gibt den Code zurück, welcher für den Fehler übertragen wird, ohne Code wird "" zurückgegeben.
+func rpcErrorCode(err error) string {
+	var rpcErr *RpcError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code
+	}
+	for _, item := range rpcErrorCodes {
+		if errors.Is(err, item.err) {
+			return item.code
+		}
+	}
+	return ""
+}
+
+// processRpcError wandelt den Fehler einer RPC Antwort anhand seines Codes zurück in einen Go Fehler.
+// Fehler ohne Code (z.B. von einer Gegenseite ohne Codes) werden anhand ihrer Fehlermeldung zugeordnet.
+//
+// Parameter:
+//   - code string: Der übertragene Code des Fehlers.
+//   - errString string: Die übertragene Fehlermeldung.
+//
+// Rückgabe:
+//   - error: Der Fehler, bekannte Codes werden dem jeweiligen Fehler dieses Pakets zugeordnet, ansonsten ein *RpcError.
+func processRpcError(code string, errString string) error {
+	if code == "" {
+		return processError(errString)
+	}
+	for _, item := range rpcErrorCodes {
+		if item.code != code {
+			continue
+		}
+		if item.err == ErrUnkownRpcFunction {
+			return ErrUnkownRpcFunction
+		}
+		if strings.HasPrefix(errString, item.err.Error()) {
+			return fmt.Errorf("%w%s", item.err, strings.TrimPrefix(errString, item.err.Error()))
+		}
+		return fmt.Errorf("%w: %s", item.err, errString)
+	}
+	return &RpcError{Code: code, Message: errString}
+}
+
 // Wandelt einen Fehler welcher mittels String übertragen wurde zurück in einen Go Fehler
 func processError(errString string) error {
 	switch {
 	case strings.Contains(ErrUnkownRpcFunction.Error(), errString):
 		return ErrUnkownRpcFunction
+	case errString == ErrPeerGoingAway.Error():
+		return ErrPeerGoingAway
+	case errString == ErrRpcStreamMismatch.Error():
+		return ErrRpcStreamMismatch
+	case strings.HasPrefix(errString, ErrSignatureMismatch.Error()):
+		return fmt.Errorf("%w%s", ErrSignatureMismatch, strings.TrimPrefix(errString, ErrSignatureMismatch.Error()))
+	case strings.HasPrefix(errString, ErrInvalidParameter.Error()):
+		return fmt.Errorf("%w%s", ErrInvalidParameter, strings.TrimPrefix(errString, ErrInvalidParameter.Error()))
+	case strings.HasPrefix(errString, ErrBatchTooLarge.Error()):
+		return fmt.Errorf("%w%s", ErrBatchTooLarge, strings.TrimPrefix(errString, ErrBatchTooLarge.Error()))
+	case strings.HasPrefix(errString, ErrInvalidTopic.Error()):
+		return fmt.Errorf("%w%s", ErrInvalidTopic, strings.TrimPrefix(errString, ErrInvalidTopic.Error()))
 	default:
 		return errors.New(errString)
 	}
@@ -0,0 +1,79 @@
+package bngsocket
+
+import (
+	"errors"
+	"fmt"
+)
+
+// NewBngHub erzeugt einen neuen, leeren Hub.
+// Ein Hub wird z.B. auf Seite eines Servers verwendet, um eine Nachricht an alle Verbindungen zu verteilen,
+// deren Gegenseite ein passendes Topic abonniert hat.
+func NewBngHub() *BngHub {
+	return &BngHub{conns: make(map[*BngConn]struct{})}
+}
+
+// Add fügt eine Verbindung zum Hub hinzu. Wird die Verbindung beendet, wird sie automatisch entfernt.
+//
+// Parameter:
+//   - conn *BngConn: Die hinzuzufügende Verbindung.
+func (h *BngHub) Add(conn *BngConn) {
+	h.mu.Lock()
+	if _, found := h.conns[conn]; found {
+		h.mu.Unlock()
+		return
+	}
+	h.conns[conn] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		<-conn.Done()
+		h.Remove(conn)
+	}()
+}
+
+// Remove entfernt eine Verbindung aus dem Hub.
+//
+// Parameter:
+//   - conn *BngConn: Die zu entfernende Verbindung.
+func (h *BngHub) Remove(conn *BngConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.conns, conn)
+}
+
+// Len gibt die Anzahl der Verbindungen des Hubs zurück.
+func (h *BngHub) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.conns)
+}
+
+// Publish veröffentlicht eine Nachricht an alle Verbindungen des Hubs, deren Gegenseite ein passendes Topic abonniert hat.
+//
+// Parameter:
+//   - topic string: Das Topic der Nachricht, Wildcards sind nicht zulässig.
+//   - value interface{}: Der zu veröffentlichende Wert.
+//
+// Rückgabe:
+//   - error: Die Fehler aller fehlgeschlagenen Verbindungen (errors.Join), ansonsten nil.
+func (h *BngHub) Publish(topic string, value interface{}) error {
+	if err := validateTopic(topic, false); err != nil {
+		return err
+	}
+
+	// Die Verbindungen werden kopiert, damit beim Senden keine Sperre gehalten wird
+	h.mu.Lock()
+	conns := make([]*BngConn, 0, len(h.conns))
+	for conn := range h.conns {
+		conns = append(conns, conn)
+	}
+	h.mu.Unlock()
+
+	var errs []error
+	for _, conn := range conns {
+		if err := conn.Publish(topic, value); err != nil {
+			errs = append(errs, fmt.Errorf("bngsocket->BngHub.Publish: %s: %w", conn._innerhid, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -10,8 +10,9 @@ import (
 	"github.com/google/uuid"
 )
 
-func _NewBaseBngSocketObject(socket net.Conn) *BngConn {
+func _NewBaseBngSocketObject(socket net.Conn, config *BngConnConfig) *BngConn {
 	bngConn := &BngConn{
+		config:                   config,
 		conn:                     socket,
 		writer:                   bufio.NewWriter(socket),
 		reader:                   bufio.NewReader(socket),
@@ -22,12 +23,30 @@ func _NewBaseBngSocketObject(socket net.Conn) *BngConn {
 		closed:                   newSafeBool(false),
 		closing:                  newSafeBool(false),
 		writerMutex:              new(sync.Mutex),
+		transferMutex:            new(sync.Mutex),
 		functions:                newSafeMap[string, reflect.Value](),
+		hiddenFunctions:          newSafeMap[string, reflect.Value](),
 		openRpcRequests:          _SafeMap[string, chan *transport.RpcResponse]{Map: new(sync.Map)},
+		openRpcBatches:           _SafeMap[string, chan *transport.RpcBatchResponse]{Map: new(sync.Map)},
+		idempotency:              newIdempotencyCache(),
 		openChannelListener:      newSafeMap[string, *BngConnChannelListener](),
 		openChannelInstances:     newSafeMap[string, *BngConnChannel](),
 		openChannelJoinProcesses: _SafeMap[string, chan *transport.ChannelRequestResponse]{Map: new(sync.Map)},
 		runningError:             newSafeValue[error](nil),
+		state:                    newConnState(),
+		compressionNegotiated:    newSafeBool(false),
+		negotiatedCodec:          newSafeValue[Codec](nil),
+		draining:                 newSafeBool(false),
+		peerGoingAway:            newSafeBool(false),
+		runningRpcCalls:          newSafeInt(0),
+		localSubscriptions:       newSafeMap[string, *BngSubscription](),
+		remoteSubscriptions:      newSafeMap[string, string](),
+		openSubscribeRequests:    _SafeMap[string, chan *transport.TopicSubscribeAck]{Map: new(sync.Map)},
 	}
+	bngConn.logger = newConnLogger(config.Logger, bngConn._innerhid)
+	bngConn.metrics = newConnMetrics(config.Metrics)
+	bngConn.tracer = newConnTracer(config.Tracer)
+	bngConn.propagator = newConnPropagator(config.Propagator)
+	bngConn.OnStateChange(config.OnStateChange)
 	return bngConn
 }
@@ -0,0 +1,274 @@
+// Package promexport stellt die Kennzahlen einer oder mehrerer BngConn Verbindungen im
+// Prometheus Textformat bereit, ohne die Prometheus Client Bibliothek zu verwenden.
+//
+// Ein Collector wird über BngConnConfig.Metrics an die Verbindungen übergeben und kann
+// anschließend als http.Handler (z.B. unter "/metrics") registriert werden.
+package promexport
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+// DefaultBuckets sind die Obergrenzen (in Sekunden) der Histogramme, sofern keine eigenen angegeben wurden.
+var DefaultBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Seiten eines RPC Aufrufs
+const (
+	sideClient = "client" // Ausgehender Aufruf
+	sideServer = "server" // Eingehender Aufruf
+)
+
+// Collector erfasst die Kennzahlen von BngConn Verbindungen und implementiert bngsocket.Metrics.
+// Ein Collector kann von mehreren Verbindungen gleichzeitig verwendet werden.
+type Collector struct {
+	buckets []float64
+
+	bytesSent     atomic.Uint64
+	bytesReceived atomic.Uint64
+	openChannels  atomic.Int64
+	openListeners atomic.Int64
+
+	mu             sync.Mutex
+	framesSent     map[string]uint64
+	framesReceived map[string]uint64
+	errors         map[string]uint64
+	ackWait        *histogram
+	rpcDurations   map[rpcKey]*histogram
+	rpcErrors      map[rpcKey]uint64
+}
+
+// rpcKey identifiziert die Kennzahlen einer RPC Funktion
+type rpcKey struct {
+	side     string
+	function string
+}
+
+// histogram speichert die Verteilung von Messwerten in festen Buckets
+type histogram struct {
+	counts []uint64 // Anzahl der Werte je Bucket (nicht kumuliert)
+	count  uint64   // Anzahl aller Werte
+	sum    float64  // Summe aller Werte
+}
+
+var _ bngsocket.Metrics = (*Collector)(nil)
+
+// New erzeugt einen Collector mit den DefaultBuckets.
+func New() *Collector {
+	return NewWithBuckets(DefaultBuckets)
+}
+
+// NewWithBuckets erzeugt einen Collector, dessen Histogramme die angegebenen Obergrenzen (in Sekunden) verwenden.
+func NewWithBuckets(buckets []float64) *Collector {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	c := &Collector{
+		buckets:        sorted,
+		framesSent:     make(map[string]uint64),
+		framesReceived: make(map[string]uint64),
+		errors:         make(map[string]uint64),
+		rpcDurations:   make(map[rpcKey]*histogram),
+		rpcErrors:      make(map[rpcKey]uint64),
+	}
+	c.ackWait = c.newHistogram()
+	return c
+}
+
+// FrameSent implementiert bngsocket.Metrics.
+func (c *Collector) FrameSent(frameType byte, size int) {
+	c.bytesSent.Add(uint64(size))
+	c.mu.Lock()
+	c.framesSent[string(frameType)]++
+	c.mu.Unlock()
+}
+
+// FrameReceived implementiert bngsocket.Metrics.
+func (c *Collector) FrameReceived(frameType byte, size int) {
+	c.bytesReceived.Add(uint64(size))
+	c.mu.Lock()
+	c.framesReceived[string(frameType)]++
+	c.mu.Unlock()
+}
+
+// AckWaited implementiert bngsocket.Metrics.
+func (c *Collector) AckWaited(d time.Duration) {
+	c.mu.Lock()
+	c.observe(c.ackWait, d)
+	c.mu.Unlock()
+}
+
+// RpcCallFinished implementiert bngsocket.Metrics.
+func (c *Collector) RpcCallFinished(function string, d time.Duration, err error) {
+	c.observeRpc(rpcKey{side: sideClient, function: function}, d, err)
+}
+
+// RpcRequestServed implementiert bngsocket.Metrics.
+func (c *Collector) RpcRequestServed(function string, d time.Duration, err error) {
+	c.observeRpc(rpcKey{side: sideServer, function: function}, d, err)
+}
+
+// ErrorOccurred implementiert bngsocket.Metrics.
+func (c *Collector) ErrorOccurred(kind string) {
+	c.mu.Lock()
+	c.errors[kind]++
+	c.mu.Unlock()
+}
+
+// ChannelsChanged implementiert bngsocket.Metrics.
+func (c *Collector) ChannelsChanged(delta int) {
+	c.openChannels.Add(int64(delta))
+}
+
+// ListenersChanged implementiert bngsocket.Metrics.
+func (c *Collector) ListenersChanged(delta int) {
+	c.openListeners.Add(int64(delta))
+}
+
+// ServeHTTP gibt die Kennzahlen im Prometheus Textformat (Version 0.0.4) aus.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := c.WriteTo(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// WriteTo schreibt die Kennzahlen im Prometheus Textformat in den Writer.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+
+	writeHeader(&b, "bngsocket_sent_bytes_total", "counter", "Total number of bytes written to the socket.")
+	fmt.Fprintf(&b, "bngsocket_sent_bytes_total %d\n", c.bytesSent.Load())
+	writeHeader(&b, "bngsocket_received_bytes_total", "counter", "Total number of bytes read from the socket.")
+	fmt.Fprintf(&b, "bngsocket_received_bytes_total %d\n", c.bytesReceived.Load())
+
+	c.mu.Lock()
+	writeHeader(&b, "bngsocket_frames_sent_total", "counter", "Total number of frames sent by frame type.")
+	writeLabeledCounters(&b, "bngsocket_frames_sent_total", "frame", c.framesSent)
+	writeHeader(&b, "bngsocket_frames_received_total", "counter", "Total number of frames received by frame type.")
+	writeLabeledCounters(&b, "bngsocket_frames_received_total", "frame", c.framesReceived)
+	writeHeader(&b, "bngsocket_errors_total", "counter", "Total number of connection errors by type.")
+	writeLabeledCounters(&b, "bngsocket_errors_total", "type", c.errors)
+
+	writeHeader(&b, "bngsocket_ack_wait_seconds", "histogram", "Time spent waiting for frame acknowledgements.")
+	c.writeHistogram(&b, "bngsocket_ack_wait_seconds", "", c.ackWait)
+
+	keys := make([]rpcKey, 0, len(c.rpcDurations))
+	for key := range c.rpcDurations {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].side != keys[j].side {
+			return keys[i].side < keys[j].side
+		}
+		return keys[i].function < keys[j].function
+	})
+	writeHeader(&b, "bngsocket_rpc_duration_seconds", "histogram", "Duration of rpc calls by side and function.")
+	for _, key := range keys {
+		c.writeHistogram(&b, "bngsocket_rpc_duration_seconds", rpcLabels(key), c.rpcDurations[key])
+	}
+	writeHeader(&b, "bngsocket_rpc_errors_total", "counter", "Total number of failed rpc calls by side and function.")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "bngsocket_rpc_errors_total{%s} %d\n", rpcLabels(key), c.rpcErrors[key])
+	}
+	c.mu.Unlock()
+
+	writeHeader(&b, "bngsocket_open_channels", "gauge", "Number of currently open channel sessions.")
+	fmt.Fprintf(&b, "bngsocket_open_channels %d\n", c.openChannels.Load())
+	writeHeader(&b, "bngsocket_open_listeners", "gauge", "Number of currently open channel listeners.")
+	fmt.Fprintf(&b, "bngsocket_open_listeners %d\n", c.openListeners.Load())
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// newHistogram erzeugt ein leeres Histogramm mit den Buckets des Collectors
+func (c *Collector) newHistogram() *histogram {
+	return &histogram{counts: make([]uint64, len(c.buckets))}
+}
+
+// observe trägt einen Messwert in das Histogramm ein, c.mu muss gesperrt sein
+func (c *Collector) observe(h *histogram, d time.Duration) {
+	seconds := d.Seconds()
+	h.count++
+	h.sum += seconds
+	for i, bound := range c.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+			return
+		}
+	}
+}
+
+// observeRpc erfasst die Dauer und ggf. den Fehler eines RPC Aufrufs
+func (c *Collector) observeRpc(key rpcKey, d time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h, found := c.rpcDurations[key]
+	if !found {
+		h = c.newHistogram()
+		c.rpcDurations[key] = h
+	}
+	c.observe(h, d)
+	if err != nil {
+		c.rpcErrors[key]++
+	}
+}
+
+// writeHistogram schreibt die kumulierten Buckets, die Summe und die Anzahl eines Histogramms
+func (c *Collector) writeHistogram(b *strings.Builder, name string, labels string, h *histogram) {
+	prefix := ""
+	if labels != "" {
+		prefix = labels + ","
+	}
+
+	var cumulative uint64
+	for i, bound := range c.buckets {
+		cumulative += h.counts[i]
+		fmt.Fprintf(b, "%s_bucket{%sle=\"%s\"} %d\n", name, prefix, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(b, "%s_bucket{%sle=\"+Inf\"} %d\n", name, prefix, h.count)
+
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	fmt.Fprintf(b, "%s_sum%s %s\n", name, labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(b, "%s_count%s %d\n", name, labels, h.count)
+}
+
+// writeHeader schreibt die HELP und TYPE Zeilen einer Kennzahl
+func writeHeader(b *strings.Builder, name string, metricType string, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// writeLabeledCounters schreibt eine Kennzahl je Label Wert, sortiert nach dem Label Wert
+func writeLabeledCounters(b *strings.Builder, name string, label string, values map[string]uint64) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(b, "%s{%s=\"%s\"} %d\n", name, label, escapeLabelValue(key), values[key])
+	}
+}
+
+// rpcLabels gibt die Labels einer RPC Kennzahl zurück
+func rpcLabels(key rpcKey) string {
+	return fmt.Sprintf("side=\"%s\",function=\"%s\"", key.side, escapeLabelValue(key.function))
+}
+
+// escapeLabelValue maskiert einen Label Wert gemäß dem Prometheus Textformat
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,57 @@
+package promexport
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollectorTextFormat(t *testing.T) {
+	c := NewWithBuckets([]float64{0.01, 0.1})
+	c.FrameSent('M', 100)
+	c.FrameSent('E', 1)
+	c.FrameReceived('A', 3)
+	c.AckWaited(5 * time.Millisecond)
+	c.RpcCallFinished("echo", 50*time.Millisecond, nil)
+	c.RpcCallFinished("echo", time.Second, errors.New("failed"))
+	c.RpcRequestServed(`we"ird`, time.Millisecond, nil)
+	c.ErrorOccurred("eof")
+	c.ChannelsChanged(2)
+	c.ChannelsChanged(-1)
+	c.ListenersChanged(1)
+
+	recorder := httptest.NewRecorder()
+	c.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("invalid content type %q", recorder.Header().Get("Content-Type"))
+	}
+	body, _ := io.ReadAll(recorder.Body)
+	output := string(body)
+
+	expected := []string{
+		"bngsocket_sent_bytes_total 101\n",
+		"bngsocket_received_bytes_total 3\n",
+		`bngsocket_frames_sent_total{frame="M"} 1` + "\n",
+		`bngsocket_frames_received_total{frame="A"} 1` + "\n",
+		`bngsocket_errors_total{type="eof"} 1` + "\n",
+		`bngsocket_ack_wait_seconds_bucket{le="0.01"} 1` + "\n",
+		"bngsocket_ack_wait_seconds_count 1\n",
+		`bngsocket_rpc_duration_seconds_bucket{side="client",function="echo",le="0.01"} 0` + "\n",
+		`bngsocket_rpc_duration_seconds_bucket{side="client",function="echo",le="0.1"} 1` + "\n",
+		`bngsocket_rpc_duration_seconds_bucket{side="client",function="echo",le="+Inf"} 2` + "\n",
+		`bngsocket_rpc_duration_seconds_count{side="client",function="echo"} 2` + "\n",
+		`bngsocket_rpc_errors_total{side="client",function="echo"} 1` + "\n",
+		`bngsocket_rpc_errors_total{side="server",function="we\"ird"} 0` + "\n",
+		"bngsocket_open_channels 1\n",
+		"bngsocket_open_listeners 1\n",
+		"# TYPE bngsocket_rpc_duration_seconds histogram\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("missing %q in output:\n%s", line, output)
+		}
+	}
+}
@@ -1,10 +1,5 @@
 package bngsocket
 
-import (
-	"fmt"
-	"reflect"
-)
-
 // NewSafeChan erstellt einen neuen _SafeChan mit dem angegebenen Puffer.
 func NewSafeChan[T any]() *_SafeChan[T] {
 	return &_SafeChan[T]{
@@ -14,7 +9,6 @@ func NewSafeChan[T any]() *_SafeChan[T] {
 }
 
 func NewBufferdSafeChan[T any](buffSize int) *_SafeChan[T] {
-	_DebugPrint(fmt.Sprintf("New Safe Bufferd Chan generated %s", reflect.TypeFor[T]().String()))
 	return &_SafeChan[T]{
 		ch:     make(chan T, buffSize),
 		isOpen: true,
@@ -35,7 +29,6 @@ func (sc *_SafeChan[T]) Enter(value T) bool {
 	select {
 	case sc.ch <- value:
 	default:
-		_DebugPrint("Chan closed")
 		return false
 	}
 
@@ -44,11 +37,16 @@ func (sc *_SafeChan[T]) Enter(value T) bool {
 }
 
 // Close schließt den Kanal.
-func (sc *_SafeChan[T]) Destroy() {
+// Destroy schließt den Chan, der Rückgabewert gibt an ob der Chan durch diesen Aufruf geschlossen wurde.
+func (sc *_SafeChan[T]) Destroy() bool {
 	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if !sc.isOpen {
+		return false
+	}
 	sc.isOpen = false
 	close(sc.ch)
-	sc.mu.Unlock()
+	return true
 }
 
 // IsOpen gibt an ob der Chan geschlossen gewurden
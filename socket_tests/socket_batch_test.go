@@ -0,0 +1,149 @@
+package sockettests
+
+import (
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+func TestRPCBatch(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	err := server.RegisterFunction("add", func(req *bngsocket.BngRequest, a int64, b int64) (int64, error) {
+		return a + b, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = server.RegisterFunction("fail", func(req *bngsocket.BngRequest) error {
+		return errors.New("failed")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = server.RegisterFunction("slow", func(req *bngsocket.BngRequest) error {
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	intType := []reflect.Type{reflect.TypeFor[int64]()}
+	start := time.Now()
+	results, err := client.Batch().
+		Call("add", []interface{}{int64(1), int64(2)}, intType).
+		Call("fail", nil, nil).
+		Call("unknown", nil, nil).
+		Call("slow", nil, nil).
+		Call("slow", nil, nil).
+		Call("slow", nil, nil).
+		Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Die langsamen Aufrufe werden nebenläufig ausgeführt
+	if elapsed := time.Since(start); elapsed > 550*time.Millisecond {
+		t.Fatalf("batch calls were not executed concurrently: %s", elapsed)
+	}
+	if len(results) != 6 {
+		t.Fatalf("unexpected result count %d", len(results))
+	}
+	if results[0].Err != nil || len(results[0].Values) != 1 || results[0].Values[0] != int64(3) {
+		t.Fatalf("unexpected add result: %+v", results[0])
+	}
+	if results[1].Err == nil || results[1].Err.Error() != "failed" {
+		t.Fatalf("unexpected fail result: %+v", results[1])
+	}
+	if !errors.Is(results[2].Err, bngsocket.ErrUnkownRpcFunction) {
+		t.Fatalf("unexpected unknown result: %+v", results[2])
+	}
+	for _, result := range results[3:] {
+		if result.Err != nil {
+			t.Fatal(result.Err)
+		}
+	}
+}
+
+func TestRPCBatchCallbackParameter(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	err := server.RegisterFunction("apply", func(req *bngsocket.BngRequest, value int64, fn func(int64) (int64, error)) (int64, error) {
+		return fn(value)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Übergebene Funktionen werden wie bei einem Einzelaufruf als Callback übertragen
+	double := func(value int64) (int64, error) { return value * 2, nil }
+	intType := []reflect.Type{reflect.TypeFor[int64]()}
+	results, err := client.Batch().
+		Call("apply", []interface{}{int64(2), double}, intType).
+		Call("apply", []interface{}{int64(5), double}, intType).
+		Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []int64{4, 10} {
+		if results[i].Err != nil || results[i].Values[0] != expected {
+			t.Fatalf("unexpected result %d: %v, %v", i, results[i].Values, results[i].Err)
+		}
+	}
+}
+
+func TestRPCBatchLimits(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, &bngsocket.BngConnConfig{MaxBatchSize: 4, BatchConcurrency: 2}, nil)
+
+	// Es werden nie mehr als BatchConcurrency Aufrufe gleichzeitig ausgeführt
+	var running, maxRunning atomic.Int32
+	err := server.RegisterFunction("work", func(req *bngsocket.BngRequest) error {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			highest := maxRunning.Load()
+			if current <= highest || maxRunning.CompareAndSwap(highest, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch := client.Batch()
+	for i := 0; i < 4; i++ {
+		batch.Call("work", nil, nil)
+	}
+	results, err := batch.Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Fatal(result.Err)
+		}
+	}
+	if maxRunning.Load() != 2 {
+		t.Fatalf("expected 2 concurrent calls, got %d", maxRunning.Load())
+	}
+
+	// Zu große Batches werden abgelehnt, ohne die Aufrufe auszuführen
+	batch.Call("work", nil, nil)
+	results, err = batch.Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if !errors.Is(result.Err, bngsocket.ErrBatchTooLarge) {
+			t.Fatalf("expected ErrBatchTooLarge, got %v", result.Err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package sockettests
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+func TestRPCCallbackParameter(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	err := server.RegisterFunction("download", func(req *bngsocket.BngRequest, url string, onProgress func(int64) error) (string, error) {
+		for _, progress := range []int64{50, 100} {
+			if err := onProgress(progress); err != nil {
+				return "", err
+			}
+		}
+		return "done:" + url, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = server.RegisterFunction("transform", func(req *bngsocket.BngRequest, value string, fn func(string) (string, error)) (string, error) {
+		return fn(value)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Der Callback wird während des Aufrufs von der Gegenseite aufgerufen
+	var mu sync.Mutex
+	var progress []int64
+	onProgress := func(value int64) error {
+		mu.Lock()
+		defer mu.Unlock()
+		progress = append(progress, value)
+		return nil
+	}
+	values, err := client.CallFunction("download", []interface{}{"file", onProgress}, []reflect.Type{reflect.TypeFor[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != "done:file" {
+		t.Fatalf("unexpected result %v", values[0])
+	}
+	mu.Lock()
+	if !reflect.DeepEqual(progress, []int64{50, 100}) {
+		t.Fatalf("unexpected progress %v", progress)
+	}
+	mu.Unlock()
+
+	// Rückgabewerte und der *BngRequest des Callbacks werden unterstützt
+	upper := func(req *bngsocket.BngRequest, value string) (string, error) {
+		return strings.ToUpper(value), nil
+	}
+	values, err = client.CallFunction("transform", []interface{}{"abc", upper}, []reflect.Type{reflect.TypeFor[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != "ABC" {
+		t.Fatalf("unexpected result %v", values[0])
+	}
+}
+
+func TestRPCCallbackRelease(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	var kept *bngsocket.BngCallback
+	err := server.RegisterFunction("release", func(req *bngsocket.BngRequest, cb *bngsocket.BngCallback) error {
+		if err := cb.Release(); err != nil {
+			return err
+		}
+		_, err := cb.Call([]interface{}{"value"}, nil)
+		if !errors.Is(err, bngsocket.ErrCallbackReleased) {
+			return errors.New("callback was not released")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = server.RegisterFunction("keep", func(req *bngsocket.BngRequest, cb *bngsocket.BngCallback) error {
+		kept = cb
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callback := func(value string) error { return nil }
+	if _, err := client.CallFunction("release", []interface{}{callback}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nach dem Abschluss des Aufrufs ist der Callback auf der Gegenseite nicht mehr vorhanden
+	if _, err := client.CallFunction("keep", []interface{}{callback}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kept.CallContext(context.Background(), []interface{}{"value"}, nil); !errors.Is(err, bngsocket.ErrCallbackReleased) {
+		t.Fatalf("expected ErrCallbackReleased, got %v", err)
+	}
+
+	// Der Context des Callbacks endet nicht mit dem Aufruf, die Gegenseite meldet die Freigabe
+	kept = nil
+	if _, err := client.CallFunction("keep", []interface{}{callback}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kept.Call([]interface{}{"value"}, nil); !errors.Is(err, bngsocket.ErrCallbackReleased) {
+		t.Fatalf("expected ErrCallbackReleased, got %v", err)
+	}
+}
+
+func TestRPCCallbackInvalidParameter(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	err := server.RegisterFunction("echo", func(req *bngsocket.BngRequest, value string) (string, error) {
+		return value, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ein Callback für einen Parameter ohne Callback Typ wird als ungültiger Parameter gemeldet
+	callback := func(value string) error { return nil }
+	if _, err := client.CallFunction("echo", []interface{}{callback}, []reflect.Type{reflect.TypeFor[string]()}); !errors.Is(err, bngsocket.ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter, got %v", err)
+	}
+
+	// Die Verbindung bleibt bestehen
+	values, err := client.CallFunction("echo", []interface{}{"still alive"}, []reflect.Type{reflect.TypeFor[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != "still alive" {
+		t.Fatalf("unexpected result %v", values[0])
+	}
+}
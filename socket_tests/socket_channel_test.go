@@ -86,10 +86,6 @@ func serveConn_ServerSide(conn net.Conn) {
 }
 
 func TestChannelSocket(t *testing.T) {
-	bngsocket.DebugSetPrintFunction(func(v ...any) {
-		fmt.Println(v...)
-	})
-
 	// UNIX-Socket Pfad
 	socketPath := "/tmp/test_bngsocket.sock"
 
@@ -0,0 +1,112 @@
+package sockettests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+func TestRPCReturnsChannel(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	err := server.RegisterFunction("open", func(req *bngsocket.BngRequest, greeting string) (*bngsocket.BngConnChannel, error) {
+		channel, err := req.Conn.NewChannel()
+		if err != nil {
+			return nil, err
+		}
+
+		// Der Schreibvorgang wartet, bis der Aufrufer dem Channel beigetreten ist
+		go channel.Write([]byte(greeting))
+		return channel, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := client.CallFunction("open", []interface{}{"hello"}, []reflect.Type{reflect.TypeFor[*bngsocket.BngConnChannel]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	channel := values[0].(*bngsocket.BngConnChannel)
+	defer channel.Close()
+
+	buf := make([]byte, 16)
+	n, err := channel.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf[:n]) != "hello" {
+		t.Fatalf("unexpected data %q", buf[:n])
+	}
+}
+
+func TestRPCChannelParameter(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	err := server.RegisterFunction("upload", func(req *bngsocket.BngRequest, channel *bngsocket.BngConnChannel) (string, error) {
+		buf := make([]byte, 16)
+		n, err := channel.Read(buf)
+		if err != nil {
+			return "", err
+		}
+		return string(buf[:n]), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Die Lebensdauer des Channels ist an den Context des Aufrufs gebunden
+	ctx, cancel := context.WithCancel(context.Background())
+	channel, err := client.NewChannelContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go channel.Write([]byte("payload"))
+
+	values, err := client.CallFunctionContext(ctx, "upload", []interface{}{channel}, []reflect.Type{reflect.TypeFor[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != "payload" {
+		t.Fatalf("unexpected result %v", values[0])
+	}
+
+	cancel()
+	waitUntil(t, func() bool {
+		_, err := channel.Write([]byte("late"))
+		return err == io.EOF
+	})
+}
+
+func TestRPCChannelInvalidParameter(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	err := server.RegisterFunction("echo", func(req *bngsocket.BngRequest, value string) (string, error) {
+		return value, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ein Channel für einen Parameter ohne Channel Typ wird als ungültiger Parameter gemeldet
+	channel, err := client.NewChannel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CallFunction("echo", []interface{}{channel}, []reflect.Type{reflect.TypeFor[string]()}); !errors.Is(err, bngsocket.ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter, got %v", err)
+	}
+
+	// Die Verbindung bleibt bestehen
+	values, err := client.CallFunction("echo", []interface{}{"still alive"}, []reflect.Type{reflect.TypeFor[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != "still alive" {
+		t.Fatalf("unexpected result %v", values[0])
+	}
+}
@@ -0,0 +1,90 @@
+package sockettests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/custodia-cenv/bngsocket-go"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type codecTestPoint struct {
+	X int64  `rpc:"x"`
+	Y int64  `rpc:"y"`
+	N string `rpc:"n"`
+}
+
+func TestCodecNegotiatedRPC(t *testing.T) {
+	for _, name := range []string{bngsocket.CodecJSON, bngsocket.CodecCBOR} {
+		t.Run(name, func(t *testing.T) {
+			config := &bngsocket.BngConnConfig{Codec: name}
+			server, client := newConnectedBngConnPair(t, config, config)
+
+			// Der Codec muss auf beiden Seiten ausgehandelt werden
+			waitUntil(t, func() bool { return server.Codec() == name && client.Codec() == name })
+
+			err := server.RegisterFunction("move", func(req *bngsocket.BngRequest, p *codecTestPoint, dx int8) (*codecTestPoint, uint16, error) {
+				return &codecTestPoint{X: p.X + int64(dx), Y: p.Y, N: p.N}, 7, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			values, err := client.CallFunction("move", []interface{}{&codecTestPoint{X: 1, Y: 2, N: "p"}, int8(3)},
+				[]reflect.Type{reflect.TypeFor[*codecTestPoint](), reflect.TypeFor[uint16]()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if point := values[0].(*codecTestPoint); *point != (codecTestPoint{X: 4, Y: 2, N: "p"}) {
+				t.Fatalf("unexpected point %+v", point)
+			}
+			if values[1] != uint16(7) {
+				t.Fatalf("unexpected value %v (%T)", values[1], values[1])
+			}
+		})
+	}
+}
+
+func TestCodecFallbackToMsgpack(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, &bngsocket.BngConnConfig{Codec: bngsocket.CodecJSON}, nil)
+
+	err := server.RegisterFunction("echo", func(req *bngsocket.BngRequest, value string) (string, error) {
+		return value, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Die Gegenseite bietet keinen Codec an, es wird weiterhin msgpack verwendet
+	values, err := client.CallFunction("echo", []interface{}{"hello"}, []reflect.Type{reflect.TypeFor[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != "hello" || server.Codec() != bngsocket.CodecMsgpack {
+		t.Fatalf("unexpected result %v with codec %s", values[0], server.Codec())
+	}
+}
+
+func TestCodecProtoMessageParameter(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	err := server.RegisterFunction("greet", func(req *bngsocket.BngRequest, name *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+		return wrapperspb.String("hello " + name.GetValue()), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := client.CallFunction("greet", []interface{}{wrapperspb.String("bng")}, []reflect.Type{reflect.TypeFor[*wrapperspb.StringValue]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0].(*wrapperspb.StringValue).GetValue() != "hello bng" {
+		t.Fatalf("unexpected result %v", values[0])
+	}
+
+	// Eine Nachricht eines anderen Typs wird abgelehnt
+	if _, err := client.CallFunction("greet", []interface{}{wrapperspb.Int64(1)}, []reflect.Type{reflect.TypeFor[*wrapperspb.StringValue]()}); err == nil {
+		t.Fatal("expected error for mismatching proto message")
+	}
+}
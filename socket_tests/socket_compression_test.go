@@ -0,0 +1,130 @@
+package sockettests
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+// Erzeugt zwei miteinander verbundene BngConn Objekte über einen Unix-Socket
+func newConnectedBngConnPair(t *testing.T, serverConfig *bngsocket.BngConnConfig, clientConfig *bngsocket.BngConnConfig) (*bngsocket.BngConn, *bngsocket.BngConn) {
+	t.Helper()
+
+	// Erstellen eines temporären Unix-Socket-Pfads
+	socketPath := filepath.Join(os.TempDir(), fmt.Sprintf("test_socket_%d.sock", time.Now().UnixNano()))
+	t.Cleanup(func() { os.Remove(socketPath) })
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Fehler beim Erstellen des Unix-Socket-Listeners: %v", err)
+	}
+	defer listener.Close()
+
+	// Die Server Verbindung wird im Hintergrund angenommen
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			accepted <- nil
+			return
+		}
+		accepted <- conn
+	}()
+
+	clientSocket, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Fehler beim Verbinden zum Unix-Socket: %v", err)
+	}
+	serverSocket := <-accepted
+	if serverSocket == nil {
+		t.Fatal("Fehler beim Akzeptieren der Verbindung")
+	}
+
+	server, err := bngsocket.UpgradeSocketToBngConnWithConfig(serverSocket, serverConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := bngsocket.UpgradeSocketToBngConnWithConfig(clientSocket, clientConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return server, client
+}
+
+// Wartet bis eine Bedingung erfüllt ist
+func waitUntil(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout while waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCompressedRPC(t *testing.T) {
+	config := &bngsocket.BngConnConfig{Compression: bngsocket.CompressionGzip}
+	server, client := newConnectedBngConnPair(t, config, config)
+
+	// Die Kompression muss auf beiden Seiten ausgehandelt werden
+	waitUntil(t, func() bool { return server.CompressionActive() && client.CompressionActive() })
+
+	err := server.RegisterFunction("echo", func(req *bngsocket.BngRequest, value string) (string, error) {
+		return value, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Es wird ein gut komprimierbarer Wert übertragen
+	payload := strings.Repeat(`{"level":"info","msg":"compress me"}`, 512)
+	result, err := client.CallFunction("echo", []interface{}{payload}, []reflect.Type{reflect.TypeFor[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || result[0] != payload {
+		t.Fatal("invalid echo result")
+	}
+
+	// Es wird geprüft ob die Daten tatsächlich komprimiert wurden
+	sent, _ := client.CompressionStats()
+	if sent.Messages == 0 || sent.Ratio() < 5 {
+		t.Fatalf("payload was not compressed: %+v", sent)
+	}
+	_, received := server.CompressionStats()
+	if received.Messages == 0 {
+		t.Fatal("server did not receive compressed messages")
+	}
+}
+
+func TestCompressionNotNegotiated(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, &bngsocket.BngConnConfig{Compression: bngsocket.CompressionGzip}, nil)
+
+	err := server.RegisterFunction("echo", func(req *bngsocket.BngRequest, value string) (string, error) {
+		return value, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ohne Kompression auf der Gegenseite müssen die Daten unkomprimiert übertragen werden
+	payload := strings.Repeat("a", 4096)
+	if _, err := client.CallFunction("echo", []interface{}{payload}, []reflect.Type{reflect.TypeFor[string]()}); err != nil {
+		t.Fatal(err)
+	}
+	if client.CompressionActive() || server.CompressionActive() {
+		t.Fatal("compression must not be negotiated")
+	}
+	if sent, _ := server.CompressionStats(); sent.Messages != 0 {
+		t.Fatal("server compressed without negotiation")
+	}
+}
@@ -0,0 +1,67 @@
+package sockettests
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go"
+	"github.com/google/uuid"
+)
+
+// Eine Domänen ID ohne rpc Felder, welche über RegisterType übertragen wird
+type customTestOrderID struct {
+	shard uint16
+	seq   uint32
+}
+
+func init() {
+	err := bngsocket.RegisterType(
+		func(id customTestOrderID) ([]byte, error) {
+			data := binary.BigEndian.AppendUint16(nil, id.shard)
+			return binary.BigEndian.AppendUint32(data, id.seq), nil
+		},
+		func(data []byte) (customTestOrderID, error) {
+			if len(data) != 6 {
+				return customTestOrderID{}, errors.New("invalid order id")
+			}
+			return customTestOrderID{shard: binary.BigEndian.Uint16(data), seq: binary.BigEndian.Uint32(data[2:])}, nil
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestCustomTypeRPC(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	// uuid.UUID und time.Time implementieren die Marshaler Interfaces, die Order ID ist registriert
+	err := server.RegisterFunction("order", func(req *bngsocket.BngRequest, id customTestOrderID, owner uuid.UUID, at time.Time) (customTestOrderID, uuid.UUID, error) {
+		return customTestOrderID{shard: id.shard, seq: id.seq + 1}, owner, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner := uuid.New()
+	values, err := client.CallFunction("order", []interface{}{customTestOrderID{shard: 3, seq: 41}, owner, time.Now()},
+		[]reflect.Type{reflect.TypeFor[customTestOrderID](), reflect.TypeFor[uuid.UUID]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != (customTestOrderID{shard: 3, seq: 42}) {
+		t.Fatalf("unexpected order id %+v", values[0])
+	}
+	if values[1] != owner {
+		t.Fatalf("unexpected owner %v", values[1])
+	}
+
+	// Ein Typ kann nur einmal registriert werden
+	err = bngsocket.RegisterType(func(customTestOrderID) ([]byte, error) { return nil, nil }, func([]byte) (customTestOrderID, error) { return customTestOrderID{}, nil })
+	if !errors.Is(err, bngsocket.ErrTypeAlreadyRegistered) {
+		t.Fatalf("expected ErrTypeAlreadyRegistered, got %v", err)
+	}
+}
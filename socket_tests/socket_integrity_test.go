@@ -0,0 +1,134 @@
+package sockettests
+
+import (
+	"net"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+func TestRPCWithFrameChecksums(t *testing.T) {
+	config := &bngsocket.BngConnConfig{FrameIntegrity: bngsocket.FrameIntegrityRetransmit}
+	server, client := newConnectedBngConnPair(t, config, config)
+
+	err := server.RegisterFunction("echo", func(req *bngsocket.BngRequest, value string) (string, error) {
+		return value, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Der Wert wird über mehrere Chunks verteilt übertragen
+	payload := strings.Repeat("0123456789", 500)
+	result, err := client.CallFunction("echo", []interface{}{payload}, []reflect.Type{reflect.TypeFor[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || result[0] != payload {
+		t.Fatal("invalid echo result")
+	}
+}
+
+// Leitet die Daten des Clients an den Server weiter und beschädigt dabei die ersten 'm' Frames
+type _CorruptingProxy struct {
+	armed     atomic.Bool
+	corrupted atomic.Int32
+}
+
+// Kopiert die Daten von src nach dst, nach dem Scharfschalten wird zuerst ein Datenbyte und danach die Längenangabe beschädigt
+func (p *_CorruptingProxy) forward(dst net.Conn, src net.Conn, corrupt bool) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := src.Read(buf)
+		if err != nil {
+			dst.Close()
+			return
+		}
+		if corrupt && p.armed.Load() && n > 9 && buf[0] == 'm' {
+			switch p.corrupted.Add(1) {
+			case 1:
+				buf[5] ^= 0xFF
+			case 2:
+				buf[1] = 0xFF
+			}
+		}
+		if _, err := dst.Write(buf[:n]); err != nil {
+			src.Close()
+			return
+		}
+	}
+}
+
+func TestRPCRetransmitsCorruptedFrames(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "bng.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// Zwischen Client und Server wird ein Proxy geschaltet, welcher Frames beschädigt
+	proxy := &_CorruptingProxy{}
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for i := 0; i < 2; i++ {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+	serverSocket, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientSocket, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverSide, clientSide := <-accepted, <-accepted
+	go proxy.forward(serverSide, clientSide, true)
+	go proxy.forward(clientSide, serverSide, false)
+
+	config := &bngsocket.BngConnConfig{FrameIntegrity: bngsocket.FrameIntegrityRetransmit}
+	server, err := bngsocket.UpgradeSocketToBngConnWithConfig(serverSocket, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := bngsocket.UpgradeSocketToBngConnWithConfig(clientSocket, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	err = server.RegisterFunction("echo", func(req *bngsocket.BngRequest, value string) (string, error) {
+		return value, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Die beschädigten Chunks werden erneut angefordert, der Aufruf gelingt dennoch
+	time.Sleep(100 * time.Millisecond)
+	proxy.armed.Store(true)
+	payload := strings.Repeat("0123456789", 500)
+	result, err := client.CallFunction("echo", []interface{}{payload}, []reflect.Type{reflect.TypeFor[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || result[0] != payload {
+		t.Fatal("invalid echo result")
+	}
+	if proxy.corrupted.Load() < 2 {
+		t.Fatalf("expected corrupted frames, got %d", proxy.corrupted.Load())
+	}
+}
@@ -0,0 +1,120 @@
+package sockettests
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+func TestRPCInterceptors(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	record := func(entry string) {
+		mu.Lock()
+		order = append(order, entry)
+		mu.Unlock()
+	}
+
+	serverConfig := &bngsocket.BngConnConfig{
+		ServerInterceptors: []bngsocket.UnaryServerInterceptor{
+			// Zugriffsprüfung: Die Funktion "secret" darf nicht aufgerufen werden
+			func(req *bngsocket.BngRequest, method string, args []interface{}, handler bngsocket.UnaryHandler) ([]interface{}, error) {
+				record("server-auth:" + method)
+				if method == "secret" {
+					return nil, errors.New("permission denied")
+				}
+				return handler(req, args)
+			},
+			// Die Argumente werden verändert
+			func(req *bngsocket.BngRequest, method string, args []interface{}, handler bngsocket.UnaryHandler) ([]interface{}, error) {
+				record("server-upper:" + method)
+				args[0] = strings.ToUpper(args[0].(string))
+				return handler(req, args)
+			},
+		},
+	}
+	clientConfig := &bngsocket.BngConnConfig{
+		ClientInterceptors: []bngsocket.UnaryClientInterceptor{
+			func(ctx context.Context, conn *bngsocket.BngConn, method string, params []interface{}, returnDataType []reflect.Type, invoker bngsocket.UnaryInvoker) ([]interface{}, error) {
+				record("client-outer:" + method)
+				return invoker(ctx, conn, method, params, returnDataType)
+			},
+			func(ctx context.Context, conn *bngsocket.BngConn, method string, params []interface{}, returnDataType []reflect.Type, invoker bngsocket.UnaryInvoker) ([]interface{}, error) {
+				record("client-inner:" + method)
+				return invoker(ctx, conn, method, params, returnDataType)
+			},
+		},
+	}
+	server, client := newConnectedBngConnPair(t, serverConfig, clientConfig)
+
+	for _, name := range []string{"echo", "secret"} {
+		err := server.RegisterFunction(name, func(req *bngsocket.BngRequest, value string) (string, error) {
+			return value, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := client.CallFunction("echo", []interface{}{"hello"}, []reflect.Type{reflect.TypeFor[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || result[0] != "HELLO" {
+		t.Fatalf("arguments were not modified by interceptor: %v", result)
+	}
+
+	// Der abgelehnte Aufruf muss den Fehler des Interceptors zurückgeben, die Verbindung bleibt bestehen
+	if _, err := client.CallFunction("secret", []interface{}{"hello"}, []reflect.Type{reflect.TypeFor[string]()}); err == nil || err.Error() != "permission denied" {
+		t.Fatalf("expected permission denied, got %v", err)
+	}
+	if _, err := client.CallFunction("echo", []interface{}{"again"}, []reflect.Type{reflect.TypeFor[string]()}); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []string{
+		"client-outer:echo", "client-inner:echo", "server-auth:echo", "server-upper:echo",
+		"client-outer:secret", "client-inner:secret", "server-auth:secret",
+		"client-outer:echo", "client-inner:echo", "server-auth:echo", "server-upper:echo",
+	}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("unexpected interceptor order:\n%v\n%v", order, expected)
+	}
+}
+
+func TestRPCInterceptorInvalidArguments(t *testing.T) {
+	// Der Interceptor ersetzt das Argument durch einen Wert mit falschem Typ
+	replace := func(req *bngsocket.BngRequest, method string, args []interface{}, handler bngsocket.UnaryHandler) ([]interface{}, error) {
+		if method == "echo" && args[0] == "replace" {
+			args = []interface{}{int64(1)}
+		}
+		return handler(req, args)
+	}
+	server, client := newConnectedBngConnPair(t, &bngsocket.BngConnConfig{ServerInterceptors: []bngsocket.UnaryServerInterceptor{replace}}, nil)
+
+	err := server.RegisterFunction("echo", func(req *bngsocket.BngRequest, value string) (string, error) {
+		return value, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Das ungültige Argument wird dem Aufrufer gemeldet, die Verbindung bleibt bestehen
+	if _, err := client.CallFunction("echo", []interface{}{"replace"}, []reflect.Type{reflect.TypeFor[string]()}); !errors.Is(err, bngsocket.ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter, got %v", err)
+	}
+	values, err := client.CallFunction("echo", []interface{}{"keep"}, []reflect.Type{reflect.TypeFor[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != "keep" {
+		t.Fatalf("unexpected result %v", values[0])
+	}
+}
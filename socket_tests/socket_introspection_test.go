@@ -0,0 +1,53 @@
+package sockettests
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+func TestListRemoteFunctions(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	err := server.RegisterFunction("sum", func(req *bngsocket.BngRequest, a int64, b []int64) (int64, error) {
+		return a, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = server.RegisterFunction("ticker", func(req *bngsocket.BngRequest, stream *bngsocket.BngStream, count int) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := server.OpenChannelListener("uploads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// Reservierte Namen können nicht registriert werden
+	err = server.RegisterFunction("_bng.list", func(req *bngsocket.BngRequest) error { return nil })
+	if !errors.Is(err, bngsocket.ErrReservedFunctionName) {
+		t.Fatalf("expected ErrReservedFunctionName, got %v", err)
+	}
+
+	description, err := client.ListRemoteFunctions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*bngsocket.BngFunctionDescription{
+		{Name: "sum", Params: []string{"int64", "[]int64"}, Returns: []string{"int64"}},
+		{Name: "ticker", Params: []string{"int"}, Returns: []string{}, Stream: true},
+	}
+	if !reflect.DeepEqual(description.Functions, expected) {
+		t.Fatalf("unexpected functions %+v", description.Functions)
+	}
+	if !reflect.DeepEqual(description.ChannelListeners, []string{"uploads"}) {
+		t.Fatalf("unexpected channel listeners %v", description.ChannelListeners)
+	}
+}
@@ -0,0 +1,85 @@
+package sockettests
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+func TestKeepaliveRTT(t *testing.T) {
+	config := &bngsocket.BngConnConfig{KeepaliveInterval: 20 * time.Millisecond}
+	server, client := newConnectedBngConnPair(t, config, config)
+
+	// Beide Seiten müssen eine RTT gemessen haben
+	waitUntil(t, func() bool { return server.RTT() > 0 && client.RTT() > 0 })
+	if client.LastPong().IsZero() {
+		t.Fatal("no pong received")
+	}
+}
+
+func TestKeepaliveDeadPeer(t *testing.T) {
+	socketPath := filepath.Join(os.TempDir(), fmt.Sprintf("test_socket_%d.sock", time.Now().UnixNano()))
+	defer os.Remove(socketPath)
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// Die Gegenseite nimmt die Verbindung an, beantwortet aber keine Pings
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(2 * time.Second)
+		}
+	}()
+
+	socket, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := bngsocket.UpgradeSocketToBngConnWithConfig(socket, &bngsocket.BngConnConfig{
+		KeepaliveInterval: 20 * time.Millisecond,
+		KeepaliveTimeout:  100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Die Verbindung muss mit ErrPeerTimeout beendet werden
+	result := make(chan error, 1)
+	go func() { result <- bngsocket.MonitorConnection(conn) }()
+	select {
+	case err := <-result:
+		if !errors.Is(err, bngsocket.ErrPeerTimeout) {
+			t.Fatalf("expected ErrPeerTimeout, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("dead peer was not detected")
+	}
+	if !bngsocket.IsConnectionClosed(conn) {
+		t.Fatal("connection must be closed")
+	}
+}
+
+func TestKeepaliveStopsOnClose(t *testing.T) {
+	config := &bngsocket.BngConnConfig{KeepaliveInterval: time.Hour}
+	_, client := newConnectedBngConnPair(t, config, config)
+
+	// Das Schließen wartet auf die Keepalive Routine, diese darf nicht erst beim nächsten Ping enden
+	start := time.Now()
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("close waited for the keepalive interval (%s)", elapsed)
+	}
+}
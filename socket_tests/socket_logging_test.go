@@ -0,0 +1,68 @@
+package sockettests
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+// Puffer, welcher von mehreren Routinen gleichzeitig beschrieben werden kann
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.Split(strings.TrimSpace(b.buf.String()), "\n")
+}
+
+func TestConnectionLogger(t *testing.T) {
+	output := new(syncBuffer)
+	logger := slog.New(slog.NewJSONHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	server, client := newConnectedBngConnPair(t, &bngsocket.BngConnConfig{Logger: logger}, nil)
+
+	err := server.RegisterFunction("echo", func(req *bngsocket.BngRequest, value string) (string, error) {
+		return value, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CallFunction("echo", []interface{}{"log"}, []reflect.Type{reflect.TypeFor[string]()}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Jeder Eintrag muss die ID der Verbindung enthalten, der RPC Aufruf muss seine ID enthalten
+	var withRpcId, withFrame bool
+	for _, line := range output.Lines() {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		if record["conn"] == nil || record["conn"] == "" {
+			t.Fatalf("log line without conn id: %s", line)
+		}
+		if record["rpc_id"] != nil {
+			withRpcId = true
+		}
+		if record["frame"] != nil {
+			withFrame = true
+		}
+	}
+	if !withRpcId || !withFrame {
+		t.Fatal("missing rpc_id or frame attributes")
+	}
+}
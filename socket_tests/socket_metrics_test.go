@@ -0,0 +1,54 @@
+package sockettests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/custodia-cenv/bngsocket-go"
+	"github.com/custodia-cenv/bngsocket-go/promexport"
+)
+
+func TestMetricsCollector(t *testing.T) {
+	collector := promexport.New()
+	config := &bngsocket.BngConnConfig{Metrics: collector}
+	server, client := newConnectedBngConnPair(t, config, config)
+
+	err := server.RegisterFunction("echo", func(req *bngsocket.BngRequest, value string) (string, error) {
+		return value, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CallFunction("echo", []interface{}{"metrics"}, []reflect.Type{reflect.TypeFor[string]()}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.OpenChannelListener("listener"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Die Kennzahlen des Servers werden erst nach dem Senden der Antwort erfasst
+	collect := func() string {
+		var output strings.Builder
+		if _, err := collector.WriteTo(&output); err != nil {
+			t.Fatal(err)
+		}
+		return output.String()
+	}
+	waitUntil(t, func() bool {
+		return strings.Contains(collect(), `bngsocket_rpc_duration_seconds_count{side="server",function="echo"} 1`)
+	})
+
+	output := collect()
+	expected := []string{
+		`bngsocket_rpc_duration_seconds_count{side="client",function="echo"} 1`,
+		`bngsocket_frames_sent_total{frame="A"}`,
+		`bngsocket_frames_received_total{frame="M"}`,
+		"bngsocket_open_listeners 1",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("missing %q in output:\n%s", line, output)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package sockettests
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+func TestRPCNotify(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	events := make(chan string, 1)
+	err := server.RegisterFunction("event", func(req *bngsocket.BngRequest, name string, value int64) error {
+		events <- name
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = server.RegisterFunction("echo", func(req *bngsocket.BngRequest, value string) (string, error) {
+		return value, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Notify("event", "started", int64(1)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case name := <-events:
+		if name != "started" {
+			t.Fatalf("unexpected event %q", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification was not delivered")
+	}
+
+	// Eine Benachrichtigung an eine unbekannte Funktion wird verworfen, die Verbindung bleibt bestehen
+	if err := client.Notify("unknown", "value"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CallFunction("echo", []interface{}{"still alive"}, []reflect.Type{reflect.TypeFor[string]()}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,125 @@
+package sockettests
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+type paramsTestUser struct {
+	Name     string                     `rpc:"name"`
+	Age      int64                      `rpc:"age"`
+	Admin    bool                       `rpc:"admin"`
+	Nickname bngsocket.Optional[string] `rpc:"nickname"`
+}
+
+func TestRPCVariadicAndOptionalParameters(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	err := server.RegisterFunction("join", func(req *bngsocket.BngRequest, sep string, parts ...string) (string, error) {
+		result := ""
+		for i, part := range parts {
+			if i > 0 {
+				result += sep
+			}
+			result += part
+		}
+		return result, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = server.RegisterFunction("greet", func(req *bngsocket.BngRequest, name string, greeting bngsocket.Optional[string]) (string, error) {
+		return greeting.Or("hello") + " " + name, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stringType := []reflect.Type{reflect.TypeFor[string]()}
+	for _, tc := range []struct {
+		name   string
+		params []interface{}
+		want   string
+	}{
+		{"join", []interface{}{","}, ""},
+		{"join", []interface{}{",", "a", "b", "c"}, "a,b,c"},
+		{"greet", []interface{}{"bob"}, "hello bob"},
+		{"greet", []interface{}{"bob", "hi"}, "hi bob"},
+	} {
+		values, err := client.CallFunction(tc.name, tc.params, stringType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if values[0] != tc.want {
+			t.Fatalf("%s%v: expected %q, got %q", tc.name, tc.params, tc.want, values[0])
+		}
+	}
+
+	// Zu viele Parameter und fehlende Pflichtparameter werden als ungültige Parameter gemeldet, die Verbindung bleibt bestehen
+	for _, params := range [][]interface{}{{"bob", "hi", "extra"}, {}} {
+		if _, err := client.CallFunction("greet", params, stringType); !errors.Is(err, bngsocket.ErrInvalidParameter) {
+			t.Fatalf("expected ErrInvalidParameter for %v, got %v", params, err)
+		}
+	}
+	values, err := client.CallFunction("greet", []interface{}{"alice"}, stringType)
+	if err != nil || values[0] != "hello alice" {
+		t.Fatalf("connection unusable after invalid call: %v %v", values, err)
+	}
+
+	// Optional Parameter dürfen nur am Ende stehen
+	err = server.RegisterFunction("invalid", func(req *bngsocket.BngRequest, greeting bngsocket.Optional[string], name string) (string, error) {
+		return "", nil
+	})
+	if err == nil {
+		t.Fatal("expected error for required parameter after optional parameter")
+	}
+}
+
+func TestRPCNamedParameters(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	err := server.RegisterFunction("createUser", func(req *bngsocket.BngRequest, user *paramsTestUser) (string, error) {
+		if user.Admin {
+			return "", errors.New("admins can not be created")
+		}
+		return user.Nickname.Or(user.Name), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nicht übergebene Optional Felder sind nicht gesetzt, unbekannte Namen werden ignoriert
+	args := bngsocket.NamedArgs{"name": "bob", "age": 42, "admin": false, "unknown": "value"}
+	values, err := client.CallFunction("createUser", []interface{}{args}, []reflect.Type{reflect.TypeFor[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != "bob" {
+		t.Fatalf("unexpected result %v", values[0])
+	}
+
+	// Übergebene Optional Felder werden als gesetzt markiert
+	args = bngsocket.NamedArgs{"name": "bob", "age": 42, "admin": false, "nickname": "bobby"}
+	values, err = client.CallFunction("createUser", []interface{}{args}, []reflect.Type{reflect.TypeFor[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != "bobby" {
+		t.Fatalf("unexpected result %v", values[0])
+	}
+
+	// Fehlende Felder, welche nicht optional sind, werden als ungültiger Parameter gemeldet
+	args = bngsocket.NamedArgs{"name": "bob", "age": 42}
+	if _, err := client.CallFunction("createUser", []interface{}{args}, []reflect.Type{reflect.TypeFor[string]()}); !errors.Is(err, bngsocket.ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter, got %v", err)
+	}
+
+	// Ein Wert mit falschem Typ wird als ungültiger Parameter gemeldet
+	args = bngsocket.NamedArgs{"name": "bob", "age": "old", "admin": false}
+	if _, err := client.CallFunction("createUser", []interface{}{args}, []reflect.Type{reflect.TypeFor[string]()}); !errors.Is(err, bngsocket.ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter, got %v", err)
+	}
+}
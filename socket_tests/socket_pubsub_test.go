@@ -0,0 +1,126 @@
+package sockettests
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+func TestPubSubHub(t *testing.T) {
+	server1, client1 := newConnectedBngConnPair(t, nil, nil)
+	server2, client2 := newConnectedBngConnPair(t, nil, nil)
+
+	hub := bngsocket.NewBngHub()
+	hub.Add(server1)
+	hub.Add(server2)
+
+	jobs := make(chan string, 4)
+	jobSubscription, err := client1.Subscribe("jobs.*.status", func(msg *bngsocket.BngMessage) {
+		var status string
+		if err := msg.Decode(&status); err != nil {
+			t.Error(err)
+		}
+		jobs <- msg.Topic + "=" + status
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs := make(chan string, 4)
+	if _, err := client2.Subscribe("config.>", func(msg *bngsocket.BngMessage) {
+		configs <- msg.Topic
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ungültige Topics werden abgelehnt
+	if _, err := client1.Subscribe("jobs..status", func(*bngsocket.BngMessage) {}); !errors.Is(err, bngsocket.ErrInvalidTopic) {
+		t.Fatalf("expected invalid topic, got %v", err)
+	}
+	if err := hub.Publish("jobs.*.status", "done"); !errors.Is(err, bngsocket.ErrInvalidTopic) {
+		t.Fatalf("expected invalid topic, got %v", err)
+	}
+
+	// Die Nachrichten werden nur an passende Abonnenten verteilt
+	if err := hub.Publish("jobs.42.status", "done"); err != nil {
+		t.Fatal(err)
+	}
+	if err := hub.Publish("config.db.host", "localhost"); err != nil {
+		t.Fatal(err)
+	}
+	expectMessage(t, jobs, "jobs.42.status=done")
+	expectMessage(t, configs, "config.db.host")
+
+	// Nach dem Beenden des Abonnements werden keine Nachrichten mehr zugestellt
+	if err := jobSubscription.Unsubscribe(); err != nil {
+		t.Fatal(err)
+	}
+	waitUntil(t, func() bool {
+		if err := hub.Publish("jobs.43.status", "done"); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-jobs:
+			return false
+		case <-time.After(50 * time.Millisecond):
+			return true
+		}
+	})
+
+	// Beendete Verbindungen werden aus dem Hub entfernt
+	client2.Close()
+	waitUntil(t, func() bool { return hub.Len() == 1 })
+}
+
+func expectMessage(t *testing.T, messages chan string, expected string) {
+	t.Helper()
+	select {
+	case msg := <-messages:
+		if msg != expected {
+			t.Fatalf("unexpected message %q, want %q", msg, expected)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("message %q was not delivered", expected)
+	}
+}
+
+func TestPubSubOrderedDelivery(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	// Der Handler zeichnet die Reihenfolge auf und prüft, dass er nicht nebenläufig aufgerufen wird
+	const count = 100
+	var running atomic.Int32
+	received := make(chan int64, count)
+	if _, err := client.Subscribe("events", func(msg *bngsocket.BngMessage) {
+		if running.Add(1) != 1 {
+			t.Error("handler was called concurrently")
+		}
+		defer running.Add(-1)
+		var value int64
+		if err := msg.Decode(&value); err != nil {
+			t.Error(err)
+		}
+		time.Sleep(time.Millisecond)
+		received <- value
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := int64(0); i < count; i++ {
+		if err := server.Publish("events", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := int64(0); i < count; i++ {
+		select {
+		case value := <-received:
+			if value != i {
+				t.Fatalf("message %d was delivered at position %d", value, i)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("message %d was not delivered", i)
+		}
+	}
+}
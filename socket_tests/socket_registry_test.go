@@ -0,0 +1,157 @@
+package sockettests
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+type calculatorService struct{}
+
+func (calculatorService) Add(req *bngsocket.BngRequest, a int64, b int64) (int64, error) {
+	return a + b, nil
+}
+
+func (calculatorService) Negate(req *bngsocket.BngRequest, a int64) (int64, error) {
+	return -a, nil
+}
+
+func TestUnregisterAndReplaceFunction(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+	stringType := []reflect.Type{reflect.TypeFor[string]()}
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	err := server.RegisterFunction("version", func(req *bngsocket.BngRequest) (string, error) {
+		close(entered)
+		<-release
+		return "v1", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ein laufender Aufruf wird auch nach dem Entfernen der Funktion normal beendet
+	result := make(chan error, 1)
+	go func() {
+		values, err := client.CallFunction("version", nil, stringType)
+		if err == nil && values[0] != "v1" {
+			err = errors.New("unexpected value")
+		}
+		result <- err
+	}()
+	<-entered
+	if err := server.UnregisterFunction("version"); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	if err := <-result; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.CallFunction("version", nil, stringType); !errors.Is(err, bngsocket.ErrUnkownRpcFunction) {
+		t.Fatalf("expected ErrUnkownRpcFunction, got %v", err)
+	}
+	if err := server.UnregisterFunction("version"); !errors.Is(err, bngsocket.ErrFunctionNotRegistered) {
+		t.Fatalf("expected ErrFunctionNotRegistered, got %v", err)
+	}
+
+	// ReplaceFunction registriert bzw. ersetzt die Funktion
+	for _, version := range []string{"v2", "v3"} {
+		version := version
+		if err := server.ReplaceFunction("version", func(req *bngsocket.BngRequest) (string, error) { return version, nil }); err != nil {
+			t.Fatal(err)
+		}
+		values, err := client.CallFunction("version", nil, stringType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if values[0] != version {
+			t.Fatalf("expected %s, got %v", version, values[0])
+		}
+	}
+}
+
+func TestRegisterService(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	if err := server.RegisterService("calc", calculatorService{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterService("calc", calculatorService{}); err == nil {
+		t.Fatal("expected error for duplicate service registration")
+	}
+
+	values, err := client.CallFunction("calc.Add", []interface{}{int64(2), int64(3)}, []reflect.Type{reflect.TypeFor[int64]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != int64(5) {
+		t.Fatalf("expected 5, got %v", values[0])
+	}
+	values, err = client.CallFunction("calc.Negate", []interface{}{int64(4)}, []reflect.Type{reflect.TypeFor[int64]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != int64(-4) {
+		t.Fatalf("expected -4, got %v", values[0])
+	}
+}
+
+type inventoryReceiver struct{}
+
+func (inventoryReceiver) Count(req *bngsocket.BngRequest, item string) (int64, error) {
+	return int64(len(item)), nil
+}
+
+// Wird übersprungen, da der erste Parameter kein *BngRequest ist
+func (inventoryReceiver) Helper(item string) string {
+	return item
+}
+
+type brokenReceiver struct {
+	inventoryReceiver
+}
+
+func (brokenReceiver) Watch(req *bngsocket.BngRequest, updates chan string) error {
+	return nil
+}
+
+func (brokenReceiver) Store(req *bngsocket.BngRequest, value struct{ Name string }) error {
+	return nil
+}
+
+func TestRegisterReceiver(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	// Alle ungültigen Methoden werden gemeldet, es wird keine Methode registriert
+	err := server.RegisterReceiver("broken", brokenReceiver{})
+	if err == nil {
+		t.Fatal("expected error for invalid methods")
+	}
+	for _, name := range []string{"Watch", "Store"} {
+		if !strings.Contains(err.Error(), "method "+name) {
+			t.Fatalf("error does not report method %s: %v", name, err)
+		}
+	}
+	if _, err := client.CallFunction("broken.Count", []interface{}{"abc"}, []reflect.Type{reflect.TypeFor[int64]()}); !errors.Is(err, bngsocket.ErrUnkownRpcFunction) {
+		t.Fatalf("expected ErrUnkownRpcFunction, got %v", err)
+	}
+
+	if err := server.RegisterReceiver("inventory", inventoryReceiver{}); err != nil {
+		t.Fatal(err)
+	}
+	values, err := client.CallFunction("inventory.Count", []interface{}{"abcd"}, []reflect.Type{reflect.TypeFor[int64]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != int64(4) {
+		t.Fatalf("expected 4, got %v", values[0])
+	}
+	if _, err := client.CallFunction("inventory.Helper", []interface{}{"abcd"}, []reflect.Type{reflect.TypeFor[string]()}); !errors.Is(err, bngsocket.ErrUnkownRpcFunction) {
+		t.Fatalf("expected ErrUnkownRpcFunction, got %v", err)
+	}
+}
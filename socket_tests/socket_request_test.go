@@ -0,0 +1,89 @@
+package sockettests
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+func TestRPCRequestMetadata(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	type requestInfo struct {
+		id       string
+		method   string
+		metadata map[string]string
+		timeout  time.Duration
+	}
+	infos := make(chan requestInfo, 1)
+	err := server.RegisterFunction("inspect", func(req *bngsocket.BngRequest) (string, error) {
+		var timeout time.Duration
+		if deadline, ok := req.Context().Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+		infos <- requestInfo{id: req.RequestID(), method: req.Method(), metadata: req.Metadata(), timeout: timeout}
+		req.SetTrailer("served-by", "server")
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = bngsocket.ContextWithMetadata(ctx, map[string]string{"caller": "client"})
+	trailer := make(map[string]string)
+	ctx = bngsocket.ContextWithTrailer(ctx, trailer)
+
+	if _, err := client.CallFunctionContext(ctx, "inspect", nil, []reflect.Type{reflect.TypeFor[string]()}); err != nil {
+		t.Fatal(err)
+	}
+
+	info := <-infos
+	if info.id == "" || info.method != "inspect" || info.metadata["caller"] != "client" {
+		t.Fatalf("unexpected request info: %+v", info)
+	}
+	// Die verbleibende Zeit des Aufrufers wird übertragen und mit der eigenen Uhr übernommen
+	if info.timeout <= 4*time.Second || info.timeout > 5*time.Second {
+		t.Fatalf("unexpected remaining timeout %s", info.timeout)
+	}
+	if trailer["served-by"] != "server" {
+		t.Fatalf("trailer was not received: %v", trailer)
+	}
+}
+
+func TestRPCRequestCallerIdentity(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("peer credentials are only available on linux")
+	}
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	// Die Funktion gibt die Zugangsdaten des aufrufenden Prozesses zurück
+	err := server.RegisterFunction("whoami", func(req *bngsocket.BngRequest) (int64, int64, error) {
+		if req.RemoteAddr() == nil {
+			return 0, 0, errors.New("missing remote address")
+		}
+		creds, err := req.PeerCredentials()
+		if err != nil {
+			return 0, 0, err
+		}
+		return int64(creds.PID), int64(creds.UID), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := client.CallFunction("whoami", nil, []reflect.Type{reflect.TypeFor[int64](), reflect.TypeFor[int64]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != int64(os.Getpid()) || values[1] != int64(os.Getuid()) {
+		t.Fatalf("unexpected caller identity pid=%v uid=%v", values[0], values[1])
+	}
+}
@@ -0,0 +1,143 @@
+package sockettests
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+var errRetryTestBusy = bngsocket.NewRpcError("busy", "peer busy")
+
+func TestRPCRetryPolicy(t *testing.T) {
+	policy := &bngsocket.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, RetryableErrors: []error{errRetryTestBusy}}
+	server, client := newConnectedBngConnPair(t, nil, &bngsocket.BngConnConfig{RetryPolicies: map[string]*bngsocket.RetryPolicy{"flaky": policy}})
+
+	// Die Funktion schlägt bei den ersten beiden Aufrufen fehl
+	var calls atomic.Int32
+	err := server.RegisterFunction("flaky", func(req *bngsocket.BngRequest) (int64, error) {
+		if calls.Add(1) < 3 {
+			return 0, errRetryTestBusy
+		}
+		return 42, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = server.RegisterFunction("broken", func(req *bngsocket.BngRequest) (int64, error) {
+		calls.Add(1)
+		return 0, errors.New("broken")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := client.CallFunction("flaky", nil, []reflect.Type{reflect.TypeFor[int64]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != int64(42) || calls.Load() != 3 {
+		t.Fatalf("unexpected result %v after %d calls", values[0], calls.Load())
+	}
+
+	// Nicht wiederholbare Fehler werden sofort zurückgegeben
+	calls.Store(0)
+	ctx := bngsocket.ContextWithRetryPolicy(context.Background(), policy)
+	if _, err := client.CallFunctionContext(ctx, "broken", nil, []reflect.Type{reflect.TypeFor[int64]()}); err == nil || calls.Load() != 1 {
+		t.Fatalf("expected single failed call, got %v after %d calls", err, calls.Load())
+	}
+
+	// Nach der maximalen Anzahl an Versuchen wird der letzte Fehler zurückgegeben
+	calls.Store(-10)
+	if _, err := client.CallFunction("flaky", nil, []reflect.Type{reflect.TypeFor[int64]()}); !errors.Is(err, errRetryTestBusy) || err.Error() != errRetryTestBusy.Error() || calls.Load() != -7 {
+		t.Fatalf("expected %v after 3 calls, got %v after %d calls", errRetryTestBusy, err, calls.Load()+10)
+	}
+
+	// Fehler werden über ihren Code verglichen, eine gleichlautende Fehlermeldung ohne Code wird nicht wiederholt
+	err = server.RegisterFunction("uncoded", func(req *bngsocket.BngRequest) (int64, error) {
+		calls.Add(1)
+		return 0, errors.New(errRetryTestBusy.Error())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls.Store(0)
+	if _, err := client.CallFunctionContext(ctx, "uncoded", nil, []reflect.Type{reflect.TypeFor[int64]()}); err == nil || calls.Load() != 1 {
+		t.Fatalf("expected single failed call, got %v after %d calls", err, calls.Load())
+	}
+}
+
+func TestRPCIdempotencyKey(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	var calls atomic.Int64
+	err := server.RegisterFunction("charge", func(req *bngsocket.BngRequest, amount int64) (int64, error) {
+		return calls.Add(amount), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wiederholungen mit demselben Schlüssel erhalten die zwischengespeicherte Antwort
+	ctx := bngsocket.ContextWithIdempotencyKey(context.Background(), "order-1")
+	for i := 0; i < 3; i++ {
+		values, err := client.CallFunctionContext(ctx, "charge", []interface{}{int64(5)}, []reflect.Type{reflect.TypeFor[int64]()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if values[0] != int64(5) {
+			t.Fatalf("unexpected result %v", values[0])
+		}
+	}
+
+	// Ein anderer Schlüssel führt die Funktion erneut aus
+	ctx = bngsocket.ContextWithIdempotencyKey(context.Background(), "order-2")
+	values, err := client.CallFunctionContext(ctx, "charge", []interface{}{int64(5)}, []reflect.Type{reflect.TypeFor[int64]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != int64(10) || calls.Load() != 10 {
+		t.Fatalf("unexpected result %v", values[0])
+	}
+}
+
+func TestRPCRetryStopsWhenPeerGoingAway(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	err := server.RegisterFunction("block", func(req *bngsocket.BngRequest) error {
+		close(started)
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ein laufender Aufruf hält das Herunterfahren des Servers auf
+	go client.CallFunction("block", nil, nil)
+	<-started
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}()
+	waitUntil(t, client.PeerGoingAway)
+
+	// Wiederholungen erfolgen über dieselbe Verbindung und werden daher nach dem GOAWAY nicht durchgeführt
+	policy := &bngsocket.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, RetryableErrors: []error{bngsocket.ErrPeerGoingAway}}
+	ctx := bngsocket.ContextWithRetryPolicy(context.Background(), policy)
+	start := time.Now()
+	if _, err := client.CallFunctionContext(ctx, "block", nil, nil); !errors.Is(err, bngsocket.ErrPeerGoingAway) {
+		t.Fatalf("expected ErrPeerGoingAway, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("call was retried on a going away connection (%s)", elapsed)
+	}
+}
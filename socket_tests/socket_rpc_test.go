@@ -111,10 +111,6 @@ func serveConn_ServerSideRPC(conn net.Conn) {
 
 // TestRPCSocket verwendet Unix-Sockets anstelle von net.Pipe()
 func TestRPCSocket(t *testing.T) {
-	bngsocket.DebugSetPrintFunction(func(v ...any) {
-		fmt.Println(v...)
-	})
-
 	// Erstellen eines temporären Unix-Socket-Pfads
 	socketDir := os.TempDir()
 	socketPath := filepath.Join(socketDir, fmt.Sprintf("test_socket_%d.sock", time.Now().UnixNano()))
@@ -129,6 +125,8 @@ func TestRPCSocket(t *testing.T) {
 	}
 	defer listener.Close()
 
+	// Abgeschlossen wird der Test durch den Server nach dem Schließen seiner Verbindung
+	// und durch das Monitoring der Client Verbindung
 	mainWait.Add(2)
 	clientWait.Add(1)
 	serverWait.Add(1)
@@ -156,10 +154,7 @@ func TestRPCSocket(t *testing.T) {
 	defer conn.Close()
 
 	// Starten des Client-Seitigen RPC
-	go func() {
-		serveConn_ClientSideRPC(conn)
-		mainWait.Done()
-	}()
+	go serveConn_ClientSideRPC(conn)
 
 	// Warten auf den Abschluss aller Goroutinen
 	mainWait.Wait()
@@ -0,0 +1,209 @@
+package sockettests
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+func TestShutdownWaitsForRunningCalls(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	err := server.RegisterFunction("slow", func(req *bngsocket.BngRequest) (string, error) {
+		close(started)
+		<-release
+		return "done", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Es wird ein Aufruf gestartet, welcher erst nach dem GOAWAY abgeschlossen wird
+	callResult := make(chan error, 1)
+	go func() {
+		result, err := client.CallFunction("slow", []interface{}{}, []reflect.Type{reflect.TypeFor[string]()})
+		if err == nil && (len(result) != 1 || result[0] != "done") {
+			err = errors.New("invalid result")
+		}
+		callResult <- err
+	}()
+	<-started
+
+	// Der Server wird heruntergefahren, während der Aufruf noch läuft
+	shutdownResult := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownResult <- server.Shutdown(ctx)
+	}()
+
+	// Die Gegenseite darf keine neuen Aufrufe mehr starten
+	waitUntil(t, client.PeerGoingAway)
+	if _, err := client.CallFunction("slow", []interface{}{}, []reflect.Type{reflect.TypeFor[string]()}); !errors.Is(err, bngsocket.ErrPeerGoingAway) {
+		t.Fatalf("expected ErrPeerGoingAway, got %v", err)
+	}
+	if _, err := client.JoinChannel("chan"); !errors.Is(err, bngsocket.ErrPeerGoingAway) {
+		t.Fatalf("expected ErrPeerGoingAway, got %v", err)
+	}
+
+	// Der laufende Aufruf wird abgeschlossen, danach muss Shutdown zurückkehren
+	close(release)
+	if err := <-callResult; err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-shutdownResult:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown did not return")
+	}
+	if !bngsocket.IsConnectionClosed(server) {
+		t.Fatal("connection must be closed after shutdown")
+	}
+}
+
+func TestShutdownContextExpired(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	err := server.RegisterFunction("block", func(req *bngsocket.BngRequest) error {
+		close(started)
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go client.CallFunction("block", []interface{}{}, []reflect.Type{})
+	<-started
+
+	// Läuft der Context ab, wird die Verbindung trotzdem geschlossen
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := server.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	// Die Gegenseite muss das Schließen der Verbindung bemerken
+	waitUntil(t, func() bool { return bngsocket.IsConnectionClosed(client) })
+}
+
+func TestShutdownWaitsForOutgoingBatch(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	err := server.RegisterFunction("slow", func(req *bngsocket.BngRequest) (string, error) {
+		close(started)
+		<-release
+		return "done", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Der Client wartet auf die Antwort seines Batches
+	batchResult := make(chan error, 1)
+	go func() {
+		results, err := client.Batch().Call("slow", nil, []reflect.Type{reflect.TypeFor[string]()}).Do()
+		if err == nil && (results[0].Err != nil || results[0].Values[0] != "done") {
+			err = errors.New("invalid batch result")
+		}
+		batchResult <- err
+	}()
+	<-started
+
+	// Das Herunterfahren des Clients wartet auf die ausstehende Antwort
+	shutdownResult := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownResult <- client.Shutdown(ctx)
+	}()
+	select {
+	case err := <-shutdownResult:
+		t.Fatalf("shutdown returned while the batch was in flight: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-batchResult; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-shutdownResult; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCloseFromInsideHandler(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	// Die Funktion schließt die eigene Verbindung, Close darf dabei nicht auf die Funktion selbst warten
+	closed := make(chan error, 1)
+	err := server.RegisterFunction("close", func(req *bngsocket.BngRequest) error {
+		closed <- req.Conn.Close()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go client.CallFunction("close", []interface{}{}, nil)
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked inside the handler")
+	}
+
+	// Done wird geschlossen, sobald die Funktion zurückgekehrt ist
+	select {
+	case <-server.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Done was not closed")
+	}
+	if server.State() != bngsocket.StateClosed {
+		t.Fatalf("unexpected state %s", server.State())
+	}
+}
+
+func TestShutdownFromInsideHandler(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	// Mit dem Context der Anfrage wird auf die Funktion selbst nicht gewartet
+	shutdown := make(chan error, 1)
+	err := server.RegisterFunction("shutdown", func(req *bngsocket.BngRequest) error {
+		shutdown <- req.Conn.Shutdown(req.Context())
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go client.CallFunction("shutdown", []interface{}{}, nil)
+
+	select {
+	case err := <-shutdown:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shutdown blocked inside the handler")
+	}
+	select {
+	case <-server.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Done was not closed")
+	}
+}
@@ -0,0 +1,92 @@
+package sockettests
+
+import (
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+func TestRPCReturnSignatureMismatch(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	var calls atomic.Int32
+	err := server.RegisterFunction("name", func(req *bngsocket.BngRequest) (string, error) {
+		calls.Add(1)
+		return "bob", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = server.RegisterFunction("find", func(req *bngsocket.BngRequest, name string) (*paramsTestUser, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Abweichende Rückgabetypen werden vor dem Aufruf der Funktion abgelehnt
+	for _, returnTypes := range [][]reflect.Type{
+		{reflect.TypeFor[int64]()},
+		{reflect.TypeFor[string](), reflect.TypeFor[string]()},
+		nil,
+	} {
+		if _, err := client.CallFunction("name", nil, returnTypes); !errors.Is(err, bngsocket.ErrSignatureMismatch) {
+			t.Fatalf("expected ErrSignatureMismatch for %v, got %v", returnTypes, err)
+		}
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("function was called %d times", calls.Load())
+	}
+
+	// Die Verbindung bleibt bestehen
+	values, err := client.CallFunction("name", nil, []reflect.Type{reflect.TypeFor[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != "bob" {
+		t.Fatalf("unexpected result %v", values[0])
+	}
+
+	// Ein leerer Zeiger entspricht dem erwarteten Struct
+	values, err = client.CallFunction("find", []interface{}{"alice"}, []reflect.Type{reflect.TypeFor[*paramsTestUser]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user, ok := values[0].(*paramsTestUser); !ok || user != nil {
+		t.Fatalf("expected nil user, got %#v", values[0])
+	}
+}
+
+func TestRPCInterfaceReturnCheckedAfterCall(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	// Der Typ eines interface{} Rückgabewertes steht erst nach dem Aufruf fest
+	var calls atomic.Int32
+	err := server.RegisterFunction("dynamic", func(req *bngsocket.BngRequest) (interface{}, error) {
+		calls.Add(1)
+		return "text", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Die Abweichung wird gemeldet, die Funktion wurde dabei jedoch bereits ausgeführt
+	if _, err := client.CallFunction("dynamic", nil, []reflect.Type{reflect.TypeFor[int64]()}); !errors.Is(err, bngsocket.ErrSignatureMismatch) {
+		t.Fatalf("expected ErrSignatureMismatch, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected the function to be called once, got %d", calls.Load())
+	}
+
+	// Passende Typen werden normal zurückgegeben
+	values, err := client.CallFunction("dynamic", nil, []reflect.Type{reflect.TypeFor[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != "text" {
+		t.Fatalf("unexpected result %v", values[0])
+	}
+}
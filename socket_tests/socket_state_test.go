@@ -0,0 +1,74 @@
+package sockettests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+func TestConnectionStateTransitions(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+	if server.State() != bngsocket.StateReady || client.State() != bngsocket.StateReady {
+		t.Fatalf("expected ready connections, got %s / %s", server.State(), client.State())
+	}
+
+	// Die Zustandsänderungen des Servers werden aufgezeichnet
+	var mu sync.Mutex
+	var transitions []bngsocket.State
+	server.OnStateChange(func(old, new bngsocket.State, err error) {
+		mu.Lock()
+		transitions = append(transitions, new)
+		mu.Unlock()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Der Server muss über Draining in den Zustand Closed gewechselt sein
+	mu.Lock()
+	if len(transitions) != 2 || transitions[0] != bngsocket.StateDraining || transitions[1] != bngsocket.StateClosed {
+		t.Fatalf("unexpected transitions: %v", transitions)
+	}
+	mu.Unlock()
+
+	// Die Gegenseite muss das Ende der Verbindung über Done bemerken
+	select {
+	case <-client.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("client connection was not closed")
+	}
+	if client.State() != bngsocket.StateClosed {
+		t.Fatalf("expected closed client, got %s", client.State())
+	}
+	if err := bngsocket.MonitorConnection(client); err != bngsocket.ErrConnectionClosedEOF {
+		t.Fatalf("expected ErrConnectionClosedEOF, got %v", err)
+	}
+}
+
+func TestConnectionStateHookFromConfig(t *testing.T) {
+	// Die Funktion wird vor dem Upgrade registriert und erhält daher auch den Übergang nach Ready
+	var mu sync.Mutex
+	var transitions [][2]bngsocket.State
+	config := &bngsocket.BngConnConfig{OnStateChange: func(old, new bngsocket.State, err error) {
+		mu.Lock()
+		transitions = append(transitions, [2]bngsocket.State{old, new})
+		mu.Unlock()
+	}}
+	_, client := newConnectedBngConnPair(t, nil, config)
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := [][2]bngsocket.State{{bngsocket.StateConnecting, bngsocket.StateReady}, {bngsocket.StateReady, bngsocket.StateClosed}}
+	if len(transitions) != len(expected) || transitions[0] != expected[0] || transitions[1] != expected[1] {
+		t.Fatalf("unexpected transitions: %v", transitions)
+	}
+}
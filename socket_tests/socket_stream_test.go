@@ -0,0 +1,195 @@
+package sockettests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+func TestRPCStream(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	// Server-Streaming: Es werden count Werte gesendet
+	err := server.RegisterFunction("count", func(req *bngsocket.BngRequest, stream *bngsocket.BngStream, count int64) error {
+		for i := int64(0); i < count; i++ {
+			if err := stream.Send(i); err != nil {
+				return err
+			}
+		}
+		if count > 3 {
+			return errors.New("too many")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Bidirektional: Jeder empfangene Wert wird in Großbuchstaben zurückgesendet
+	err = server.RegisterFunction("upper", func(req *bngsocket.BngRequest, stream *bngsocket.BngStream) error {
+		var value string
+		for stream.Next(&value) {
+			if err := stream.Send(strings.ToUpper(value)); err != nil {
+				return err
+			}
+		}
+		return stream.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := client.CallStream(ctx, "count", []interface{}{int64(3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received []int64
+	var value int64
+	for stream.Next(&value) {
+		received = append(received, value)
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(received, []int64{0, 1, 2}) {
+		t.Fatalf("unexpected values: %v", received)
+	}
+
+	// Der Fehler der Funktion wird nach den gesendeten Werten zurückgegeben
+	stream, err = client.CallStream(ctx, "count", []interface{}{int64(5)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	received = nil
+	for stream.Next(&value) {
+		received = append(received, value)
+	}
+	if len(received) != 5 || stream.Err() == nil || stream.Err().Error() != "too many" {
+		t.Fatalf("unexpected result: %v, %v", received, stream.Err())
+	}
+
+	stream, err = client.CallStream(ctx, "upper", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, word := range []string{"a", "b", "c"} {
+		if err := stream.Send(word); err != nil {
+			t.Fatal(err)
+		}
+		var answer string
+		if err := stream.Recv(&answer); err != nil {
+			t.Fatal(err)
+		}
+		if answer != strings.ToUpper(word) {
+			t.Fatalf("unexpected answer %q", answer)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	var rest string
+	if err := stream.Recv(&rest); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+
+	// Stream und Einzelaufruf dürfen nicht vertauscht werden
+	if _, err := client.CallFunction("count", []interface{}{int64(1)}, nil); !errors.Is(err, bngsocket.ErrRpcStreamMismatch) {
+		t.Fatalf("expected stream mismatch, got %v", err)
+	}
+	if _, err := client.CallStream(ctx, "unknown", nil); !errors.Is(err, bngsocket.ErrUnkownRpcFunction) {
+		t.Fatalf("expected unknown function, got %v", err)
+	}
+
+	// Die Verbindung bleibt nach den Streams verwendbar
+	if client.State() != bngsocket.StateReady || server.State() != bngsocket.StateReady {
+		t.Fatalf("connection state changed: %s / %s", client.State(), server.State())
+	}
+}
+
+func TestRPCStreamFrameSizeLimit(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, &bngsocket.BngConnConfig{MaxStreamFrameSize: 1024})
+
+	err := server.RegisterFunction("large", func(req *bngsocket.BngRequest, stream *bngsocket.BngStream, size int64) error {
+		return stream.Send(strings.Repeat("x", int(size)))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Werte innerhalb der Grenze werden empfangen
+	stream, err := client.CallStream(ctx, "large", []interface{}{int64(100)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var value string
+	if err := stream.Recv(&value); err != nil || len(value) != 100 {
+		t.Fatalf("unexpected result %d, %v", len(value), err)
+	}
+
+	// Ein zu großer Wert wird vor dem Einlesen abgelehnt und beendet den Stream
+	stream, err = client.CallStream(ctx, "large", []interface{}{int64(4096)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Recv(&value); !errors.Is(err, bngsocket.ErrFrameTooLarge) {
+		t.Fatalf("expected ErrFrameTooLarge, got %v", err)
+	}
+	if client.State() != bngsocket.StateReady {
+		t.Fatalf("connection state changed: %s", client.State())
+	}
+}
+
+func TestRPCStreamCallbackParameter(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	// Der Callback des Aufrufers wird während des Streams aufgerufen
+	err := server.RegisterFunction("progress", func(req *bngsocket.BngRequest, stream *bngsocket.BngStream, onProgress func(int64) error) error {
+		for i := int64(1); i <= 3; i++ {
+			if err := onProgress(i); err != nil {
+				return err
+			}
+			if err := stream.Send(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var reported []int64
+	stream, err := client.CallStream(ctx, "progress", []interface{}{func(value int64) error {
+		reported = append(reported, value)
+		return nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received []int64
+	var value int64
+	for stream.Next(&value) {
+		received = append(received, value)
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(received, []int64{1, 2, 3}) || !reflect.DeepEqual(reported, []int64{1, 2, 3}) {
+		t.Fatalf("unexpected values: %v / %v", received, reported)
+	}
+}
@@ -0,0 +1,131 @@
+package sockettests
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+// Zeichnet die erzeugten Spans auf
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordingSpan
+}
+
+type recordingSpan struct {
+	mu    *sync.Mutex
+	name  string
+	kind  bngsocket.SpanKind
+	attrs map[string]string
+	err   error
+	ended bool
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, kind bngsocket.SpanKind) (context.Context, bngsocket.Span) {
+	span := &recordingSpan{mu: &t.mu, name: name, kind: kind, attrs: make(map[string]string)}
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return ctx, span
+}
+
+func (t *recordingTracer) Ended(kind bngsocket.SpanKind) *recordingSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, span := range t.spans {
+		if span.kind == kind && span.ended {
+			return span
+		}
+	}
+	return nil
+}
+
+func (s *recordingSpan) SetAttribute(key string, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs[key] = value
+}
+
+func (s *recordingSpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *recordingSpan) End() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ended = true
+}
+
+func TestTraceContextPropagation(t *testing.T) {
+	clientTracer, serverTracer := new(recordingTracer), new(recordingTracer)
+	server, client := newConnectedBngConnPair(t, &bngsocket.BngConnConfig{Tracer: serverTracer}, &bngsocket.BngConnConfig{Tracer: clientTracer})
+
+	// Die Funktion gibt die Trace ID des Aufrufers zurück
+	err := server.RegisterFunction("traceid", func(req *bngsocket.BngRequest) (string, error) {
+		sc, ok := bngsocket.SpanContextFromContext(req.Context())
+		if !ok {
+			return "", errors.New("no trace context")
+		}
+		return hex.EncodeToString(sc.TraceID[:]), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := bngsocket.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := bngsocket.ContextWithSpanContext(context.Background(), sc)
+	result, err := client.CallFunctionContext(ctx, "traceid", []interface{}{}, []reflect.Type{reflect.TypeFor[string]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 || result[0] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("trace context was not propagated: %v", result)
+	}
+
+	// Auf beiden Seiten muss ein Span für den Aufruf erzeugt worden sein
+	span := clientTracer.Ended(bngsocket.SpanKindClient)
+	if span == nil || span.name != "traceid" || span.attrs["rpc_id"] == "" {
+		t.Fatal("missing client span")
+	}
+	waitUntil(t, func() bool { return serverTracer.Ended(bngsocket.SpanKindServer) != nil })
+}
+
+func TestCallFunctionContextCanceled(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	release := make(chan struct{})
+	err := server.RegisterFunction("slow", func(req *bngsocket.BngRequest) error {
+		<-release
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Der Aufruf wird abgebrochen, bevor die Antwort eintrifft
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.CallFunctionContext(ctx, "slow", []interface{}{}, []reflect.Type{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	// Die verspätete Antwort darf die Verbindung nicht beenden
+	close(release)
+	if _, err := client.CallFunction("slow", []interface{}{}, []reflect.Type{}); err != nil {
+		t.Fatal(err)
+	}
+	if bngsocket.IsConnectionClosed(client) {
+		t.Fatal("connection must stay open")
+	}
+}
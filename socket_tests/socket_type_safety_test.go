@@ -0,0 +1,91 @@
+package sockettests
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/custodia-cenv/bngsocket-go"
+)
+
+func TestRPCNumberRangeChecks(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, nil, nil)
+
+	err := server.RegisterFunction("byte", func(req *bngsocket.BngRequest, value uint8) (uint8, error) {
+		return value, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = server.RegisterFunction("wide", func(req *bngsocket.BngRequest) (uint16, error) {
+		return 300, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = server.RegisterFunction("half", func(req *bngsocket.BngRequest, value float32) (float32, error) {
+		return value / 2, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Werte außerhalb des Wertebereichs werden dem Aufrufer als ungültiger Parameter gemeldet
+	for _, value := range []interface{}{int64(300), int64(-1), float64(1.5)} {
+		if _, err := client.CallFunction("byte", []interface{}{value}, []reflect.Type{reflect.TypeFor[uint8]()}); !errors.Is(err, bngsocket.ErrInvalidParameter) {
+			t.Fatalf("expected ErrInvalidParameter for %v, got %v", value, err)
+		}
+	}
+	if _, err := client.CallFunction("half", []interface{}{float64(0.1)}, []reflect.Type{reflect.TypeFor[float32]()}); !errors.Is(err, bngsocket.ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter for lossy float, got %v", err)
+	}
+
+	// Die Verbindung bleibt bestehen, passende Werte werden in den erwarteten Typ umgewandelt
+	values, err := client.CallFunction("byte", []interface{}{int64(200)}, []reflect.Type{reflect.TypeFor[uint8]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != uint8(200) {
+		t.Fatalf("unexpected value %v (%T)", values[0], values[0])
+	}
+	values, err = client.CallFunction("half", []interface{}{float64(3)}, []reflect.Type{reflect.TypeFor[float32]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != float32(1.5) {
+		t.Fatalf("unexpected value %v (%T)", values[0], values[0])
+	}
+
+	// Rückgabewerte werden ebenfalls geprüft
+	if _, err := client.CallFunction("wide", nil, []reflect.Type{reflect.TypeFor[uint8]()}); !errors.Is(err, bngsocket.ErrValueOutOfRange) {
+		t.Fatalf("expected ErrValueOutOfRange, got %v", err)
+	}
+}
+
+func TestRPCStrictTypes(t *testing.T) {
+	server, client := newConnectedBngConnPair(t, &bngsocket.BngConnConfig{StrictTypes: true}, nil)
+
+	err := server.RegisterFunction("sum", func(req *bngsocket.BngRequest, values []int64) (int64, error) {
+		var sum int64
+		for _, value := range values {
+			sum += value
+		}
+		return sum, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := client.CallFunction("sum", []interface{}{[]int64{1, 2, 3}}, []reflect.Type{reflect.TypeFor[int64]()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values[0] != int64(6) {
+		t.Fatalf("unexpected sum %v", values[0])
+	}
+
+	// Im Strict Modus wird ein abweichender Typ abgelehnt, auch wenn er umgewandelt werden könnte
+	if _, err := client.CallFunction("sum", []interface{}{[]int32{1, 2, 3}}, []reflect.Type{reflect.TypeFor[int64]()}); !errors.Is(err, bngsocket.ErrInvalidParameter) {
+		t.Fatalf("expected ErrInvalidParameter, got %v", err)
+	}
+}
@@ -1,69 +1,134 @@
 package transport
 
 type TypeInfo struct {
-	Type string `msgpack:"type"`
+	Type string `msgpack:"type" json:"type"`
 }
 
 type RpcDataCapsle struct {
-	Type  string      `msgpack:"type"`
-	Value interface{} `msgpack:"value"`
+	Type    string      `msgpack:"type" json:"type"`
+	Value   interface{} `msgpack:"value" json:"value"`
+	Codec   string      `msgpack:"codec,omitempty" json:"codec,omitempty"`     // Codec, mit dem der Wert in Encoded kodiert wurde (leer: Wert in Value)
+	Encoded []byte      `msgpack:"encoded,omitempty" json:"encoded,omitempty"` // Mit dem Codec kodierter Wert
+	Kind    string      `msgpack:"kind,omitempty" json:"kind,omitempty"`       // Exakter Go Datentyp des Wertes (z.B. int64), wird im Strict Modus geprüft
 }
 
 type RpcRequest struct {
-	Type         string           `msgpack:"type"`
-	Params       []*RpcDataCapsle `msgpack:"parameters"`
-	ReturnDTypes []string         `msgpack:"returndtypes"`
-	Name         string           `msgpack:"name"`
-	Id           string           `msgpack:"id"`
+	Type           string            `msgpack:"type" json:"type"`
+	Params         []*RpcDataCapsle  `msgpack:"parameters" json:"parameters"`
+	ReturnDTypes   []string          `msgpack:"returndtypes" json:"returndtypes"`
+	Name           string            `msgpack:"name" json:"name"`
+	Id             string            `msgpack:"id" json:"id"`
+	Metadata       map[string]string `msgpack:"metadata,omitempty" json:"metadata,omitempty"`
+	Timeout        int64             `msgpack:"timeout,omitempty" json:"timeout,omitempty"`               // Verbleibende Zeit (Nanosekunden), für die der Aufrufer auf die Antwort wartet
+	Stream         bool              `msgpack:"stream,omitempty" json:"stream,omitempty"`                 // Der Aufruf wird als Stream über einen Channel mit der ID des Aufrufs ausgeführt
+	IdempotencyKey string            `msgpack:"idempotencykey,omitempty" json:"idempotencykey,omitempty"` // Schlüssel des logischen Aufrufs, Wiederholungen erhalten die zwischengespeicherte Antwort
 }
 
 // RpcResponse wird verwendet um die Antwortdaten zu übertragen
 type RpcResponse struct {
-	Type   string           `msgpack:"type"`
-	Error  string           `msgpack:"error,omitempty"`
-	Id     string           `msgpack:"id"`
-	Return []*RpcDataCapsle `msgpack:"return"`
+	Type     string            `msgpack:"type" json:"type"`
+	Error    string            `msgpack:"error,omitempty" json:"error,omitempty"`
+	Code     string            `msgpack:"code,omitempty" json:"code,omitempty"` // Stabiler Code des Fehlers, unabhängig von der Fehlermeldung
+	Id       string            `msgpack:"id" json:"id"`
+	Return   []*RpcDataCapsle  `msgpack:"return" json:"return"`
+	Metadata map[string]string `msgpack:"metadata,omitempty" json:"metadata,omitempty"`
+}
+
+// RpcBatchRequest überträgt mehrere RPC Aufrufe in einer Nachricht
+type RpcBatchRequest struct {
+	Type     string        `msgpack:"type" json:"type"`
+	Id       string        `msgpack:"id" json:"id"`
+	Requests []*RpcRequest `msgpack:"requests" json:"requests"`
+}
+
+// RpcBatchResponse überträgt die Antworten aller Aufrufe eines RpcBatchRequest
+type RpcBatchResponse struct {
+	Type      string         `msgpack:"type" json:"type"`
+	Id        string         `msgpack:"id" json:"id"`
+	Responses []*RpcResponse `msgpack:"responses" json:"responses"`
+}
+
+// RpcNotification wird verwendet um eine Funktion ohne Antwort aufzurufen
+type RpcNotification struct {
+	Type     string            `msgpack:"type" json:"type"`
+	Params   []*RpcDataCapsle  `msgpack:"parameters" json:"parameters"`
+	Name     string            `msgpack:"name" json:"name"`
+	Metadata map[string]string `msgpack:"metadata,omitempty" json:"metadata,omitempty"`
+}
+
+// TopicSubscribe wird verwendet um ein Topic bei der Gegenseite zu abonnieren
+type TopicSubscribe struct {
+	Type  string `msgpack:"type" json:"type"`
+	Id    string `msgpack:"id" json:"id"`
+	Topic string `msgpack:"topic" json:"topic"`
+}
+
+// TopicSubscribeAck bestätigt oder lehnt ein Abonnement ab
+type TopicSubscribeAck struct {
+	Type  string `msgpack:"type" json:"type"`
+	Id    string `msgpack:"id" json:"id"`
+	Error string `msgpack:"error,omitempty" json:"error,omitempty"`
+}
+
+// TopicUnsubscribe beendet ein Abonnement
+type TopicUnsubscribe struct {
+	Type string `msgpack:"type" json:"type"`
+	Id   string `msgpack:"id" json:"id"`
+}
+
+// TopicPublish überträgt eine veröffentlichte Nachricht an einen Abonnenten
+type TopicPublish struct {
+	Type    string `msgpack:"type" json:"type"`
+	Topic   string `msgpack:"topic" json:"topic"`
+	Payload []byte `msgpack:"payload" json:"payload"`
+	Seq     uint64 `msgpack:"seq,omitempty" json:"seq,omitempty"`
+}
+
+// Wird verwendet um der Gegenseite die unterstützten Verfahren mitzuteilen (Parameter und Rückgabe von _bng.hello)
+type ConnHello struct {
+	Compression []string `msgpack:"compression,omitempty" json:"compression,omitempty" rpc:"compression"`
+	Codecs      []string `msgpack:"codecs,omitempty" json:"codecs,omitempty" rpc:"codecs"`
 }
 
 type RpcHiddenFunction struct {
-	FunctionId string `msgpack:"id"`
+	FunctionId string `msgpack:"id" json:"id"`
 }
 
 // Wird verwendet um eine Channel Sitzung aufzubauen
 type ChannelRequest struct {
-	Type               string `msgpack:"type"`
-	Error              string `msgpack:"error,omitempty"`
-	RequestId          string `msgpack:"id"`
-	RequestedChannelId string `msgpack:"cid"`
+	Type               string `msgpack:"type" json:"type"`
+	Error              string `msgpack:"error,omitempty" json:"error,omitempty"`
+	RequestId          string `msgpack:"id" json:"id"`
+	RequestedChannelId string `msgpack:"cid" json:"cid"`
 }
 
 // Wird verwendet um zu bestätigen oder abzulehnen
 type ChannelRequestResponse struct {
-	Type                string `msgpack:"type"`
-	ReqId               string `msgpack:"rqid"`
-	ChannelId           string `msgpack:"cid"`
-	NotAcceptedByReason string `msgpack:"nabr"`
+	Type                string `msgpack:"type" json:"type"`
+	ReqId               string `msgpack:"rqid" json:"rqid"`
+	ChannelId           string `msgpack:"cid" json:"cid"`
+	NotAcceptedByReason string `msgpack:"nabr" json:"nabr"`
 }
 
 // Wird verwendet um Sitzungspakete zu übertragen
 type ChannelSessionDataTransport struct {
-	Type             string `msgpack:"type"`
-	ChannelSessionId string `msgpack:"csid"`
-	PackageId        uint64 `msgpack:"pid"`
-	Body             []byte `msgpack:"body"`
+	Type             string `msgpack:"type" json:"type"`
+	ChannelSessionId string `msgpack:"csid" json:"csid"`
+	PackageId        uint64 `msgpack:"pid" json:"pid"`
+	Body             []byte `msgpack:"body" json:"body"`
 }
 
 // Wird verwendet um zu bestätigen das die Daten übertragen wurden
 type ChannelTransportStateResponse struct {
-	Type             string `msgpack:"type"`
-	ChannelSessionId string `msgpack:"csid"`
-	PackageId        uint64 `msgpack:"pid"`
-	State            uint8  `msgpack:"state"`
+	Type             string `msgpack:"type" json:"type"`
+	ChannelSessionId string `msgpack:"csid" json:"csid"`
+	PackageId        uint64 `msgpack:"pid" json:"pid"`
+	State            uint8  `msgpack:"state" json:"state"`
 }
 
 // Wird verwendet um einen Channel Ordnungsgemäß zu schließen
 type ChannlSessionTransportSignal struct {
-	Type             string `msgpack:"type"`
-	ChannelSessionId string `msgpack:"csid"`
-	Signal           uint64 `msgpack:"pid"`
+	Type             string `msgpack:"type" json:"type"`
+	ChannelSessionId string `msgpack:"csid" json:"csid"`
+	Signal           uint64 `msgpack:"pid" json:"pid"`
 }
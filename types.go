@@ -3,9 +3,13 @@ package bngsocket
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"log/slog"
 	"net"
 	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/custodia-cenv/bngsocket-go/transport"
 )
@@ -34,9 +38,45 @@ type _ConnACK struct {
 	state uint8       // Aktueller Zustand der ACK-Verarbeitung
 }
 
+// _Keepalive speichert den Zustand der Keepalive Pings einer Verbindung.
+type _Keepalive struct {
+	nonce      atomic.Uint64 // Fortlaufende Nummer der gesendeten Pings
+	pingNonce  atomic.Uint64 // Nummer des zuletzt gesendeten Pings
+	pingSentAt atomic.Int64  // Sendezeitpunkt des zuletzt gesendeten Pings (UnixNano)
+	lastPong   atomic.Int64  // Zeitpunkt des zuletzt empfangenen Pongs (UnixNano)
+	rtt        atomic.Int64  // Zuletzt gemessene Round-Trip-Time
+}
+
+// _PublishOrder nummeriert die veröffentlichten Nachrichten einer Verbindung, damit die Gegenseite
+// diese trotz nebenläufiger Verarbeitung in der Reihenfolge der Veröffentlichung zustellen kann.
+type _PublishOrder struct {
+	sendMu   sync.Mutex                         // Serialisiert das Senden, damit die Nummern in ihrer Reihenfolge übertragen werden
+	sendSeq  uint64                             // Nummer der zuletzt gesendeten Nachricht
+	recvMu   sync.Mutex                         // Schützt die Zustellung der empfangenen Nachrichten
+	recvSeq  uint64                             // Nummer der zuletzt zugestellten Nachricht
+	pending  map[uint64]*transport.TopicPublish // Empfangene Nachrichten, deren Vorgänger noch aussteht
+	gapTimer *time.Timer                        // Überspringt eine Lücke, falls die fehlende Nachricht nicht eintrifft
+	gapSeq   uint64                             // Nummer der zuletzt zugestellten Nachricht beim Start des Timeouts
+}
+
+// _ConnState speichert den Zustand einer Verbindung sowie die Funktionen, welche bei einer Änderung aufgerufen werden
+type _ConnState struct {
+	mu      sync.Mutex
+	current State                             // Aktueller Zustand der Verbindung
+	err     error                             // Fehler, mit dem die Verbindung beendet wurde
+	hooks   []func(old, new State, err error) // Funktionen, welche bei einer Zustandsänderung aufgerufen werden
+	ended   chan struct{}                     // Wird geschlossen, sobald ein finaler Zustand erreicht wurde
+	done    chan struct{}                     // Wird geschlossen, sobald zusätzlich alle Hintergrundaufgaben beendet wurden
+}
+
 // BngConn stellt die Verbindung und den Status eines BNG (Broadband Network Gateway) dar.
 type BngConn struct {
-	_innerhid string // Eindeutige interne ID der Verbindung
+	_innerhid  string         // Eindeutige interne ID der Verbindung
+	config     *BngConnConfig // Konfiguration der Verbindung
+	logger     *slog.Logger   // Logger der Verbindung, enthält die ID der Verbindung als Attribut
+	metrics    Metrics        // Erfasst die Kennzahlen der Verbindung
+	tracer     Tracer         // Erzeugt die Spans der RPC Aufrufe
+	propagator Propagator     // Überträgt den Trace Context über die Metadaten der RPC Aufrufe
 
 	// Verbindung und I/O
 	conn      net.Conn      // Socket-Verbindung des BNG
@@ -49,24 +89,154 @@ type BngConn struct {
 	closed       _SafeBool         // Flag, das angibt, ob der Socket geschlossen wurde
 	closing      _SafeBool         // Flag, das angibt, ob der Socket geschlossen werden soll
 	runningError _SafeValue[error] // Speichert Fehler, die während des Betriebs auftreten
+	state        *_ConnState       // Zustand der Verbindung (Connecting, Ready, Draining, Closed, Failed)
 
 	// Writer-Synchronisation
-	writerMutex *sync.Mutex // Mutex zum Schutz des Writers
+	writerMutex   *sync.Mutex // Mutex zum Schutz des Writers
+	transferMutex *sync.Mutex // Stellt sicher, dass immer nur eine Nachricht gleichzeitig übertragen wird
+
+	// Kompression
+	compressionNegotiated _SafeBool           // Gibt an ob die Kompression mit der Gegenseite ausgehandelt wurde
+	compressionOut        _CompressionCounter // Zähler für ausgehende komprimierte Nachrichten
+	compressionIn         _CompressionCounter // Zähler für eingehende komprimierte Nachrichten
+
+	// Codec
+	negotiatedCodec _SafeValue[Codec] // Mit der Gegenseite ausgehandelter Codec für ausgehende Nachrichten
+
+	// Keepalive
+	keepalive _Keepalive // Zustand der Keepalive Pings
+
+	// Herunterfahren
+	draining        _SafeBool // Gibt an ob die Verbindung heruntergefahren wird (GOAWAY gesendet)
+	peerGoingAway   _SafeBool // Gibt an ob die Gegenseite die Verbindung herunterfährt (GOAWAY empfangen)
+	runningRpcCalls _SafeInt  // Anzahl der empfangenen und noch nicht vollständig verarbeiteten Nachrichten
 
 	// RPC-Variablen
-	functions           _SafeMap[string, reflect.Value]               // Registrierte Funktionen
-	openRpcRequests     _SafeMap[string, chan *transport.RpcResponse] // Offene RPC-Anfragen
-	backgroundProcesses *sync.WaitGroup                               // Wartet auf laufende Hintergrundprozesse
+	functions           _SafeMap[string, reflect.Value]                    // Registrierte Funktionen
+	hiddenFunctions     _SafeMap[string, reflect.Value]                    // Als Parameter übergebene Callbacks laufender Aufrufe
+	openRpcRequests     _SafeMap[string, chan *transport.RpcResponse]      // Offene RPC-Anfragen
+	openRpcBatches      _SafeMap[string, chan *transport.RpcBatchResponse] // Offene RPC-Batch-Anfragen
+	idempotency         *_IdempotencyCache                                 // Antworten der Aufrufe mit Idempotency Key
+	backgroundProcesses *sync.WaitGroup                                    // Wartet auf laufende Hintergrundprozesse
 
 	// Channel-Variablen
 	openChannelListener      _SafeMap[string, *BngConnChannelListener]                // Verfügbare Channel-Listener
 	openChannelInstances     _SafeMap[string, *BngConnChannel]                        // Aktive Channel-Instanzen
 	openChannelJoinProcesses _SafeMap[string, chan *transport.ChannelRequestResponse] // Offene Channel-Join-Prozesse
+
+	// Pub/Sub-Variablen
+	localSubscriptions    _SafeMap[string, *BngSubscription]                  // Eigene Abonnements, nach ID
+	remoteSubscriptions   _SafeMap[string, string]                            // Abonnements der Gegenseite (ID -> Topic)
+	openSubscribeRequests _SafeMap[string, chan *transport.TopicSubscribeAck] // Offene Abonnement-Anfragen
+	publishes             _PublishOrder                                       // Reihenfolge der gesendeten und empfangenen Nachrichten
 }
 
 // BngRequest stellt eine Anfrage an eine BNG-Verbindung dar.
 type BngRequest struct {
-	Conn *BngConn // Verweis auf die BNG-Verbindung, die diese Anfrage bearbeitet
+	Conn      *BngConn          // Verweis auf die BNG-Verbindung, die diese Anfrage bearbeitet
+	ctx       context.Context   // Context der Anfrage, enthält den Trace Context und die Deadline des Aufrufers
+	id        string            // ID des RPC Aufrufs
+	method    string            // Name, unter dem die Funktion aufgerufen wurde
+	metadata  map[string]string // Metadaten des Aufrufers
+	trailerMu sync.Mutex        // Schützt den Trailer
+	trailer   map[string]string // Metadaten, welche mit der Antwort zurückgesendet werden
+}
+
+// BngStream stellt einen Stream-RPC Aufruf dar, über den beide Seiten beliebig viele Werte austauschen können.
+// Der Stream läuft über eine Channel Sitzung, deren ID der ID des RPC Aufrufs entspricht.
+type BngStream struct {
+	conn         *BngConn                    // Verbindung, über die der Stream läuft
+	channel      *BngConnChannel             // Channel Sitzung des Streams
+	ctx          context.Context             // Context des Aufrufs
+	id           string                      // ID des RPC Aufrufs
+	method       string                      // Name der aufgerufenen Funktion
+	clientSide   bool                        // Gibt an ob der Stream vom Aufrufer verwendet wird
+	responseChan chan *transport.RpcResponse // Chan für die abschließende Antwort (nur Aufrufer)
+	sendMu       sync.Mutex                  // Serialisiert die Sendevorgänge
+	recvMu       sync.Mutex                  // Serialisiert die Lesevorgänge
+	sendClosed   _SafeBool                   // Gibt an ob die Senderichtung geschlossen wurde
+	recvClosed   _SafeBool                   // Gibt an ob die Gegenseite ihre Senderichtung geschlossen hat
+	finishOnce   sync.Once                   // Stellt sicher dass der Aufruf nur einmal abgeschlossen wird
+	finished     chan struct{}               // Wird geschlossen, sobald der Aufruf abgeschlossen wurde
+	err          error                       // Ergebnis des Aufrufs (nur Aufrufer)
+	iterErr      error                       // Fehler, welcher Next beendet hat
+	onFinish     func(err error)             // Wird beim Abschluss des Aufrufs ausgeführt (Span, Kennzahlen)
+}
+
+// BngBatch sammelt mehrere RPC Aufrufe, welche gemeinsam in einer Nachricht übertragen werden.
+type BngBatch struct {
+	conn  *BngConn
+	calls []*_BatchCall
+}
+
+// _BatchCall ist ein einzelner Aufruf eines Batches.
+type _BatchCall struct {
+	name           string
+	params         []interface{}
+	returnDataType []reflect.Type
+}
+
+// BngBatchResult enthält das Ergebnis eines einzelnen Aufrufs eines Batches.
+type BngBatchResult struct {
+	Values []interface{} // Rückgabewerte des Aufrufs
+	Err    error         // Fehler des Aufrufs
+}
+
+// PeerCredentials beschreibt den Prozess der Gegenseite einer Unix-Socket Verbindung.
+type PeerCredentials struct {
+	PID int32  // Prozess ID der Gegenseite
+	UID uint32 // Benutzer ID, unter welcher der Prozess der Gegenseite läuft
+	GID uint32 // Gruppen ID, unter welcher der Prozess der Gegenseite läuft
+}
+
+// BngCallback ist ein Callback, welchen die Gegenseite als Parameter eines RPC Aufrufs übergeben hat.
+// Der Callback ist bis zum Abschluss des Aufrufs oder bis zu seiner Freigabe aufrufbar.
+type BngCallback struct {
+	conn        *BngConn           // Verbindung, über die der Callback aufgerufen wird
+	ctx         context.Context    // Context des Callbacks, wird mit der Freigabe oder dem Beenden der Verbindung abgebrochen
+	cancel      context.CancelFunc // Bricht den Context des Callbacks ab
+	id          string             // ID der versteckten Funktion auf der Gegenseite
+	released    _SafeBool          // Gibt an ob der Callback freigegeben wurde
+	releaseOnce sync.Once          // Stellt sicher dass die Freigabe nur einmal übermittelt wird
+}
+
+// BngServiceDescription beschreibt die Funktionen und Channel Listener, welche eine Verbindung bereitstellt.
+type BngServiceDescription struct {
+	Functions        []*BngFunctionDescription `rpc:"functions"`        // Registrierte Funktionen, nach Namen sortiert
+	ChannelListeners []string                  `rpc:"channelListeners"` // IDs der geöffneten Channel Listener, sortiert
+}
+
+// BngFunctionDescription beschreibt die Signatur einer registrierten Funktion.
+type BngFunctionDescription struct {
+	Name    string   `rpc:"name"`    // Name, unter dem die Funktion registriert wurde
+	Params  []string `rpc:"params"`  // Go Datentypen der Parameter (ohne *BngRequest bzw. *BngStream)
+	Returns []string `rpc:"returns"` // Go Datentypen der Rückgabewerte (ohne den abschließenden error)
+	Stream  bool     `rpc:"stream"`  // Gibt an ob es sich um eine Stream Funktion handelt
+}
+
+// BngMessage ist eine über ein Topic empfangene Nachricht.
+type BngMessage struct {
+	Topic   string // Topic, unter dem die Nachricht veröffentlicht wurde
+	payload []byte // Mit dem ausgehandelten Codec der Gegenseite kodierter Wert (inkl. Codec Kennung)
+}
+
+// BngSubscription ist ein Abonnement eines Topics bei der Gegenseite.
+type BngSubscription struct {
+	conn    *BngConn          // Verbindung, über die das Topic abonniert wurde
+	id      string            // ID des Abonnements
+	topic   string            // Abonniertes Topic, kann Wildcards enthalten
+	handler func(*BngMessage) // Wird für jede passende Nachricht aufgerufen
+
+	mu         sync.Mutex    // Schützt die Warteschlange
+	queue      []*BngMessage // Nachrichten, welche noch an den Handler übergeben werden
+	delivering bool          // Gibt an ob eine Routine die Warteschlange abarbeitet
+}
+
+// BngHub verteilt veröffentlichte Nachrichten an alle hinzugefügten Verbindungen, deren Gegenseite
+// ein passendes Topic abonniert hat.
+type BngHub struct {
+	mu    sync.Mutex
+	conns map[*BngConn]struct{} // Verbindungen des Hubs
 }
 
 // bngConnAcceptingRequest beschreibt eine Anfrage, um einen neuen Channel zu akzeptieren.
@@ -93,6 +263,7 @@ type BngConnChannel struct {
 	openWriters         _SafeInt          // Zähler für die Anzahl der aktuell offenen Schreiboperationen
 	bytesDataInCache    *_ByteCache       // Cache für die eingehenden Daten
 	ackChan             _SafeAck          // Kanal für ACK-Rückmeldungen
+	joined              chan struct{}     // Wird geschlossen, sobald die Gegenseite einem neu erzeugten Channel beigetreten ist (nil bei beigetretenen Channeln)
 	channelRunningError _SafeValue[error] // Speichert Fehler ab, welche bei der Verwendung des Channels auftreten können
 	mu                  *sync.Mutex       // Mutex zum Schutz des Channels
 }
@@ -2,7 +2,7 @@ package bngsocket
 
 import (
 	"crypto/tls"
-	"fmt"
+	"log/slog"
 	"net"
 
 	"golang.org/x/net/websocket"
@@ -21,34 +21,73 @@ import (
 //   - *BngConn: Ein Zeiger auf das neu erstellte BngConn Objekt, das die Socket-Verbindung verwaltet.
 //   - error: Ein Fehler, falls der Verbindungstyp nicht unterstützt wird oder ein anderer Fehler auftritt.
 func UpgradeSocketToBngConn(socket net.Conn) (*BngConn, error) {
+	return UpgradeSocketToBngConnWithConfig(socket, nil)
+}
+
+// UpgradeSocketToBngConnWithConfig wandelt einen gegebenen net.Conn unter Verwendung
+// der übergebenen Konfiguration in ein *BngConn Objekt um. Wird keine Konfiguration
+// übergeben (nil), wird die Standardkonfiguration verwendet.
+//
+// Parameter:
+//   - socket net.Conn: Das zu upgradende Socket, das verschiedene Verbindungstypen unterstützen kann.
+//   - config *BngConnConfig: Die Konfiguration der Verbindung.
+//
+// Rückgabe:
+//   - *BngConn: Ein Zeiger auf das neu erstellte BngConn Objekt, das die Socket-Verbindung verwaltet.
+//   - error: Ein Fehler, falls der Verbindungstyp oder die Konfiguration nicht unterstützt wird.
+func UpgradeSocketToBngConnWithConfig(socket net.Conn, config *BngConnConfig) (*BngConn, error) {
+	// Die Konfiguration wird vervollständigt und geprüft
+	config = normalizeBngConnConfig(config)
+	if err := validateBngConnConfig(config); err != nil {
+		return nil, err
+	}
+
 	// Es wird geprüft, ob es sich um einen zulässigen Socket handelt
 	// Außerdem wird das Basis BNG Objekt erzeugt
 	var client *BngConn
 	switch socket.(type) {
 	case *net.UnixConn:
-		client = _NewBaseBngSocketObject(socket)
+		client = _NewBaseBngSocketObject(socket, config)
 	case *net.TCPConn:
-		client = _NewBaseBngSocketObject(socket)
+		client = _NewBaseBngSocketObject(socket, config)
 	case *websocket.Conn:
-		client = _NewBaseBngSocketObject(socket)
+		client = _NewBaseBngSocketObject(socket, config)
 	case *tls.Conn:
-		client = _NewBaseBngSocketObject(socket)
+		client = _NewBaseBngSocketObject(socket, config)
 	default:
 		return nil, ErrUnsupportedSocketType
 	}
 
-	// Die Anzahl der Routinen wird übermittelt
-	client.backgroundProcesses.Add(2)
-
 	// Debug-Ausgabe zur Bestätigung des Upgrades
-	_DebugPrint(fmt.Sprintf("Connection upgraded to BngConn = %s", client._innerhid))
+	client.logger.Info("Connection upgraded to BngConn")
 
 	// Es wird eine Routine gestartet, welche für das Senden der ausgehenden Daten ist
 	//go constantWriting(client)
 
-	// Es wird eine Routine gestartet, welche Parameter Daten liest
+	// Es wird eine Routine gestartet, welche Parameter Daten liest. Jede gestartete Routine wird
+	// einzeln angemeldet, da Close auf alle angemeldeten Routinen wartet und eine nicht gestartete
+	// Routine (wie die deaktivierte Schreibroutine) das Schließen dauerhaft blockieren würde
+	client.backgroundProcesses.Add(1)
 	go constantReading(client)
 
+	// Sollten Keepalive Pings gewünscht sein, wird eine Routine gestartet welche die Gegenseite überwacht
+	if config.KeepaliveInterval > 0 {
+		client.backgroundProcesses.Add(1)
+		go constantKeepalive(client)
+	}
+
+	// Die Verbindung ist bereit
+	setConnState(client, StateReady, nil)
+
+	// Sollte eine Kompression oder ein anderer Codec gewünscht sein, wird dies mit der Gegenseite ausgehandelt
+	if config.Compression != CompressionNone || config.Codec != CodecMsgpack {
+		go func() {
+			if err := negotiateConnHello(client); err != nil {
+				client.logger.Warn("Negotiation failed", slog.Any(logKeyError, err))
+			}
+		}()
+	}
+
 	// Das Objekt wird zurückgegeben
 	return client, nil
 }
@@ -12,25 +12,29 @@ import (
 
 // Speichert alle Zulässigen Transportdatentypen ab
 var supportedTypes = map[string]bool{
-	"bool":   true,
-	"string": true,
-	"map":    true,
-	"slice":  true,
-	"int":    true,
-	"uint":   true,
-	"float":  true,
-	"struct": true,
-	"func":   true,
+	"bool":    true,
+	"string":  true,
+	"map":     true,
+	"slice":   true,
+	"int":     true,
+	"uint":    true,
+	"float":   true,
+	"struct":  true,
+	"func":    true,
+	"channel": true,
+	"proto":   true,
+	"custom":  true,
+	"named":   true,
 }
 
 // Speichert alle Explizit Verbotenen Datentypen ab
-var explicitNotAllowDataTypes = map[reflect.Kind]bool{
-	reflect.TypeFor[_ByteCache]().Kind():              true,
-	reflect.TypeFor[BngConn]().Kind():                 true,
-	reflect.TypeFor[BngRequest]().Kind():              true,
-	reflect.TypeFor[bngConnAcceptingRequest]().Kind(): true,
-	reflect.TypeFor[BngConnChannelListener]().Kind():  true,
-	reflect.TypeFor[BngConnChannel]().Kind():          true,
+var explicitNotAllowDataTypes = map[reflect.Type]bool{
+	reflect.TypeFor[_ByteCache]():              true,
+	reflect.TypeFor[BngConn]():                 true,
+	reflect.TypeFor[BngRequest]():              true,
+	reflect.TypeFor[bngConnAcceptingRequest](): true,
+	reflect.TypeFor[BngConnChannelListener]():  true,
+	reflect.TypeFor[BngConnChannel]():          true,
 }
 
 // isErrorType prüft, ob der Typ ein error ist
@@ -94,10 +98,15 @@ func isValidMessagePackType(t reflect.Type) error {
 			}
 
 			// Es wird geprüft ob es sich um ein Verbotenes Struct handelt
-			if found, blocked := explicitNotAllowDataTypes[t.Kind()]; found && blocked {
+			if found, blocked := explicitNotAllowDataTypes[t]; found && blocked {
 				return fmt.Errorf("not allowed struct type")
 			}
 
+			// Optional Felder (z.B. benannter Parameter) werden anhand des enthaltenen Wertes geprüft
+			if valueType, optional := optionalValueType(t); optional {
+				return function(valueType, false, isMapValue, isSliceValue)
+			}
+
 			// Es werden alle Getaggeten Daten geprüft
 			foundedFileds := 0
 			for i := 0; i < t.NumField(); i++ {
@@ -195,15 +204,56 @@ func validateRPCFunction(fnValue reflect.Value, fnType reflect.Type, isRegisterS
 		if !(firstParam.Kind() == reflect.Ptr && firstParam.Elem().ConvertibleTo(contextType)) {
 			return fmt.Errorf("validateRPCFunction[4]: the first parameter of function isn't compatible with *Request")
 		}
+
+		// Bei einer Stream Funktion folgt der *BngStream, diese darf nur einen Fehler zurückgeben
+		if isStreamFunction(fnType) {
+			beginAt = 2
+			if fnType.NumOut() != 1 {
+				return fmt.Errorf("validateRPCFunction[4a]: stream functions must only return an error")
+			}
+		}
 	} else {
 		// Die Startposition wird festgelegt
 		beginAt = 0
 	}
 
 	// Prüfe die restlichen Parameter auf zulässige MessagePack-Typen
+	optionalFound := false
 	for i := beginAt; i < fnType.NumIn(); i++ {
 		param := fnType.In(i)
 
+		// Bei variadischen Funktionen werden die Elemente des letzten Parameters einzeln übertragen
+		isVariadicParam := isRegisterSide && fnType.IsVariadic() && i == fnType.NumIn()-1
+		if isVariadicParam {
+			param = param.Elem()
+		}
+
+		// Optional Parameter dürfen nur am Ende stehen, es wird der enthaltene Wert übertragen
+		if valueType, ok := optionalValueType(param); isRegisterSide && ok && !isVariadicParam {
+			if isCallbackParameterType(valueType) || isChannelType(valueType) {
+				return fmt.Errorf("validateRPCFunction[4c]: parameter %d: optional callbacks and channels are not supported", i)
+			}
+			optionalFound, param = true, valueType
+		} else if optionalFound && !isVariadicParam {
+			return fmt.Errorf("validateRPCFunction[4d]: parameter %d follows an optional parameter and must be optional", i)
+		}
+
+		// Auf der registrierenden Seite können Callbacks der Gegenseite entgegengenommen werden
+		if isRegisterSide && isCallbackParameterType(param) {
+			if param.Kind() == reflect.Func {
+				if err := validateRPCFunction(reflect.Zero(param), param, false); err != nil {
+					return fmt.Errorf("validateRPCFunction[4b]: parameter %d is an invalid callback: %w", i, err)
+				}
+			}
+			continue
+		}
+
+		// Channel werden über die ID ihrer Sitzung übertragen, Protocol Buffers Nachrichten mit dem Protobuf Codec
+		// und eigene Typen mit ihrer registrierten Kodierung
+		if isChannelType(param) || isProtoMessageType(param) || isCustomType(param) {
+			continue
+		}
+
 		// Wenn der Parameter ein Pointer ist
 		if param.Kind() == reflect.Ptr {
 			// Prüfen, ob der zugrunde liegende Typ ein zulässiger MessagePack-Typ ist
@@ -240,10 +290,13 @@ func validateRPCFunction(fnValue reflect.Value, fnType reflect.Type, isRegisterS
 	}
 
 	// Es werden alle Rückgabewerte Abgearbeitet, bis auf den letzten
-	for i := 0; i < fnType.NumOut()-2; i++ {
+	for i := 0; i < fnType.NumOut()-1; i++ {
 		outType := fnType.Out(i)
+		if isChannelType(outType) || isProtoMessageType(outType) || isCustomType(outType) {
+			continue
+		}
 		if outType.Kind() == reflect.Ptr {
-			outType := fnType.Out(0).Elem()
+			outType := outType.Elem()
 			if outType.Kind() != reflect.Struct {
 				return fmt.Errorf("only structs as pointer allowed")
 			}
@@ -266,6 +319,12 @@ func validateRPCFunction(fnValue reflect.Value, fnType reflect.Type, isRegisterS
 
 // Überprüft ob die Datentypen Zulässig sind um in einer RPC Funktion verwendet werden zu können
 func validateDatatypeForRpc(param reflect.Type) error {
+	// Funktionen werden als Callback übergeben und bei der Registrierung geprüft, Channel über ihre Sitzung
+	// sowie Protocol Buffers Nachrichten und eigene Typen mit ihrer eigenen Kodierung
+	if param.Kind() == reflect.Func || isChannelType(param) || isProtoMessageType(param) || isCustomType(param) {
+		return nil
+	}
+
 	// Wenn der Parameter ein Pointer ist
 	if param.Kind() == reflect.Ptr {
 		// Prüfen, ob der zugrunde liegende Typ ein zulässiger MessagePack-Typ ist
@@ -301,78 +360,162 @@ func validateRpcParamsDatatypes(params ...interface{}) error {
 	return nil
 }
 
-// Konvertiert die Parameter eines Funktionsaufrufes
-func processRpcGoDataTypeTransportable(params ...interface{}) ([]*transport.RpcDataCapsle, error) {
+// Konvertiert die Parameter eines Funktionsaufrufes, mit einem anderen Codec als msgpack werden die Werte über den Codec kodiert
+func processRpcGoDataTypeTransportable(codec Codec, params ...interface{}) ([]*transport.RpcDataCapsle, error) {
 	newItems := make([]*transport.RpcDataCapsle, 0)
 	for i, item := range params {
-		// Refelction wird auf 'fn' angewendet
+		// Callbacks werden über die ID der versteckten Funktion übertragen
+		if hidden, ok := item.(*transport.RpcHiddenFunction); ok {
+			newItems = append(newItems, &transport.RpcDataCapsle{Type: "func", Value: hidden})
+			continue
+		}
+
+		// Channel werden über die ID ihrer Sitzung übertragen
+		if channel, ok := item.(*BngConnChannel); ok {
+			newItems = append(newItems, &transport.RpcDataCapsle{Type: "channel", Value: channel.sesisonId})
+			continue
+		}
+
+		// Benannte Parameter werden gemeinsam übertragen
+		if named, ok := item.(NamedArgs); ok {
+			capsle, err := encodeNamedArgs(codec, named)
+			if err != nil {
+				return nil, fmt.Errorf("convertRPCCallParameters: invalid named arguments on %d, %s", i, err.Error())
+			}
+			newItems = append(newItems, capsle)
+			continue
+		}
+
+		// Refelction wird auf den Wert angewendet
 		fnValue := reflect.ValueOf(item)
-		fnType := fnValue.Type()
+		if !fnValue.IsValid() {
+			return nil, fmt.Errorf("convertRPCCallParameters: invalid data type on %d, nil", i)
+		}
 
-		// Der Datentyp wird extrahiert
-		switch fnType.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			newItems = append(newItems, &transport.RpcDataCapsle{Type: "int", Value: item})
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			newItems = append(newItems, &transport.RpcDataCapsle{Type: "uint", Value: item})
-		case reflect.Float32, reflect.Float64:
-			newItems = append(newItems, &transport.RpcDataCapsle{Type: "float", Value: item})
-		case reflect.Bool:
-			newItems = append(newItems, &transport.RpcDataCapsle{Type: "bool", Value: item})
-		case reflect.String:
-			newItems = append(newItems, &transport.RpcDataCapsle{Type: "string", Value: item})
-		case reflect.Slice:
-			newItems = append(newItems, &transport.RpcDataCapsle{Type: "slice", Value: item})
-		case reflect.Map:
-			newItems = append(newItems, &transport.RpcDataCapsle{Type: "map", Value: item})
-		case reflect.Ptr:
-			if fnValue.IsNil() {
-				newItems = append(newItems, &transport.RpcDataCapsle{Type: fmt.Sprintf("null-struct:%s", fnType.Elem()), Value: nil})
-			} else {
-				if fnType.Elem().Kind() != reflect.Struct {
-					return nil, fmt.Errorf("convertRPCCallParameters: invalid data type on %d, type %s, x", i, fnType.Elem().Name())
-				}
-				cborconverted, err := cbor.Marshal(fnValue.Interface())
-				if err != nil {
-					return nil, fmt.Errorf("convertRPCCallParameters: invalid data type on %d, type %s, b", i, fnType.Elem().Name())
-				}
-				newItems = append(newItems, &transport.RpcDataCapsle{Type: fmt.Sprintf("struct:%s", fnType.Elem()), Value: cborconverted})
+		// Eigene Typen werden mit ihrer registrierten Kodierung übertragen
+		if customType, found := lookupCustomType(fnValue.Type()); found {
+			capsle, err := encodeCustomValue(customType, fnValue)
+			if err != nil {
+				return nil, fmt.Errorf("convertRPCCallParameters: invalid value on %d, %s", i, err.Error())
 			}
-		default:
-			return nil, fmt.Errorf("convertRPCCallParameters: invalid data type on %d, type %s, a", i, fnType.Kind())
+			newItems = append(newItems, capsle)
+			continue
+		}
+
+		// Der Transportdatentyp wird ermittelt
+		dataType, err := rpcTransportDatatype(fnValue.Type())
+		if err != nil {
+			return nil, fmt.Errorf("convertRPCCallParameters: invalid data type on %d, %s", i, err.Error())
+		}
+
+		// Protocol Buffers Nachrichten werden immer mit dem Protobuf Codec kodiert
+		if isProtoMessageType(fnValue.Type()) {
+			codec, _ = lookupCodec(CodecProtobuf)
+		} else if fnValue.Kind() == reflect.Ptr && fnValue.IsNil() {
+			// Für einen leeren Zeiger wird nur der Typ übertragen
+			newItems = append(newItems, &transport.RpcDataCapsle{Type: fmt.Sprintf("null-struct:%s", fnValue.Type().Elem()), Value: nil, Kind: fnValue.Type().String()})
+			continue
+		}
+
+		// Mit msgpack werden die Werte direkt übertragen, Structs als CBOR Daten
+		if codec.Name() == CodecMsgpack {
+			if fnValue.Kind() != reflect.Ptr {
+				newItems = append(newItems, &transport.RpcDataCapsle{Type: dataType, Value: item, Kind: fnValue.Type().String()})
+				continue
+			}
+			cborconverted, err := cbor.Marshal(item)
+			if err != nil {
+				return nil, fmt.Errorf("convertRPCCallParameters: invalid data type on %d, type %s, b", i, fnValue.Type().Elem().Name())
+			}
+			newItems = append(newItems, &transport.RpcDataCapsle{Type: dataType, Value: cborconverted, Kind: fnValue.Type().String()})
+			continue
+		}
+
+		// Der Wert wird mit dem Codec kodiert
+		encoded, err := codec.Marshal(item)
+		if err != nil {
+			return nil, fmt.Errorf("convertRPCCallParameters: encoding %d with %s failed: %w", i, codec.Name(), err)
 		}
+		newItems = append(newItems, &transport.RpcDataCapsle{Type: dataType, Codec: codec.Name(), Encoded: encoded, Kind: fnValue.Type().String()})
 	}
 	return newItems, nil
 }
 
-// Wandelt Daten mittels Angabe eines Refelect Types um
-func processGoValueToRelectType(value any, expectedType reflect.Type) (reflect.Value, error) {
-	val := reflect.ValueOf(value)
-	switch expectedType.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		// Es wird geprüft ob es sich um einen Integerwert handelt
-		if expectedType.Kind() != reflect.Int && expectedType.Kind() != reflect.Int8 && expectedType.Kind() != reflect.Int16 && expectedType.Kind() != reflect.Int32 && expectedType.Kind() != reflect.Int64 {
-			return reflect.Value{}, fmt.Errorf("invalid integer transmitted 1")
-		}
+// rpcTransportDatatype gibt den Transportdatentyp eines Go Datentyps zurück.
+func rpcTransportDatatype(item reflect.Type) (string, error) {
+	// Channel werden über die ID ihrer Sitzung übertragen
+	if isChannelType(item) {
+		return "channel", nil
+	}
+
+	// Protocol Buffers Nachrichten werden über ihren vollständigen Namen identifiziert
+	if isProtoMessageType(item) {
+		return "proto:" + protoMessageName(item), nil
+	}
 
-		// Es wird ein Leeres Int zurückgegeben
-		return val.Convert(expectedType), nil
+	// Eigene Typen werden über ihren Namen identifiziert
+	if customType, found := lookupCustomType(item); found {
+		return customTypePrefix + customType.name, nil
+	}
+
+	// Der Datentyp wird extrahiert
+	switch item.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int", nil
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		// Es wird geprüft ob es sich um einen Integerwert handelt
-		if expectedType.Kind() != reflect.Uint && expectedType.Kind() != reflect.Uint8 && expectedType.Kind() != reflect.Uint16 && expectedType.Kind() != reflect.Uint32 && expectedType.Kind() != reflect.Uint64 {
-			return reflect.Value{}, fmt.Errorf("invalid integer transmitted 2")
+		return "uint", nil
+	case reflect.Float32, reflect.Float64:
+		return "float", nil
+	case reflect.Bool:
+		return "bool", nil
+	case reflect.String:
+		return "string", nil
+	case reflect.Slice:
+		return "slice", nil
+	case reflect.Map:
+		return "map", nil
+	case reflect.Ptr:
+		if item.Elem().Kind() != reflect.Struct {
+			return "", fmt.Errorf("type %s, x", item.Elem().Name())
 		}
+		return fmt.Sprintf("struct:%s", item.Elem()), nil
+	default:
+		return "", fmt.Errorf("type %s, a", item.Kind())
+	}
+}
 
-		// Es wird ein Leeres Int zurückgegeben
-		return val.Convert(expectedType), nil
-	case reflect.Float32, reflect.Float64:
-		// Wandelt den Datentypen um
-		if expectedType.Kind() != reflect.Float32 && expectedType.Kind() != reflect.Float64 {
-			return reflect.Value{}, fmt.Errorf("invalid float transmitted")
+// decodeRpcDataCapsleWithCodec liest einen mit einem Codec kodierten Wert in den erwarteten Typ ein.
+func decodeRpcDataCapsleWithCodec(value *transport.RpcDataCapsle, expectedType reflect.Type) (reflect.Value, error) {
+	// Der Codec muss bekannt sein
+	codec, found := lookupCodec(value.Codec)
+	if !found {
+		return reflect.Value{}, fmt.Errorf("%w: %s", ErrUnsupportedCodec, value.Codec)
+	}
+
+	// Protocol Buffers Nachrichten müssen dem erwarteten Typ entsprechen
+	if strings.HasPrefix(value.Type, "proto:") {
+		if !isProtoMessageType(expectedType) || value.Type != "proto:"+protoMessageName(expectedType) {
+			return reflect.Value{}, fmt.Errorf("%s transmitted, expected %s", value.Type, expectedType)
 		}
+	}
 
-		// Es wird ein Leeres Int zurückgegeben
-		return val.Convert(expectedType), nil
+	// Der Wert wird dekodiert
+	target := reflect.New(expectedType)
+	if err := codec.Unmarshal(value.Encoded, target.Interface()); err != nil {
+		return reflect.Value{}, fmt.Errorf("decoding %s failed: %w", codec.Name(), err)
+	}
+	return target.Elem(), nil
+}
+
+// Wandelt Daten mittels Angabe eines Refelect Types um
+func processGoValueToRelectType(value any, expectedType reflect.Type) (reflect.Value, error) {
+	val := reflect.ValueOf(value)
+	switch expectedType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		// Die Zahl wird mit Bereichsprüfung umgewandelt
+		return convertRpcNumber(val, expectedType)
 	case reflect.Bool:
 		return reflect.ValueOf(value), nil
 	case reflect.String:
@@ -502,6 +645,16 @@ func processGoValueToRelectType(value any, expectedType reflect.Type) (reflect.V
 
 // Wandelt RpcDataCapsle zurück in Go Datensätze
 func processRpcDataCapsleToGoValue(value *transport.RpcDataCapsle, expectedType reflect.Type) (reflect.Value, error) {
+	// Eigene Typen werden mit ihrer registrierten Kodierung eingelesen
+	if strings.HasPrefix(value.Type, customTypePrefix) {
+		return decodeCustomValue(value, expectedType)
+	}
+
+	// Mit einem Codec kodierte Werte werden direkt in den erwarteten Typ eingelesen
+	if value.Codec != "" {
+		return decodeRpcDataCapsleWithCodec(value, expectedType)
+	}
+
 	switch expectedType.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return processGoValueToRelectType(value.Value, expectedType)
@@ -526,84 +679,118 @@ func processRpcDataCapsleToGoValue(value *transport.RpcDataCapsle, expectedType
 	}
 }
 
-// Konvertiert übertragene Parameter wirder zurück in Go Werte um
+// Konvertiert übertragene Parameter wirder zurück in Go Werte um, fehlende Optional Parameter am Ende werden ergänzt
+// und die Elemente eines variadischen Parameters werden zu einem Slice zusammengefasst
 func convertRPCCallParameterBackToGoValues(fn reflect.Value, ctx *BngRequest, params ...*transport.RpcDataCapsle) ([]reflect.Value, error) {
-	// Parametertypen prüfen und aufbereiten
-	in := make([]reflect.Value, len(params)+1)
-	in[0] = reflect.ValueOf(ctx)
-
-	// ES wird geprüft ob genau die Anzahl der Parameter vorhanden ist
-	if fn.Type().NumIn() != len(params)+1 {
-		return nil, fmt.Errorf("transmitted function call hast not same parameters, wanted %d, have %d", fn.Type().NumIn(), len(params)+1)
-	}
-
 	// Übergebe die weiteren Parameter an die Funktion
+	values := make([]reflect.Value, len(params))
 	for i, param := range params {
 		// Es wird ermnittelt um was für einen Typen es sich handelt
-		expectedType := fn.Type().In(i + 1) // Den erwarteten Typ der Funktion an der Stelle ermittelnconvertRPCCallParameters
-
-		// Es wird geprüft ob es sich um einen Zulässigen Datentyp handelt
-		if !supportedTypes[strings.Split(param.Type, ":")[0]] {
-			return nil, fmt.Errorf("unsuported datatype %s on %d", param.Type, i)
+		expectedType, err := expectedRpcParameterType(fn.Type(), 1, i)
+		if err != nil {
+			return nil, err
 		}
 
-		// Der Wert wird eingelesen
-		cvalue, err := processRpcDataCapsleToGoValue(param, expectedType)
+		// Der Wert wird eingelesen, ungültige Werte werden dem Aufrufer als ErrInvalidParameter gemeldet
+		cvalue, err := convertIncomingRpcParameter(ctx, i, param, expectedType)
 		if err != nil {
-			return nil, fmt.Errorf("convertRPCCallParameterBackToGoValues: " + err.Error())
+			return nil, err
 		}
 
 		// Wid zwischengespeichert
-		in[i+1] = cvalue
+		values[i] = cvalue
+	}
+
+	// Fehlende Optional Parameter werden ergänzt
+	args, err := completeRpcArguments(fn.Type(), 1, values)
+	if err != nil {
+		return nil, err
 	}
 
+	// Der BngRequest wird als erster Parameter übergeben
+	in := append([]reflect.Value{reflect.ValueOf(ctx)}, args...)
+
 	// Die Rückgabewerte werden zurückgegeben
 	return in, nil
 }
 
+// convertIncomingRpcParameter wandelt einen übertragenen Parameter eines eingehenden Aufrufs in den erwarteten
+// Go Datentyp um. Channel werden über ihre Sitzung übernommen, Callbacks werden als Proxy übergeben.
+//
+// Parameter:
+//   - ctx *BngRequest: Die Anfrage, zu welcher der Parameter gehört.
+//   - index int: Die Position des Parameters.
+//   - param *transport.RpcDataCapsle: Der übertragene Parameter.
+//   - expectedType reflect.Type: Der erwartete Typ des Parameters.
+//
+// Rückgabe:
+//   - reflect.Value: Der eingelesene Wert.
+//   - error: ErrInvalidParameter, falls der Parameter nicht eingelesen werden konnte, ansonsten nil.
+func convertIncomingRpcParameter(ctx *BngRequest, index int, param *transport.RpcDataCapsle, expectedType reflect.Type) (reflect.Value, error) {
+	// Es wird geprüft ob es sich um einen Zulässigen Datentyp handelt
+	if !supportedTypes[strings.Split(param.Type, ":")[0]] {
+		return reflect.Value{}, fmt.Errorf("%w: parameter %d: unsuported datatype %s", ErrInvalidParameter, index, param.Type)
+	}
+
+	// Channel werden über ihre Sitzung übernommen
+	if param.Type == "channel" {
+		channel, err := resolveChannelValue(ctx.Conn, param, expectedType)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%w: parameter %d: %w", ErrInvalidParameter, index, err)
+		}
+		return reflect.ValueOf(channel), nil
+	}
+
+	// Callbacks werden als Proxy an die Funktion übergeben
+	if param.Type == "func" {
+		cvalue, err := newCallbackParameter(ctx, param, expectedType)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%w: parameter %d: %w", ErrInvalidParameter, index, err)
+		}
+		return cvalue, nil
+	}
+
+	return convertRpcParameter(ctx.Conn, index, param, expectedType)
+}
+
 // Wird verwendet um die Rückgabe Daten eines Aufrufes wieder in Go Datentypen zu Konvertieren
 func processRPCCallResponseDataToGoDatatype(rdc *transport.RpcDataCapsle, retunDataType reflect.Type) (interface{}, error) {
+	// Eigene Typen werden mit ihrer registrierten Kodierung eingelesen
+	if strings.HasPrefix(rdc.Type, customTypePrefix) {
+		value, err := decodeCustomValue(rdc, retunDataType)
+		if err != nil {
+			return nil, err
+		}
+		return value.Interface(), nil
+	}
+
+	// Für einen leeren Zeiger wird der Nullwert des erwarteten Typs zurückgegeben
+	if strings.HasPrefix(rdc.Type, "null-struct:") {
+		return reflect.Zero(retunDataType).Interface(), nil
+	}
+
+	// Mit einem Codec kodierte Werte werden direkt in den erwarteten Typ eingelesen
+	if rdc.Codec != "" {
+		value, err := decodeRpcDataCapsleWithCodec(rdc, retunDataType)
+		if err != nil {
+			return nil, err
+		}
+		return value.Interface(), nil
+	}
+
 	// Es wird ermnittelt um was für einen Typen es sich handelt
 	val := reflect.ValueOf(rdc.Value)
 	valType := val.Type()
 
 	// Konvertiere den Wert in den exakt erwarteten Typ
 	switch {
-	case rdc.Type == "int":
-		// Es wird geprüft ob es sich um einen Integerwert handelt
-		if valType.Kind() != reflect.Int && valType.Kind() != reflect.Int8 && valType.Kind() != reflect.Int16 && valType.Kind() != reflect.Int32 && valType.Kind() != reflect.Int64 {
-			return nil, fmt.Errorf("invalid integer transmitted 3")
-		}
-
-		// Konvertiere in den exakten Integer-Typ, der erwartet wird
-		if val.Kind() >= reflect.Int && val.Kind() <= reflect.Int64 {
-			// Konvertiere Ganzzahlen entsprechend dem erwarteten Typ
-			return val.Interface(), nil
-		}
-	case rdc.Type == "uint":
-		// Es wird geprüft ob es sich um einen Integerwert handelt
-		if valType.Kind() != reflect.Uint && valType.Kind() != reflect.Uint8 && valType.Kind() != reflect.Uint16 && valType.Kind() != reflect.Uint32 && valType.Kind() != reflect.Uint64 {
-			// Wenn der Übertragene Datentyp kein Uint ist, wird geprüft ob es sich um ein Integer handelt
-			if valType.Kind() != reflect.Int && valType.Kind() != reflect.Int8 && valType.Kind() != reflect.Int16 && valType.Kind() != reflect.Int32 && valType.Kind() != reflect.Int64 {
-				return nil, fmt.Errorf("invalid integer transmitted 4")
-			}
-		}
-
-		// Konvertiere in den exakten Integer-Typ, der erwartet wird
-		if val.Kind() >= reflect.Uint && val.Kind() <= reflect.Uint64 {
-			// Konvertiere Ganzzahlen entsprechend dem erwarteten Typ
-			return val.Interface(), nil
-		}
-	case rdc.Type == "float":
-		// Wandelt den Datentypen um
-		if valType.Kind() != reflect.Float32 && valType.Kind() != reflect.Float64 {
-			return nil, fmt.Errorf("invalid float transmitted")
-		}
-
-		// Konvertiere in den exakten Float-Typ, der erwartet wird
-		if val.Kind() == reflect.Float32 || val.Kind() == reflect.Float64 {
-			return val.Interface(), nil
+	case rdc.Type == "int" || rdc.Type == "uint" || rdc.Type == "float":
+		// Die Zahl wird mit Bereichsprüfung in den erwarteten Typ umgewandelt
+		converted, err := convertRpcNumber(val, retunDataType)
+		if err != nil {
+			return nil, err
 		}
+		return converted.Interface(), nil
 	case strings.Split(rdc.Type, ":")[0] == "struct":
 		// Erstelle einen neuen Zeiger auf das erwartete Struct
 		structPtr := reflect.New(retunDataType)
@@ -627,38 +814,17 @@ func processRPCCallResponseDataToGoDatatype(rdc *transport.RpcDataCapsle, retunD
 	default:
 		return nil, fmt.Errorf("invalid data type on %s", valType.Kind())
 	}
-
-	// Due Werte werden zurückgegeben
-	return rdc.Value, nil
 }
 
 // Konvertiert einen Go Datentyp in einen Transport Datatype
 func processRpcGoDataTypeTransportableDatatype(params []reflect.Type) ([]string, error) {
 	newItems := make([]string, 0)
 	for i, item := range params {
-		// Refelction wird auf 'fn' angewendet
-
-		// Der Datentyp wird extrahiert
-		switch item.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			newItems = append(newItems, "int")
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			newItems = append(newItems, "uint")
-		case reflect.Float32, reflect.Float64:
-			newItems = append(newItems, "float")
-		case reflect.Bool:
-			newItems = append(newItems, "bool")
-		case reflect.String:
-			newItems = append(newItems, "string")
-		case reflect.Slice:
-			newItems = append(newItems, "slice")
-		case reflect.Map:
-			newItems = append(newItems, "map")
-		case reflect.Ptr:
-			newItems = append(newItems, fmt.Sprintf("struct:%s", item.Elem()))
-		default:
-			return nil, fmt.Errorf("convertRPCCallParameters: invalid data type on %d, type %s, a", i, item.Kind())
+		dataType, err := rpcTransportDatatype(item)
+		if err != nil {
+			return nil, fmt.Errorf("convertRPCCallParameters: invalid data type on %d, %s", i, err.Error())
 		}
+		newItems = append(newItems, dataType)
 	}
 	return newItems, nil
 }